@@ -0,0 +1,181 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/yunikorn-release/perf-tools/framework"
+	"github.com/apache/yunikorn-release/perf-tools/scenarios"
+	"github.com/apache/yunikorn-release/perf-tools/utils"
+)
+
+// listScenarios prints names of registered scenarios in order with the schema of their configs
+func listScenarios(args []string) int {
+	flagSet := newFlagSet(CommandListScenarios)
+	scenarioNames := addScenariosFlag(flagSet)
+	if exitCode, ok := parseFlags(flagSet, args, 0); !ok {
+		return exitCode
+	}
+	testScenarios, err := getExpectedTestScenarios(*scenarioNames, true)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitCodeUsage
+	}
+	sort.Slice(testScenarios, func(i, j int) bool {
+		return testScenarios[i].GetName() < testScenarios[j].GetName()
+	})
+	for _, testScenario := range testScenarios {
+		if framework.IsDisruptive(testScenario) {
+			fmt.Printf("%s: (disruptive, only runs when specified by -scenarios)\n", testScenario.GetName())
+		} else {
+			fmt.Printf("%s:\n", testScenario.GetName())
+		}
+		for _, line := range framework.DescribeScenarioConfig(testScenario.NewScenarioConfig()) {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+	return 0
+}
+
+// validate validates the effective config and prints all invalid entries with their line numbers
+func validate(args []string) int {
+	flagSet := newFlagSet(CommandValidate)
+	getConfigLayers := addConfigFlags(flagSet)
+	scenarioNames := addScenariosFlag(flagSet)
+	printConfig := flagSet.Bool("print", false, "print the effective config merged from all config layers")
+	if exitCode, ok := parseFlags(flagSet, args, 0); !ok {
+		return exitCode
+	}
+	testScenarios, err := getExpectedTestScenarios(*scenarioNames, false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitCodeUsage
+	}
+	configLayers := getConfigLayers()
+	configContent, valid := loadConfig(configLayers, testScenarios)
+	if *printConfig && configContent != nil {
+		fmt.Print(string(configContent))
+	}
+	if !valid {
+		return exitCodeFailed
+	}
+	fmt.Fprintf(os.Stderr, "config of %s is valid\n", configLayers.BaseFile)
+	return 0
+}
+
+// report regenerates the HTML report from the results saved in the output directory of a run
+func report(args []string) int {
+	flagSet := newFlagSet(CommandReport)
+	logLevel := addLogLevelFlag(flagSet)
+	if exitCode, ok := parseFlags(flagSet, args, 1); !ok {
+		return exitCode
+	}
+	utils.SetLogLevel(*logLevel)
+	results, runMetadata, err := utils.LoadResults(flagSet.Arg(0))
+	if err != nil {
+		utils.Logger.Error("failed to load results", zap.String("outputPath", flagSet.Arg(0)), zap.Error(err))
+		return exitCodeFailed
+	}
+	results.RefreshStatus()
+	fmt.Println(results.String())
+	if err = utils.NewHTMLReporter(results, runMetadata).GenerateReport(); err != nil {
+		utils.Logger.Error("failed to generate HTML report", zap.Error(err))
+		return exitCodeFailed
+	}
+	return 0
+}
+
+// compare prints a table comparing metrics saved in the output directories of two runs
+func compare(args []string) int {
+	flagSet := newFlagSet(CommandCompare)
+	logLevel := addLogLevelFlag(flagSet)
+	tableFormats := flagSet.String("tableFormats", "",
+		"comma separated formats of the table also written into the target output directory: txt, csv, tsv, md")
+	if exitCode, ok := parseFlags(flagSet, args, 2); !ok {
+		return exitCode
+	}
+	utils.SetLogLevel(*logLevel)
+	var formats []utils.TableFormat
+	if *tableFormats != "" {
+		var err error
+		if formats, err = utils.ParseTableFormats(strings.Split(*tableFormats, ",")); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitCodeUsage
+		}
+	}
+	allResults := make([]*utils.Results, 0, 2)
+	for _, outputPath := range flagSet.Args() {
+		results, _, loadErr := utils.LoadResults(outputPath)
+		if loadErr != nil {
+			utils.Logger.Error("failed to load results", zap.String("outputPath", outputPath), zap.Error(loadErr))
+			return exitCodeFailed
+		}
+		allResults = append(allResults, results)
+	}
+	table := utils.CompareResults(allResults[0], allResults[1], scenarios.GetComparedMetrics())
+	fmt.Printf("compare %s (baseline) with %s (target):\n", flagSet.Arg(0), flagSet.Arg(1))
+	table.Print()
+	if len(formats) > 0 {
+		if _, err := table.OutputFormats(flagSet.Arg(1)+"/compare", formats); err != nil {
+			utils.Logger.Error("failed to output comparison", zap.Error(err))
+			return exitCodeFailed
+		}
+	}
+	return 0
+}
+
+// cleanup deletes deployments and pods left behind by previous runs in the cluster of the config file
+func cleanup(args []string) int {
+	flagSet := newFlagSet(CommandCleanup)
+	getConfigLayers := addConfigFlags(flagSet)
+	logLevel := addLogLevelFlag(flagSet)
+	if exitCode, ok := parseFlags(flagSet, args, 0); !ok {
+		return exitCode
+	}
+	utils.SetLogLevel(*logLevel)
+	configContent, err := getConfigLayers().Merge()
+	if err != nil {
+		utils.Logger.Error("failed to merge config layers", zap.Error(err))
+		return exitCodeFailed
+	}
+	conf, err := framework.ParseConfig(configContent)
+	if err != nil {
+		utils.Logger.Error("failed to initialize config", zap.Error(err))
+		return exitCodeFailed
+	}
+	kubeClient, err := utils.NewKubeClient(conf.Common.KubeConfigFile)
+	if err != nil {
+		utils.Logger.Error("failed to initialize kube-client", zap.Error(err))
+		return exitCodeFailed
+	}
+	result, err := framework.Cleanup(kubeClient, time.Duration(conf.Common.MaxWaitSeconds)*time.Second)
+	if err != nil {
+		utils.Logger.Error("failed to clean up", zap.Error(err))
+		return exitCodeFailed
+	}
+	fmt.Printf("deleted deployments: %v, pods: %v\n", result.NumDeployments, result.NumPods)
+	return 0
+}
@@ -27,9 +27,17 @@ const (
 	DefaultContainerImage = "nginx:1.12"
 	LabelAppID            = "applicationId"
 	LabelQueue            = "queue"
+	LabelRequestGroup     = "requestGroup"
+	LabelRequestIndex     = "requestIndex"
+	// all objects created by this tool are labeled so that leftovers can be cleaned up
+	LabelManagedBy     = "app.kubernetes.io/managed-by"
+	ManagedByPerfTools = "yunikorn-perf-tools"
+
+	// names of schedulers which are known to be available
+	SchedulerNameYuniKorn = "yunikorn"
+	SchedulerNameDefault  = "default-scheduler"
 
 	// constants for chart
-	ChartWidth      = 6 * vg.Inch
-	ChartHeight     = 6 * vg.Inch
-	ChartFileSuffix = ".svg"
+	ChartWidth  = 6 * vg.Inch
+	ChartHeight = 6 * vg.Inch
 )
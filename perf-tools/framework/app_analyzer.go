@@ -36,6 +36,37 @@ func NewAppAnalyzer(appInfo *AppInfo) *AppAnalyzer {
 	return &AppAnalyzer{appInfo: appInfo}
 }
 
+// GetGroups returns groups of tasks in the order of requests, tasks which can't be traced back to
+// their requests are not included in any group
+func (aa *AppAnalyzer) GetGroups() []string {
+	return aa.appInfo.GetRequestGroups()
+}
+
+// GetGroupAnalyzer returns an analyzer for tasks of the specified group only, the app status is shared
+// so that distributions of different groups are aligned on the same time axis
+func (aa *AppAnalyzer) GetGroupAnalyzer(group string) *AppAnalyzer {
+	groupAppInfo := *aa.appInfo
+	groupAppInfo.TasksStatus = make(map[string]*TaskStatus)
+	for taskID, taskStatus := range aa.appInfo.TasksStatus {
+		if taskStatus.Group == group {
+			groupAppInfo.TasksStatus[taskID] = taskStatus
+		}
+	}
+	return NewAppAnalyzer(&groupAppInfo)
+}
+
+func (aa *AppAnalyzer) GetGroupLastTasks(group string, lastN int) []*TaskStatus {
+	return aa.GetGroupAnalyzer(group).GetLastTasks(lastN)
+}
+
+func (aa *AppAnalyzer) GetGroupTimeDistribution(group string, condType TaskConditionType) []int {
+	return aa.GetGroupAnalyzer(group).GetTimeDistribution(condType)
+}
+
+func (aa *AppAnalyzer) GetGroupTasksProfiling(group string) profiling.Profiling {
+	return aa.GetGroupAnalyzer(group).GetTasksProfiling()
+}
+
 func (aa *AppAnalyzer) GetLastTasks(lastN int) []*TaskStatus {
 	taskStatusSlice := make([]*TaskStatus, len(aa.appInfo.TasksStatus))
 	i := 0
@@ -64,6 +95,10 @@ func (aa *AppAnalyzer) GetTasksDistributionInfo(nodeInfos map[string]*NodeInfo)
 	})
 	leastNodeInfo := nodeInfoSlice[0]
 	mostNodeInfo := nodeInfoSlice[len(nodeInfoSlice)-1]
+	numTasks := make([]float64, len(nodeInfoSlice))
+	for i, nodeInfo := range nodeInfoSlice {
+		numTasks[i] = float64(len(nodeInfo.Tasks))
+	}
 	return &TasksDistributionInfo{
 		LeastNum:        len(leastNodeInfo.Tasks),
 		LeastNumNodeID:  leastNodeInfo.NodeID,
@@ -71,6 +106,7 @@ func (aa *AppAnalyzer) GetTasksDistributionInfo(nodeInfos map[string]*NodeInfo)
 		MostNumNodeID:   mostNodeInfo.NodeID,
 		AvgNum:          float64(len(aa.appInfo.TasksStatus)) / float64(len(nodeInfoSlice)),
 		SortedNodeInfos: nodeInfoSlice,
+		Fairness:        utils.CalculateFairnessIndices(numTasks),
 	}
 }
 
@@ -128,3 +164,35 @@ func (aa *AppAnalyzer) GetTasksProfiling() profiling.Profiling {
 	}
 	return prof
 }
+
+// GetStageLatencies returns latencies (in seconds) of all tasks for every stage between two adjacent
+// ordered conditions, keyed by stage name, plus the end-to-end latency from creation to running.
+func (aa *AppAnalyzer) GetStageLatencies() map[string][]float64 {
+	orderedCondTypes := GetOrderedTaskConditionTypes()
+	stageLatencies := make(map[string][]float64)
+	for _, ts := range aa.appInfo.TasksStatus {
+		for i := 1; i < len(orderedCondTypes); i++ {
+			fromTime := ts.GetTransitionTime(orderedCondTypes[i-1])
+			toTime := ts.GetTransitionTime(orderedCondTypes[i])
+			if fromTime == nil || toTime == nil {
+				continue
+			}
+			stageName := GetStageName(orderedCondTypes[i-1], orderedCondTypes[i])
+			stageLatencies[stageName] = append(stageLatencies[stageName], toTime.Sub(*fromTime).Seconds())
+		}
+		stageLatencies[EndToEndStageName] = append(stageLatencies[EndToEndStageName],
+			ts.RunningTime.Sub(ts.CreateTime).Seconds())
+	}
+	return stageLatencies
+}
+
+// GetSchedulingLatencies returns latencies (in seconds) from creation to scheduled of all tasks
+func (aa *AppAnalyzer) GetSchedulingLatencies() []float64 {
+	latencies := make([]float64, 0, len(aa.appInfo.TasksStatus))
+	for _, ts := range aa.appInfo.TasksStatus {
+		if scheduledTime := ts.GetTransitionTime(PodScheduled); scheduledTime != nil {
+			latencies = append(latencies, scheduledTime.Sub(ts.CreateTime).Seconds())
+		}
+	}
+	return latencies
+}
@@ -0,0 +1,70 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func newGroupedTaskStatus(taskID string, reqIndex int, appInfo *AppInfo, createTime,
+	scheduledTime time.Time) *TaskStatus {
+	taskStatus := NewTaskStatus(taskID, "node-1", createTime, scheduledTime, nil,
+		[]*TaskCondition{{CondType: PodScheduled, TransitionTime: scheduledTime}})
+	taskStatus.RequestIndex = reqIndex
+	taskStatus.Group = appInfo.GetRequestGroup(reqIndex)
+	return taskStatus
+}
+
+func TestGetGroupAnalyzer(t *testing.T) {
+	highPriorityInfo := NewRequestInfo(1, "high", nil, nil)
+	highPriorityInfo.Group = "high"
+	appInfo := NewAppInfo("ns", "app-1", "root.test", []*RequestInfo{
+		highPriorityInfo,
+		NewRequestInfo(2, "", nil, nil),
+		highPriorityInfo,
+	}, apiv1.PodTemplateSpec{}, apiv1.PodSpec{})
+	assert.DeepEqual(t, appInfo.GetRequestGroups(), []string{"high", "request-1"})
+	assert.Equal(t, appInfo.GetRequestGroup(3), "")
+
+	beginTime := time.Now()
+	appInfo.AppStatus.CreateTime = beginTime
+	appInfo.AppStatus.RunningTime = beginTime.Add(3 * time.Second)
+	appInfo.TasksStatus = map[string]*TaskStatus{
+		"high-0": newGroupedTaskStatus("high-0", 0, appInfo, beginTime, beginTime.Add(500*time.Millisecond)),
+		"high-1": newGroupedTaskStatus("high-1", 2, appInfo, beginTime, beginTime.Add(600*time.Millisecond)),
+		"low-0":  newGroupedTaskStatus("low-0", 1, appInfo, beginTime, beginTime.Add(2500*time.Millisecond)),
+		"low-1":  newGroupedTaskStatus("low-1", 1, appInfo, beginTime, beginTime.Add(1500*time.Millisecond)),
+	}
+	appAnalyzer := NewAppAnalyzer(appInfo)
+	assert.DeepEqual(t, appAnalyzer.GetGroups(), []string{"high", "request-1"})
+	assert.DeepEqual(t, appAnalyzer.GetTimeDistribution(PodScheduled), []int{0, 2, 1, 1})
+	// distributions of groups share the time axis of the app
+	assert.DeepEqual(t, appAnalyzer.GetGroupTimeDistribution("high", PodScheduled), []int{0, 2})
+	assert.DeepEqual(t, appAnalyzer.GetGroupTimeDistribution("request-1", PodScheduled), []int{0, 0, 1, 1})
+	lastTasks := appAnalyzer.GetGroupLastTasks("request-1", 1)
+	assert.Equal(t, len(lastTasks), 1)
+	assert.Equal(t, lastTasks[0].TaskID, "low-0")
+	assert.Equal(t, len(appAnalyzer.GetGroupAnalyzer("unknown").GetSchedulingLatencies()), 0)
+	// the app is not modified
+	assert.Equal(t, len(appInfo.TasksStatus), 4)
+}
@@ -19,11 +19,14 @@
 package framework
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/apache/yunikorn-core/pkg/common/resources"
 
 	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/apache/yunikorn-release/perf-tools/utils"
 )
 
 type AppInfo struct {
@@ -38,10 +41,24 @@ type AppInfo struct {
 }
 
 type RequestInfo struct {
+	// name of the request group, pods of the same group share the same label for analysis
+	Group            string
 	Number           int32
 	PriorityClass    string
 	RequestResources map[string]string
 	LimitResources   map[string]string
+	// pod-level scheduling constraints, override those in the pod spec of the app if specified
+	Affinity                  *apiv1.Affinity
+	TopologySpreadConstraints []apiv1.TopologySpreadConstraint
+	RuntimeClassName          string
+	// pod-level settings of the request group, override those of the app if specified
+	Image        string
+	Command      []string
+	Labels       map[string]string
+	Annotations  map[string]string
+	Queue        string
+	NodeSelector map[string]string
+	Tolerations  []apiv1.Toleration
 }
 
 type AppStatus struct {
@@ -54,7 +71,11 @@ type AppStatus struct {
 }
 
 type TaskStatus struct {
-	TaskID           string
+	TaskID string
+	// index of the request which produced the task, -1 if unknown
+	RequestIndex int
+	// group of the request which produced the task, empty if unknown
+	Group            string
 	CreateTime       time.Time
 	RunningTime      time.Time
 	NodeID           string
@@ -74,6 +95,8 @@ type TasksDistributionInfo struct {
 	MostNumNodeID   string
 	AvgNum          float64
 	SortedNodeInfos []*NodeInfo
+	// fairness of the number of tasks across nodes
+	Fairness utils.FairnessIndices
 }
 
 type TaskConditionType string
@@ -87,6 +110,13 @@ const (
 	ContainersReady TaskConditionType = "ContainersReady"
 )
 
+// EndToEndStageName is the name of the stage from pod creation to containers ready
+var EndToEndStageName = GetStageName(PodCreated, ContainersReady)
+
+func GetStageName(from, to TaskConditionType) string {
+	return string(from) + "->" + string(to)
+}
+
 func NewRequestInfo(number int32, priorityClass string, requestResources, limitResources map[string]string) *RequestInfo {
 	return &RequestInfo{
 		Number:           number,
@@ -112,6 +142,7 @@ func NewTaskStatus(taskID, nodeID string, createTime, runningTime time.Time,
 	requestResources *resources.Resource, conditions []*TaskCondition) *TaskStatus {
 	return &TaskStatus{
 		TaskID:           taskID,
+		RequestIndex:     -1,
 		CreateTime:       createTime,
 		RunningTime:      runningTime,
 		NodeID:           nodeID,
@@ -126,6 +157,40 @@ func (appInfo *AppInfo) SetAppStatus(desiredNum, createdNum, readyNum int) {
 	appInfo.AppStatus.ReadyNum = readyNum
 }
 
+// GetQueue returns the queue of the request, which is the queue of the app if not specified
+func (requestInfo *RequestInfo) GetQueue(appInfo *AppInfo) string {
+	if requestInfo.Queue != "" {
+		return requestInfo.Queue
+	}
+	return appInfo.Queue
+}
+
+// GetRequestGroup returns the group of the request at the specified index,
+// which is named after the index if the request has no group name
+func (appInfo *AppInfo) GetRequestGroup(reqIndex int) string {
+	if reqIndex < 0 || reqIndex >= len(appInfo.RequestInfos) {
+		return ""
+	}
+	if group := appInfo.RequestInfos[reqIndex].Group; group != "" {
+		return group
+	}
+	return fmt.Sprintf("request-%d", reqIndex)
+}
+
+// GetRequestGroups returns distinct groups of all requests in order
+func (appInfo *AppInfo) GetRequestGroups() []string {
+	var groups []string
+	seen := make(map[string]bool)
+	for reqIndex := range appInfo.RequestInfos {
+		group := appInfo.GetRequestGroup(reqIndex)
+		if !seen[group] {
+			seen[group] = true
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
 func (appInfo *AppInfo) GetDesiredNumTasks() int32 {
 	var desiredNum int32
 	for _, requestInfo := range appInfo.RequestInfos {
@@ -21,6 +21,7 @@ package framework
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/apache/yunikorn-release/perf-tools/constants"
@@ -29,8 +30,6 @@ import (
 	"go.uber.org/zap"
 
 	appsv1 "k8s.io/api/apps/v1"
-	apiv1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -47,54 +46,54 @@ type AppManager interface {
 	DeleteWait(appInfo *AppInfo, timeout time.Duration) error
 }
 
+var deploymentNameRegexp = regexp.MustCompile(`[_\W]`)
+
 type DeploymentsAppManager struct {
 	kubeClient *utils.KubeClient
-	nameRegexp *regexp.Regexp
 }
 
 func NewDeploymentsAppManager(kubeClient *utils.KubeClient) AppManager {
-	regexp, _ := regexp.Compile(`[_\W]`)
 	return &DeploymentsAppManager{
 		kubeClient: kubeClient,
-		nameRegexp: regexp,
 	}
 }
 
 func (dam *DeploymentsAppManager) Create(schedulerName string, appInfo *AppInfo) error {
+	deployments, err := NewDeployments(schedulerName, appInfo)
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments {
+		err = dam.kubeClient.CreateDeployment(appInfo.Namespace, deployment)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewDeployments returns deployments of the app to be created, one for each request
+func NewDeployments(schedulerName string, appInfo *AppInfo) ([]*appsv1.Deployment, error) {
 	if len(appInfo.RequestInfos) == 0 {
-		return fmt.Errorf("request info not defined for app %s", appInfo.AppID)
+		return nil, fmt.Errorf("request info not defined for app %s", appInfo.AppID)
 	}
+	deployments := make([]*appsv1.Deployment, 0, len(appInfo.RequestInfos))
 	for reqIndex, requestInfo := range appInfo.RequestInfos {
-		// init container
-		var container apiv1.Container
-		if len(appInfo.PodSpec.Containers) > 0 {
-			container = appInfo.PodSpec.Containers[0]
-		} else {
-			container = apiv1.Container{}
-			container.Name = constants.DefaultContainerName
-			container.Image = constants.DefaultContainerImage
-		}
-		if requestInfo.RequestResources != nil {
-			if container.Resources.Requests == nil {
-				container.Resources.Requests = apiv1.ResourceList{}
-			}
-			for resourceName, resourceValue := range requestInfo.RequestResources {
-				container.Resources.Requests[apiv1.ResourceName(resourceName)] = resource.MustParse(resourceValue)
-			}
-		}
-		if requestInfo.LimitResources != nil {
-			if container.Resources.Limits == nil {
-				container.Resources.Limits = apiv1.ResourceList{}
-			}
-			for resourceName, resourceValue := range requestInfo.LimitResources {
-				container.Resources.Limits[apiv1.ResourceName(resourceName)] = resource.MustParse(resourceValue)
-			}
+		podTemplateSpec, err := NewPodTemplateSpec(schedulerName, appInfo, requestInfo)
+		if err != nil {
+			return nil, err
 		}
-		// init and create deployment
-		deployment := &appsv1.Deployment{
+		// tag pods with the request index so that tasks can be traced back to their request
+		podTemplateSpec.Labels[constants.LabelRequestIndex] = strconv.Itoa(reqIndex)
+		deployments = append(deployments, &appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+				Kind:       "Deployment",
+			},
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: appInfo.Namespace,
-				Name:      dam.getDeploymentName(appInfo, reqIndex),
+				Name:      getDeploymentName(appInfo, reqIndex),
+				Labels:    map[string]string{constants.LabelManagedBy: constants.ManagedByPerfTools},
 			},
 			Spec: appsv1.DeploymentSpec{
 				Replicas: &requestInfo.Number,
@@ -103,43 +102,21 @@ func (dam *DeploymentsAppManager) Create(schedulerName string, appInfo *AppInfo)
 						constants.LabelAppID: appInfo.AppID,
 					},
 				},
-				Template: apiv1.PodTemplateSpec{
-					ObjectMeta: metav1.ObjectMeta{
-						Labels: map[string]string{
-							constants.LabelAppID: appInfo.AppID,
-							constants.LabelQueue: appInfo.Queue,
-						},
-						Annotations: appInfo.PodTemplateSpec.Annotations,
-					},
-					Spec: apiv1.PodSpec{
-						SchedulerName: schedulerName,
-						HostNetwork:   appInfo.PodSpec.HostNetwork,
-						Containers: []apiv1.Container{
-							container,
-						},
-						Tolerations:       appInfo.PodSpec.Tolerations,
-						NodeSelector:      appInfo.PodSpec.NodeSelector,
-						PriorityClassName: requestInfo.PriorityClass,
-					},
-				},
+				Template: podTemplateSpec,
 			},
-		}
-		err := dam.kubeClient.CreateDeployment(appInfo.Namespace, deployment)
-		if err != nil {
-			return err
-		}
+		})
 	}
-	return nil
+	return deployments, nil
 }
 
-func (dam *DeploymentsAppManager) getDeploymentName(appInfo *AppInfo, reqIndex int) string {
-	normalizedName := dam.nameRegexp.ReplaceAllString(appInfo.AppID, "-")
+func getDeploymentName(appInfo *AppInfo, reqIndex int) string {
+	normalizedName := deploymentNameRegexp.ReplaceAllString(appInfo.AppID, "-")
 	return fmt.Sprintf("%s-%d", normalizedName, reqIndex)
 }
 
 func (dam *DeploymentsAppManager) Delete(appInfo *AppInfo) error {
 	for i := 0; i < len(appInfo.RequestInfos); i++ {
-		err := dam.kubeClient.DeleteDeployment(appInfo.Namespace, dam.getDeploymentName(appInfo, i))
+		err := dam.kubeClient.DeleteDeployment(appInfo.Namespace, getDeploymentName(appInfo, i))
 		if err != nil {
 			return err
 		}
@@ -152,7 +129,7 @@ func (dam *DeploymentsAppManager) RefreshAppStatus(appInfo *AppInfo) error {
 	firstCreateTime := time.Time{}
 	for i := 0; i < len(appInfo.RequestInfos); i++ {
 		createTime, metrics, err := dam.kubeClient.GetDeploymentInfo(
-			appInfo.Namespace, dam.getDeploymentName(appInfo, i))
+			appInfo.Namespace, getDeploymentName(appInfo, i))
 		if err != nil {
 			utils.Logger.Info("failed to refresh app status", zap.Error(err))
 			continue
@@ -180,7 +157,7 @@ func (dam *DeploymentsAppManager) RefreshTasksStatusAfterRunning(appInfo *AppInf
 	for _, pod := range podList.Items {
 		createTime := pod.CreationTimestamp.Time
 		startTime := pod.Status.StartTime.Time
-		requestResources := ParseResourceFromResourceList(&pod.Spec.Containers[0].Resources.Requests)
+		requestResources := GetPodRequestResource(&pod)
 		// init conditions map
 		condMap := make(map[TaskConditionType]*TaskCondition)
 		condMap[PodCreated] = &TaskCondition{
@@ -218,6 +195,10 @@ func (dam *DeploymentsAppManager) RefreshTasksStatusAfterRunning(appInfo *AppInf
 		}
 		taskStatus := NewTaskStatus(pod.Name, pod.Spec.NodeName,
 			createTime, runningTime, requestResources, conditions)
+		if reqIndex, parseErr := strconv.Atoi(pod.Labels[constants.LabelRequestIndex]); parseErr == nil {
+			taskStatus.RequestIndex = reqIndex
+			taskStatus.Group = appInfo.GetRequestGroup(reqIndex)
+		}
 		tasksStatus[taskStatus.TaskID] = taskStatus
 		// update maxRunningTime
 		if runningTime.After(maxRunningTime) {
@@ -236,7 +217,7 @@ func (dam *DeploymentsAppManager) RefreshTasksStatusAfterRunning(appInfo *AppInf
 func (dam *DeploymentsAppManager) WaitForAppsToBeCleanedUp(appInfo *AppInfo, timeout time.Duration) error {
 	startTime := time.Now()
 	i := 1
-	return waitForCondition(func() bool {
+	return WaitForCondition(func() bool {
 		err := dam.RefreshAppStatus(appInfo)
 		if err != nil {
 			return true
@@ -263,7 +244,7 @@ func (dam *DeploymentsAppManager) WaitForAppsToBeCleanedUp(appInfo *AppInfo, tim
 func (dam *DeploymentsAppManager) WaitForAppsToBeSatisfied(appInfo *AppInfo, timeout time.Duration) error {
 	startTime := time.Now()
 	i := 1
-	return waitForCondition(func() bool {
+	return WaitForCondition(func() bool {
 		err := dam.RefreshAppStatus(appInfo)
 		if err != nil {
 			return true
@@ -317,8 +298,8 @@ func (dam *DeploymentsAppManager) DeleteWait(appInfo *AppInfo,
 	return nil
 }
 
-// copied from yunikorn-k8shim to avoid importing too many dependencies
-func waitForCondition(eval func() bool, interval time.Duration, timeout time.Duration) error {
+// WaitForCondition is copied from yunikorn-k8shim to avoid importing too many dependencies
+func WaitForCondition(eval func() bool, interval time.Duration, timeout time.Duration) error {
 	deadline := time.Now().Add(timeout)
 	for {
 		if eval() {
@@ -0,0 +1,88 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/yunikorn-release/perf-tools/constants"
+	"github.com/apache/yunikorn-release/perf-tools/utils"
+)
+
+// CleanupResult is the number of leftovers deleted by Cleanup in every namespace
+type CleanupResult struct {
+	NumDeployments map[string]int
+	NumPods        map[string]int
+}
+
+// Cleanup deletes deployments and pods left behind by previous runs in all namespaces, which are found by the
+// managed-by label of this tool, and waits for the pods to be cleaned up. Nothing else is touched.
+func Cleanup(kubeClient *utils.KubeClient, timeout time.Duration) (*CleanupResult, error) {
+	listOptions := utils.GetListOptions(map[string]string{constants.LabelManagedBy: constants.ManagedByPerfTools})
+	result := &CleanupResult{
+		NumDeployments: make(map[string]int),
+		NumPods:        make(map[string]int),
+	}
+	// an empty namespace lists objects of all namespaces
+	deploymentList, err := kubeClient.GetDeployments("", listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %s", err.Error())
+	}
+	for _, deployment := range deploymentList.Items {
+		utils.Logger.Info("delete deployment", zap.String("namespace", deployment.Namespace),
+			zap.String("name", deployment.Name))
+		if err = kubeClient.DeleteDeployment(deployment.Namespace, deployment.Name); err != nil {
+			return nil, fmt.Errorf("failed to delete deployment %s/%s: %s", deployment.Namespace, deployment.Name,
+				err.Error())
+		}
+		result.NumDeployments[deployment.Namespace]++
+	}
+	// pods created directly or not yet deleted by their deployments
+	podList, err := kubeClient.GetPods("", listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %s", err.Error())
+	}
+	for _, pod := range podList.Items {
+		result.NumPods[pod.Namespace]++
+	}
+	namespaces := make([]string, 0, len(result.NumPods))
+	for namespace := range result.NumPods {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		utils.Logger.Info("delete pods", zap.String("namespace", namespace),
+			zap.Int("numPods", result.NumPods[namespace]))
+		if err = kubeClient.DeletePods(namespace, listOptions); err != nil {
+			return nil, fmt.Errorf("failed to delete pods in namespace %s: %s", namespace, err.Error())
+		}
+	}
+	err = WaitForCondition(func() bool {
+		remainingPodList, listErr := kubeClient.GetPods("", listOptions)
+		return listErr == nil && len(remainingPodList.Items) == 0
+	}, time.Second, timeout)
+	if err != nil {
+		return result, fmt.Errorf("failed to wait for pods to be cleaned up: %s", err.Error())
+	}
+	return result, nil
+}
@@ -19,10 +19,12 @@
 package framework
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"gopkg.in/yaml.v3"
 )
@@ -33,16 +35,38 @@ type Config struct {
 }
 
 type CommonConfig struct {
-	KubeConfigFile  string
+	KubeConfigFile  string `validate:"required"`
 	SchedulerName   string
-	MaxWaitSeconds  int
+	MaxWaitSeconds  int `validate:"required,positive"`
 	Queue           string
 	Namespace       string
-	OutputRootPath  string
+	OutputRootPath  string `validate:"required"`
 	OutputPath      string
 	NodeSelector    string
 	PodSpec         apiv1.PodSpec
 	PodTemplateSpec apiv1.PodTemplateSpec
+	// formats of output tables (txt, csv, tsv, md), can be overridden by scenario configs
+	TableFormats []string
+	// formats of output charts (svg, png, pdf)
+	ChartFormats []string
+	// track deletion of pods via watch and output deletion analysis when apps are deleted
+	AnalyzeDeletion bool
+	// print progress of verifications to the console as they happen
+	ConsoleProgress bool
+	// where the config of YuniKorn is stored and served, used to switch scheduler configs between cases
+	YuniKorn *YuniKornConfig
+	// optional endpoint to which events of results are posted as JSON, e.g. http://localhost:8080/events
+	EventsWebhookURL string
+	// per-scenario node selector, namespace and queue keyed by scenario name, which isolate scenarios
+	// running in parallel from each other
+	Isolation map[string]*IsolationConfig
+}
+
+type IsolationConfig struct {
+	// label selector of nodes, e.g. "pool=a", pods are also constrained to the selected nodes
+	NodeSelector string
+	Namespace    string
+	Queue        string
 }
 
 func InitConfig(configFile string) (*Config, error) {
@@ -50,10 +74,54 @@ func InitConfig(configFile string) (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %s ", err.Error())
 	}
+	return ParseConfig(yamlContent)
+}
+
+// ParseConfig parses the YAML content of a config, e.g. the effective config merged from config layers
+func ParseConfig(yamlContent []byte) (*Config, error) {
 	conf := Config{}
-	err = yaml.Unmarshal(yamlContent, &conf)
-	if err != nil {
+	// unknown keys are rejected rather than silently ignored
+	decoder := yaml.NewDecoder(bytes.NewReader(yamlContent))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&conf); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %s ", err.Error())
 	}
 	return &conf, nil
 }
+
+// ForScenario returns the config for the specified scenario, in which the common config is a copy
+// overridden by the isolation config of the scenario if configured.
+func (conf *Config) ForScenario(scenarioName string) (*Config, error) {
+	isolation := conf.Common.Isolation[scenarioName]
+	if isolation == nil {
+		return conf, nil
+	}
+	commonConf := *conf.Common
+	if isolation.Namespace != "" {
+		commonConf.Namespace = isolation.Namespace
+	}
+	if isolation.Queue != "" {
+		commonConf.Queue = isolation.Queue
+	}
+	if isolation.NodeSelector != "" {
+		nodeLabels, err := labels.ConvertSelectorToLabelsMap(isolation.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node selector %s for scenario %s: %s", isolation.NodeSelector,
+				scenarioName, err.Error())
+		}
+		commonConf.NodeSelector = isolation.NodeSelector
+		// constrain pods to selected nodes without modifying the shared pod spec
+		nodeSelector := make(map[string]string, len(commonConf.PodSpec.NodeSelector)+len(nodeLabels))
+		for k, v := range commonConf.PodSpec.NodeSelector {
+			nodeSelector[k] = v
+		}
+		for k, v := range nodeLabels {
+			nodeSelector[k] = v
+		}
+		commonConf.PodSpec.NodeSelector = nodeSelector
+	}
+	return &Config{
+		Common:    &commonConf,
+		Scenarios: conf.Scenarios,
+	}, nil
+}
@@ -0,0 +1,279 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// prefix of environment variables overriding config fields, e.g. PERF_COMMON__MAXWAITSECONDS=900
+	ConfigEnvPrefix = "PERF_"
+	// name of the file in the output directory to which the effective config of a run is written
+	EffectiveConfigFileName = "effective-conf.yaml"
+
+	// keys in names of environment variables are separated by "__" since keys such as scenario names contain "_"
+	configEnvKeySeparator = "__"
+)
+
+// ConfigLayers are merged into the effective config in order, later layers take precedence:
+//  1. the base config file
+//  2. overlay files, mappings are merged by keys and other values (including lists) are replaced
+//  3. environment variables with the PERF_ prefix, keys of the path are separated by "__",
+//     e.g. PERF_SCENARIOS__THROUGHPUT__CLEANUPDELAYMS=100
+//  4. overrides in the form of path=value, e.g. common.maxWaitSeconds=900 or
+//     scenarios.throughput.cases[0].description=test
+//
+// Keys are matched case-insensitively, missing keys are created (in lowercase in the common section which is
+// decoded by yaml). Values of environment variables and overrides are parsed as YAML, quote them to keep
+// strings such as "123" as they are.
+type ConfigLayers struct {
+	BaseFile     string
+	OverlayFiles []string
+	// environment variables in the form of NAME=VALUE, only those with the prefix are applied
+	Env []string
+	// overrides in the form of path=value
+	Overrides []string
+}
+
+type configOverride struct {
+	source string
+	path   []string
+	value  string
+}
+
+// IsLayered returns true if there is any layer other than the base file
+func (cl *ConfigLayers) IsLayered() bool {
+	return len(cl.OverlayFiles) > 0 || len(cl.getEnvOverrides()) > 0 || len(cl.Overrides) > 0
+}
+
+// Merge returns the YAML content of the effective config, which is the content of the base file as it is
+// if there is no other layer. Aliases are expanded in the effective config.
+func (cl *ConfigLayers) Merge() ([]byte, error) {
+	baseContent, err := os.ReadFile(cl.BaseFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %s ", err.Error())
+	}
+	if !cl.IsLayered() {
+		return baseContent, nil
+	}
+	document, err := parseConfigDocument(baseContent, cl.BaseFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, overlayFile := range cl.OverlayFiles {
+		overlayContent, readErr := os.ReadFile(overlayFile)
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read overlay file: %s ", readErr.Error())
+		}
+		overlay, parseErr := parseConfigDocument(overlayContent, overlayFile)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		document.Content[0] = mergeNode(document.Content[0], overlay.Content[0])
+	}
+	overrides := cl.getEnvOverrides()
+	for _, override := range cl.Overrides {
+		path, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid override %q, expected path=value", override)
+		}
+		keys, parseErr := parseOverridePath(path)
+		if parseErr != nil {
+			return nil, fmt.Errorf("invalid override %q: %s", override, parseErr.Error())
+		}
+		overrides = append(overrides, &configOverride{source: override, path: keys, value: value})
+	}
+	for _, override := range overrides {
+		var valueNode *yaml.Node
+		if valueNode, err = parseValueNode(override.value); err == nil {
+			err = setNode(document.Content[0], override.path, valueNode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid override %q: %s", override.source, err.Error())
+		}
+	}
+	return encodeConfigDocument(document)
+}
+
+func (cl *ConfigLayers) getEnvOverrides() []*configOverride {
+	var overrides []*configOverride
+	for _, env := range cl.Env {
+		name, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, ConfigEnvPrefix) || len(name) == len(ConfigEnvPrefix) {
+			continue
+		}
+		path := strings.Split(strings.ToLower(strings.TrimPrefix(name, ConfigEnvPrefix)), configEnvKeySeparator)
+		overrides = append(overrides, &configOverride{source: name, path: path, value: value})
+	}
+	return overrides
+}
+
+// parseConfigDocument parses the YAML content into a document of which the root is a mapping,
+// aliases are expanded so that overrides never affect other places sharing the same anchor
+func parseConfigDocument(yamlContent []byte, fileName string) (*yaml.Node, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(yamlContent, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %s ", fileName, err.Error())
+	}
+	if len(document.Content) == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	expandAliases(&document)
+	if document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("failed to parse config file %s: expected a mapping", fileName)
+	}
+	return &document, nil
+}
+
+func encodeConfigDocument(document *yaml.Node) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return nil, fmt.Errorf("failed to encode effective config: %s", err.Error())
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode effective config: %s", err.Error())
+	}
+	return buffer.Bytes(), nil
+}
+
+// parseValueNode parses the value as YAML, an empty value is null
+func parseValueNode(value string) (*yaml.Node, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &document); err != nil {
+		return nil, fmt.Errorf("failed to parse value: %s", err.Error())
+	}
+	if len(document.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}, nil
+	}
+	return document.Content[0], nil
+}
+
+// parseOverridePath parses a path such as scenarios.throughput.cases[0].description into keys,
+// indexes of lists are keys as well
+func parseOverridePath(path string) ([]string, error) {
+	var keys []string
+	for _, part := range strings.Split(path, ".") {
+		key, indexes, _ := strings.Cut(part, "[")
+		if key == "" {
+			return nil, fmt.Errorf("empty key in path %s", path)
+		}
+		keys = append(keys, key)
+		if indexes == "" {
+			continue
+		}
+		for _, index := range strings.Split(strings.TrimSuffix(indexes, "]"), "][") {
+			if _, err := strconv.Atoi(index); err != nil {
+				return nil, fmt.Errorf("invalid index [%s] in path %s", index, path)
+			}
+			keys = append(keys, index)
+		}
+	}
+	return keys, nil
+}
+
+func expandAliases(node *yaml.Node) {
+	for i, child := range node.Content {
+		if child.Kind == yaml.AliasNode && child.Alias != nil {
+			node.Content[i] = copyNode(child.Alias)
+		}
+		expandAliases(node.Content[i])
+	}
+}
+
+func copyNode(node *yaml.Node) *yaml.Node {
+	copied := *node
+	copied.Anchor = ""
+	copied.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		copied.Content[i] = copyNode(child)
+	}
+	return &copied
+}
+
+// mergeNode merges the overlay into the base and returns the merged node, mappings are merged by keys
+// and other nodes are replaced by the overlay
+func mergeNode(base, overlay *yaml.Node) *yaml.Node {
+	if base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		return overlay
+	}
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		if index := getKeyIndex(base, overlay.Content[i].Value); index >= 0 {
+			base.Content[index+1] = mergeNode(base.Content[index+1], overlay.Content[i+1])
+		} else {
+			base.Content = append(base.Content, overlay.Content[i], overlay.Content[i+1])
+		}
+	}
+	return base
+}
+
+// setNode sets the value at the path of keys, missing keys of mappings are created
+func setNode(root *yaml.Node, path []string, valueNode *yaml.Node) error {
+	node := root
+	for i, key := range path {
+		var child **yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			index := getKeyIndex(node, key)
+			if index < 0 {
+				// the common section is decoded by yaml, of which keys are lowercase field names
+				if strings.EqualFold(path[0], commonKey) {
+					key = strings.ToLower(key)
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"})
+				index = len(node.Content) - 2
+			}
+			child = &node.Content[index+1]
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return fmt.Errorf("%s is not an index of the list at %s", key, strings.Join(path[:i], "."))
+			}
+			child = &node.Content[index]
+		default:
+			return fmt.Errorf("%s is not a mapping or a list", strings.Join(path[:i], "."))
+		}
+		if i == len(path)-1 {
+			*child = valueNode
+		} else if isNullNode(*child) {
+			*child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		node = *child
+	}
+	return nil
+}
+
+// getKeyIndex returns the index of the key matched case-insensitively in the mapping, or -1 if not found
+func getKeyIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, key) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,107 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestConfigLayers(t *testing.T) {
+	dir := t.TempDir()
+	baseFile := filepath.Join(dir, "base.yaml")
+	assert.NilError(t, os.WriteFile(baseFile, []byte(`
+common:
+  # comments are kept
+  maxwaitseconds: 600
+  queue: root.default
+scenarios:
+  throughput:
+    schedulerNames: &schedulers [yunikorn]
+    cases:
+      - description: small
+        cleanUpDelayMs: 0
+  node_fairness:
+    schedulerNames: *schedulers
+`), 0600))
+	overlayFile := filepath.Join(dir, "overlay.yaml")
+	assert.NilError(t, os.WriteFile(overlayFile, []byte(`
+common:
+  queue: root.overlay
+  namespace: overlay
+scenarios:
+  throughput:
+    schedulerNames: [yunikorn, default-scheduler]
+`), 0600))
+
+	// the base file is used as it is without other layers
+	configLayers := &ConfigLayers{BaseFile: baseFile, Env: []string{"HOME=/root"}}
+	assert.Assert(t, !configLayers.IsLayered())
+	content, err := configLayers.Merge()
+	assert.NilError(t, err)
+	baseContent, err := os.ReadFile(baseFile)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), string(baseContent))
+
+	configLayers = &ConfigLayers{
+		BaseFile:     baseFile,
+		OverlayFiles: []string{overlayFile},
+		Env: []string{"HOME=/root", "PERF_COMMON__NAMESPACE=env", "PERF_COMMON__MAXWAITSECONDS=700",
+			"PERF_SCENARIOS__NODE_FAIRNESS__SCHEDULERNAMES=[default-scheduler]"},
+		Overrides: []string{"common.maxWaitSeconds=900", "common.nodeSelector=pool=a",
+			"scenarios.throughput.cases[0].description=\"123\"", "scenarios.churn.targetPods=10"},
+	}
+	assert.Assert(t, configLayers.IsLayered())
+	content, err = configLayers.Merge()
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), `common:
+  # comments are kept
+  maxwaitseconds: 900
+  queue: root.overlay
+  namespace: env
+  nodeselector: pool=a
+scenarios:
+  throughput:
+    schedulerNames: [yunikorn, default-scheduler]
+    cases:
+      - description: "123"
+        cleanUpDelayMs: 0
+  node_fairness:
+    schedulerNames: [default-scheduler]
+  churn:
+    targetPods: 10
+`)
+
+	// invalid overrides
+	for override, expectedErr := range map[string]string{
+		"common.maxWaitSeconds":                      "expected path=value",
+		"common..queue=x":                            "empty key",
+		"scenarios.throughput.cases[x].description=": "invalid index [x]",
+		"scenarios.throughput.cases[1].description=": "1 is not an index of the list at scenarios.throughput.cases",
+		"common.queue.name=x":                        "common.queue is not a mapping or a list",
+	} {
+		_, err = (&ConfigLayers{BaseFile: baseFile, Overrides: []string{override}}).Merge()
+		assert.ErrorContains(t, err, expectedErr, override)
+	}
+	_, err = (&ConfigLayers{BaseFile: baseFile, OverlayFiles: []string{"not-exist.yaml"}}).Merge()
+	assert.ErrorContains(t, err, "failed to read overlay file")
+}
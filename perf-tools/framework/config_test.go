@@ -0,0 +1,68 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func TestForScenario(t *testing.T) {
+	conf := &Config{
+		Common: &CommonConfig{
+			Namespace:    "default",
+			Queue:        "root.default",
+			NodeSelector: "pool=shared",
+			PodSpec:      apiv1.PodSpec{NodeSelector: map[string]string{"disk": "ssd"}},
+			Isolation: map[string]*IsolationConfig{
+				"s1": {NodeSelector: "pool=a,zone=z1", Namespace: "ns-a", Queue: "root.a"},
+				"s2": {Queue: "root.b"},
+				"s3": {NodeSelector: "pool in (a, b)"},
+			},
+		},
+	}
+	// scenarios without isolation config share the config
+	scenarioConf, err := conf.ForScenario("unknown")
+	assert.NilError(t, err)
+	assert.Equal(t, scenarioConf, conf)
+
+	scenarioConf, err = conf.ForScenario("s1")
+	assert.NilError(t, err)
+	assert.Equal(t, scenarioConf.Common.Namespace, "ns-a")
+	assert.Equal(t, scenarioConf.Common.Queue, "root.a")
+	assert.Equal(t, scenarioConf.Common.NodeSelector, "pool=a,zone=z1")
+	assert.DeepEqual(t, scenarioConf.Common.PodSpec.NodeSelector,
+		map[string]string{"disk": "ssd", "pool": "a", "zone": "z1"})
+
+	scenarioConf, err = conf.ForScenario("s2")
+	assert.NilError(t, err)
+	assert.Equal(t, scenarioConf.Common.Namespace, "default")
+	assert.Equal(t, scenarioConf.Common.Queue, "root.b")
+	assert.Equal(t, scenarioConf.Common.NodeSelector, "pool=shared")
+
+	// set-based selectors can't be applied to pods
+	_, err = conf.ForScenario("s3")
+	assert.ErrorContains(t, err, "invalid node selector")
+
+	// the shared config is not modified
+	assert.Equal(t, conf.Common.Namespace, "default")
+	assert.DeepEqual(t, conf.Common.PodSpec.NodeSelector, map[string]string{"disk": "ssd"})
+}
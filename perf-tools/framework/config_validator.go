@@ -0,0 +1,594 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/apache/yunikorn-release/perf-tools/constants"
+)
+
+// rules of config fields, specified by the "validate" tag of fields, e.g. `validate:"required,positive"`
+const (
+	// the key must be configured with a non-empty value
+	RuleRequired = "required"
+	// numbers must be greater than 0
+	RulePositive = "positive"
+	// numbers must not be less than 0
+	RuleNonNegative = "nonnegative"
+	// strings, elements of lists or values of maps must be resource quantities, e.g. 100m, 1Gi
+	RuleQuantity = "quantity"
+	// strings or elements of lists must be known scheduler names
+	RuleScheduler = "scheduler"
+
+	validateTagName = "validate"
+	commonKey       = "common"
+	scenariosKey    = "scenarios"
+	casesKey        = "cases"
+	// cases of scenarios can have a matrix of parameters which is expanded into cases
+	matrixKey = "matrix"
+	// nested configs defined in this module are expanded when describing configs
+	configPkgPathPrefix = "github.com/apache/yunikorn-release/"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+var casePathRegexp = regexp.MustCompile(`^` + scenariosKey + `\.[^.]+\.` + casesKey + `\[\d+]$`)
+
+// ConfigError describes an invalid entry of the config file
+type ConfigError struct {
+	Line    int
+	Path    string
+	Message string
+}
+
+func (ce *ConfigError) Error() string {
+	return fmt.Sprintf("line %d: %s: %s", ce.Line, ce.Path, ce.Message)
+}
+
+// ValidateConfigFile validates the config file against the common config and configs of registered scenarios,
+// sections of the expected scenarios are required.
+func ValidateConfigFile(configFile string, expectedScenarioNames []string) ([]*ConfigError, error) {
+	yamlContent, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %s ", err.Error())
+	}
+	return ValidateConfigContent(yamlContent, expectedScenarioNames)
+}
+
+// ValidateConfigContent validates the YAML content of a config, e.g. the effective config merged from config
+// layers, against the common config and configs of registered scenarios.
+func ValidateConfigContent(yamlContent []byte, expectedScenarioNames []string) ([]*ConfigError, error) {
+	scenarioConfigs := make(map[string]interface{})
+	for name, testScenario := range GetRegisteredTestScenarios() {
+		scenarioConfigs[name] = testScenario.NewScenarioConfig()
+	}
+	return ValidateConfig(yamlContent, scenarioConfigs, expectedScenarioNames)
+}
+
+// ValidateConfig validates the YAML content of a config file and returns all invalid entries ordered by lines.
+// The common section is validated as it is decoded by yaml (keys are lowercase field names), scenario sections
+// are validated against the specified empty configs keyed by scenario names as they are decoded by mapstructure
+// (keys are case-insensitive field names). Scheduler names are known if they are the default scheduler,
+// YuniKorn or the scheduler name in the common section.
+func ValidateConfig(yamlContent []byte, scenarioConfigs map[string]interface{},
+	expectedScenarioNames []string) ([]*ConfigError, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(yamlContent, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %s ", err.Error())
+	}
+	cv := &configValidator{
+		schedulerNames: map[string]bool{
+			constants.SchedulerNameDefault:  true,
+			constants.SchedulerNameYuniKorn: true,
+		},
+	}
+	if len(document.Content) == 0 {
+		cv.addError(&document, "", "config is empty")
+		return cv.errors, nil
+	}
+	root := resolveNode(document.Content[0])
+	if root.Kind != yaml.MappingNode {
+		cv.addError(root, "", "expected a mapping")
+		return cv.errors, nil
+	}
+	commonNode := getValueNode(root, commonKey)
+	scenariosNode := getValueNode(root, scenariosKey)
+	if commonNode != nil {
+		if schedulerNameNode := getValueNode(commonNode, "schedulername"); schedulerNameNode != nil {
+			cv.schedulerNames[schedulerNameNode.Value] = true
+		}
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if key := root.Content[i].Value; key != commonKey && key != scenariosKey {
+			cv.addError(root.Content[i], key, "unknown key"+getSuggestion(key, []string{commonKey, scenariosKey}))
+		}
+	}
+	if isEmptyNode(commonNode) {
+		cv.addError(root, commonKey, "missing required key")
+	} else {
+		cv.validateNode(commonNode, reflect.TypeOf(CommonConfig{}), commonKey, false, nil)
+		cv.validateIsolation(commonNode, scenarioConfigs)
+	}
+	cv.validateScenarios(root, scenariosNode, scenarioConfigs, expectedScenarioNames)
+	sort.SliceStable(cv.errors, func(i, j int) bool {
+		return cv.errors[i].Line < cv.errors[j].Line
+	})
+	return cv.errors, nil
+}
+
+type configValidator struct {
+	schedulerNames map[string]bool
+	errors         []*ConfigError
+}
+
+func (cv *configValidator) addError(node *yaml.Node, path, message string) {
+	cv.errors = append(cv.errors, &ConfigError{Line: node.Line, Path: path, Message: message})
+}
+
+// validateIsolation checks that isolation configs are keyed by known scenario names
+func (cv *configValidator) validateIsolation(commonNode *yaml.Node, scenarioConfigs map[string]interface{}) {
+	isolationNode := getValueNode(commonNode, "isolation")
+	if isolationNode == nil || isolationNode.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(isolationNode.Content); i += 2 {
+		name := isolationNode.Content[i].Value
+		if _, ok := scenarioConfigs[name]; !ok {
+			cv.addError(isolationNode.Content[i], "common.isolation."+name,
+				"unknown scenario"+getSuggestion(name, getKeysOf(scenarioConfigs)))
+		}
+	}
+}
+
+func (cv *configValidator) validateScenarios(root, scenariosNode *yaml.Node, scenarioConfigs map[string]interface{},
+	expectedScenarioNames []string) {
+	if scenariosNode != nil && scenariosNode.Kind != yaml.MappingNode && !isEmptyNode(scenariosNode) {
+		cv.addError(scenariosNode, scenariosKey, "expected a mapping")
+		return
+	}
+	if scenariosNode != nil && scenariosNode.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(scenariosNode.Content); i += 2 {
+			name := scenariosNode.Content[i].Value
+			path := scenariosKey + "." + name
+			scenarioConfig, ok := scenarioConfigs[name]
+			if !ok {
+				cv.addError(scenariosNode.Content[i], path,
+					"unknown scenario"+getSuggestion(name, getKeysOf(scenarioConfigs)))
+				continue
+			}
+			cv.validateNode(scenariosNode.Content[i+1], reflect.TypeOf(scenarioConfig), path, true, nil)
+		}
+	}
+	for _, name := range expectedScenarioNames {
+		if _, ok := scenarioConfigs[name]; !ok {
+			cv.addError(root, scenariosKey+"."+name, "unknown scenario")
+		} else if scenariosNode == nil || isEmptyNode(getValueNode(scenariosNode, name)) {
+			cv.addError(root, scenariosKey+"."+name, "missing config of the scenario to run")
+		}
+	}
+}
+
+// validateNode validates the node against the type, rules are checked for scalars and elements of lists or maps.
+// Keys of structs are matched case-insensitively if caseInsensitive is true, as mapstructure does.
+func (cv *configValidator) validateNode(node *yaml.Node, t reflect.Type, path string, caseInsensitive bool,
+	rules []string) {
+	node = resolveNode(node)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if isNullNode(node) || t.Kind() == reflect.Interface {
+		return
+	}
+	// types with their own decoding (e.g. resource.Quantity) are not inspected
+	if t.Kind() == reflect.Struct && reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			cv.addError(node, path, "expected a mapping")
+			return
+		}
+		cv.validateStruct(node, t, path, caseInsensitive)
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			cv.addError(node, path, "expected a mapping")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			cv.validateNode(node.Content[i+1], t.Elem(), path+"."+node.Content[i].Value, caseInsensitive, rules)
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			cv.addError(node, path, "expected a list")
+			return
+		}
+		for i, elemNode := range node.Content {
+			cv.validateNode(elemNode, t.Elem(), fmt.Sprintf("%s[%d]", path, i), caseInsensitive, rules)
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			cv.addError(node, path, "expected a scalar")
+			return
+		}
+		cv.validateScalar(node, t, path, caseInsensitive, rules)
+	}
+}
+
+func (cv *configValidator) validateStruct(node *yaml.Node, t reflect.Type, path string, caseInsensitive bool) {
+	fieldsByKey := make(map[string]reflect.StructField)
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := getFieldKey(field, caseInsensitive)
+		if key == "-" {
+			continue
+		}
+		keys = append(keys, key)
+		fieldsByKey[strings.ToLower(key)] = field
+	}
+	configuredKeys := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		key := keyNode.Value
+		if caseInsensitive && strings.EqualFold(key, matrixKey) && casePathRegexp.MatchString(path) {
+			// keys swept by the matrix are configured as well
+			for _, sweptKey := range cv.validateMatrix(node.Content[i+1], t, joinPath(path, key)) {
+				configuredKeys[strings.ToLower(sweptKey)] = true
+			}
+			continue
+		}
+		field, ok := fieldsByKey[strings.ToLower(key)]
+		if !ok || !caseInsensitive && getFieldKey(field, caseInsensitive) != key {
+			cv.addError(keyNode, joinPath(path, key), "unknown key"+getSuggestion(key, keys))
+			continue
+		}
+		configuredKeys[strings.ToLower(key)] = !isEmptyNode(node.Content[i+1])
+		cv.validateNode(node.Content[i+1], field.Type, joinPath(path, key), caseInsensitive,
+			getRules(field))
+	}
+	for _, key := range keys {
+		field := fieldsByKey[strings.ToLower(key)]
+		if hasRule(getRules(field), RuleRequired) && !configuredKeys[strings.ToLower(key)] {
+			cv.addError(node, joinPath(path, key), "missing required key")
+		}
+	}
+}
+
+// validateMatrix validates parameters of the matrix of a case, which are paths of fields relative to the case
+// with lists of values, and returns keys of the fields which are swept as a whole
+func (cv *configValidator) validateMatrix(node *yaml.Node, caseType reflect.Type, path string) []string {
+	node = resolveNode(node)
+	if node.Kind != yaml.MappingNode {
+		cv.addError(node, path, "expected a mapping of parameters to lists of values")
+		return nil
+	}
+	var sweptKeys []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		paramPath := joinPath(path, keyNode.Value)
+		keys, err := parseOverridePath(keyNode.Value)
+		if err != nil {
+			cv.addError(keyNode, paramPath, err.Error())
+			continue
+		}
+		t, rules, err := getParameterType(caseType, keys)
+		if err != nil {
+			cv.addError(keyNode, paramPath, err.Error())
+			continue
+		}
+		// nested parameters are set into existing fields of the case
+		if len(keys) == 1 {
+			sweptKeys = append(sweptKeys, keys[0])
+		}
+		valuesNode := resolveNode(node.Content[i+1])
+		if valuesNode.Kind != yaml.SequenceNode || len(valuesNode.Content) == 0 {
+			cv.addError(valuesNode, paramPath, "expected a non-empty list of values")
+			continue
+		}
+		for j, valueNode := range valuesNode.Content {
+			cv.validateNode(valueNode, t, fmt.Sprintf("%s[%d]", paramPath, j), true, rules)
+		}
+	}
+	return sweptKeys
+}
+
+// getParameterType returns the type and rules of the field at the path of keys relative to the type of a case,
+// keys of structs are matched case-insensitively and keys of lists are indexes.
+func getParameterType(t reflect.Type, keys []string) (reflect.Type, []string, error) {
+	var rules []string
+	for i, key := range keys {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			var fieldKeys []string
+			var fieldType reflect.Type
+			for j := 0; j < t.NumField() && fieldType == nil; j++ {
+				field := t.Field(j)
+				fieldKey := getFieldKey(field, true)
+				if !field.IsExported() || fieldKey == "-" {
+					continue
+				}
+				fieldKeys = append(fieldKeys, fieldKey)
+				if strings.EqualFold(fieldKey, key) {
+					fieldType, rules = field.Type, getRules(field)
+				}
+			}
+			if fieldType == nil {
+				return nil, nil, fmt.Errorf("unknown parameter%s", getSuggestion(key, fieldKeys))
+			}
+			t = fieldType
+		case reflect.Slice, reflect.Array:
+			if _, err := strconv.Atoi(key); err != nil {
+				return nil, nil, fmt.Errorf("%s is not an index of the list at %s", key,
+					strings.Join(keys[:i], "."))
+			}
+			t = t.Elem()
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return nil, nil, fmt.Errorf("%s is not a mapping or a list", strings.Join(keys[:i], "."))
+		}
+	}
+	return t, rules, nil
+}
+
+func (cv *configValidator) validateScalar(node *yaml.Node, t reflect.Type, path string, caseInsensitive bool,
+	rules []string) {
+	var number float64
+	switch t.Kind() {
+	case reflect.String:
+		// mapstructure doesn't convert numbers or booleans to strings
+		if caseInsensitive && node.Tag != "!!str" {
+			cv.addError(node, path, fmt.Sprintf("expected a string, quote the value: %q", node.Value))
+			return
+		}
+	case reflect.Bool:
+		if node.Tag != "!!bool" {
+			cv.addError(node, path, fmt.Sprintf("expected a boolean: %q", node.Value))
+		}
+		return
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if node.Tag != "!!int" || node.Decode(&number) != nil {
+			cv.addError(node, path, fmt.Sprintf("expected an integer: %q", node.Value))
+			return
+		}
+	case reflect.Float32, reflect.Float64:
+		if node.Tag != "!!int" && node.Tag != "!!float" || node.Decode(&number) != nil {
+			cv.addError(node, path, fmt.Sprintf("expected a number: %q", node.Value))
+			return
+		}
+	default:
+		return
+	}
+	for _, rule := range rules {
+		switch {
+		case rule == RulePositive && t.Kind() != reflect.String && number <= 0:
+			cv.addError(node, path, fmt.Sprintf("must be greater than 0: %s", node.Value))
+		case rule == RuleNonNegative && t.Kind() != reflect.String && number < 0:
+			cv.addError(node, path, fmt.Sprintf("must not be less than 0: %s", node.Value))
+		case rule == RuleQuantity && t.Kind() == reflect.String:
+			if _, err := resource.ParseQuantity(node.Value); err != nil {
+				cv.addError(node, path, fmt.Sprintf("invalid quantity %q, expected e.g. 100m, 1Gi", node.Value))
+			}
+		case rule == RuleScheduler && t.Kind() == reflect.String && node.Value != "":
+			if !cv.schedulerNames[node.Value] {
+				cv.addError(node, path, fmt.Sprintf("unknown scheduler %q, known schedulers: %v", node.Value,
+					getKeysOf(cv.schedulerNames)))
+			}
+		}
+	}
+}
+
+// DescribeScenarioConfig returns the schema of a scenario config as indented lines of keys (as they are configured
+// in the config file), types and rules, nested configs defined in this module are expanded.
+func DescribeScenarioConfig(scenarioConfig interface{}) []string {
+	return describeStruct(reflect.TypeOf(scenarioConfig), "")
+}
+
+func describeStruct(t reflect.Type, indent string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	lines := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := getFieldKey(field, true)
+		if key == "-" {
+			continue
+		}
+		typeName, nestedType := describeType(field.Type)
+		line := indent + key + ": " + typeName
+		if rules := getRules(field); len(rules) > 0 {
+			line += " (" + strings.Join(rules, ", ") + ")"
+		}
+		lines = append(lines, line)
+		if nestedType != nil {
+			lines = append(lines, describeStruct(nestedType, indent+"  ")...)
+		}
+	}
+	return lines
+}
+
+// describeType returns the name of the type without pointers, and the nested config to be expanded if there is
+func describeType(t reflect.Type) (string, reflect.Type) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return describeType(t.Elem())
+	case reflect.Slice, reflect.Array:
+		name, nestedType := describeType(t.Elem())
+		return "[]" + name, nestedType
+	case reflect.Map:
+		name, nestedType := describeType(t.Elem())
+		return "map[" + t.Key().String() + "]" + name, nestedType
+	case reflect.Struct:
+		if strings.HasPrefix(t.PkgPath(), configPkgPathPrefix) {
+			return "object", t
+		}
+	}
+	return t.String(), nil
+}
+
+// getFieldKey returns the key of a field: the name in the yaml tag or the lowercase field name for yaml,
+// the name in the mapstructure tag or the camel case field name for mapstructure (which matches keys
+// case-insensitively)
+func getFieldKey(field reflect.StructField, caseInsensitive bool) string {
+	tagName := "yaml"
+	if caseInsensitive {
+		tagName = "mapstructure"
+	}
+	if name, _, _ := strings.Cut(field.Tag.Get(tagName), ","); name != "" {
+		return name
+	}
+	if caseInsensitive {
+		return strings.ToLower(field.Name[:1]) + field.Name[1:]
+	}
+	return strings.ToLower(field.Name)
+}
+
+func getRules(field reflect.StructField) []string {
+	tag := field.Tag.Get(validateTagName)
+	if tag == "" {
+		return nil
+	}
+	return strings.Split(tag, ",")
+}
+
+func hasRule(rules []string, rule string) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// getValueNode returns the value node of the key in a mapping node, or nil if not found
+func getValueNode(node *yaml.Node, key string) *yaml.Node {
+	node = resolveNode(node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return resolveNode(node.Content[i+1])
+		}
+	}
+	return nil
+}
+
+func resolveNode(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+func isNullNode(node *yaml.Node) bool {
+	return node == nil || node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+func isEmptyNode(node *yaml.Node) bool {
+	node = resolveNode(node)
+	if isNullNode(node) {
+		return true
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value == ""
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(node.Content) == 0
+	default:
+		return false
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// getSuggestion returns a hint of the candidate closest to the key, or an empty string if none is close enough
+func getSuggestion(key string, candidates []string) string {
+	bestCandidate, bestDistance := "", 3
+	for _, candidate := range candidates {
+		if distance := getEditDistance(strings.ToLower(key), strings.ToLower(candidate)); distance < bestDistance {
+			bestCandidate, bestDistance = candidate, distance
+		}
+	}
+	if bestCandidate == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %q?", bestCandidate)
+}
+
+// getEditDistance returns the Levenshtein distance between two strings
+func getEditDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func getKeysOf[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,160 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+type testRequestConfig struct {
+	NumPods          int               `validate:"required,positive"`
+	RequestResources map[string]string `validate:"quantity"`
+}
+
+type testScenarioConfig struct {
+	SchedulerNames []string `validate:"required,scheduler"`
+	DelayMs        int      `validate:"nonnegative"`
+	Requests       []*testRequestConfig
+}
+
+type testCaseConfig struct {
+	Description   string
+	SchedulerName string               `validate:"scheduler"`
+	Requests      []*testRequestConfig `validate:"required"`
+}
+
+type testSweepScenarioConfig struct {
+	Cases []*testCaseConfig `validate:"required"`
+}
+
+func TestValidateConfig(t *testing.T) {
+	content := `
+common:
+  kubeconfigfile: /tmp/kubeconfig
+  maxwaitseconds: 60
+  outputrootpath: /tmp
+  schedulername: custom-scheduler
+  consoleProgress: true
+scenarios:
+  test:
+    schedulerNames:
+      - custom-scheduler
+      - unknown-scheduler
+    delayMs: -1
+    requests:
+      - numPod: 1
+      - numPods: 0
+        requestResources:
+          cpu: 1
+          memory: 1x
+  tset:
+    delayMs: 1
+`
+	scenarioConfigs := map[string]interface{}{"test": &testScenarioConfig{}, "other": &testScenarioConfig{}}
+	configErrors, err := ValidateConfig([]byte(content), scenarioConfigs, []string{"test", "other"})
+	assert.NilError(t, err)
+	var actual []ConfigError
+	for _, configErr := range configErrors {
+		actual = append(actual, *configErr)
+	}
+	assert.DeepEqual(t, actual, []ConfigError{
+		{Line: 2, Path: "scenarios.other", Message: "missing config of the scenario to run"},
+		{Line: 7, Path: "common.consoleProgress", Message: `unknown key, did you mean "consoleprogress"?`},
+		{Line: 12, Path: "scenarios.test.schedulerNames[1]",
+			Message: `unknown scheduler "unknown-scheduler", known schedulers: ` +
+				`[custom-scheduler default-scheduler yunikorn]`},
+		{Line: 13, Path: "scenarios.test.delayMs", Message: "must not be less than 0: -1"},
+		{Line: 15, Path: "scenarios.test.requests[0].numPod", Message: `unknown key, did you mean "numPods"?`},
+		{Line: 15, Path: "scenarios.test.requests[0].numPods", Message: "missing required key"},
+		{Line: 16, Path: "scenarios.test.requests[1].numPods", Message: "must be greater than 0: 0"},
+		{Line: 18, Path: "scenarios.test.requests[1].requestResources.cpu",
+			Message: `expected a string, quote the value: "1"`},
+		{Line: 19, Path: "scenarios.test.requests[1].requestResources.memory",
+			Message: `invalid quantity "1x", expected e.g. 100m, 1Gi`},
+		{Line: 20, Path: "scenarios.tset", Message: `unknown scenario, did you mean "test"?`},
+	})
+
+	// required keys of the common section
+	configErrors, err = ValidateConfig([]byte("common:\n  kubeconfigfile: x\n"), scenarioConfigs, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(configErrors), 2)
+	assert.Equal(t, configErrors[0].Error(), "line 2: common.maxwaitseconds: missing required key")
+	assert.Equal(t, configErrors[1].Error(), "line 2: common.outputrootpath: missing required key")
+
+	_, err = ValidateConfig([]byte("common: ["), scenarioConfigs, nil)
+	assert.ErrorContains(t, err, "failed to parse config file")
+}
+
+func TestValidateMatrix(t *testing.T) {
+	content := `
+common:
+  kubeconfigfile: /tmp/kubeconfig
+  maxwaitseconds: 60
+  outputrootpath: /tmp
+scenarios:
+  test:
+    cases:
+      - description: swept requests
+        matrix:
+          requests: [[{numPods: 1}], [{numPods: 2}]]
+      - matrix:
+          requests[0].numPods: [1, 0]
+          requests[0].requestResources.cpu: ["1", 2]
+          schedulerName: [yunikorn, unknown-scheduler]
+          request[0].numPods: [1]
+          description: x
+        requests:
+          - numPods: 1
+      - matrix: [schedulerName]
+`
+	scenarioConfigs := map[string]interface{}{"test": &testSweepScenarioConfig{}}
+	configErrors, err := ValidateConfig([]byte(content), scenarioConfigs, []string{"test"})
+	assert.NilError(t, err)
+	var actual []ConfigError
+	for _, configErr := range configErrors {
+		actual = append(actual, *configErr)
+	}
+	assert.DeepEqual(t, actual, []ConfigError{
+		{Line: 13, Path: "scenarios.test.cases[1].matrix.requests[0].numPods[1]",
+			Message: "must be greater than 0: 0"},
+		{Line: 14, Path: "scenarios.test.cases[1].matrix.requests[0].requestResources.cpu[1]",
+			Message: `expected a string, quote the value: "2"`},
+		{Line: 15, Path: "scenarios.test.cases[1].matrix.schedulerName[1]",
+			Message: `unknown scheduler "unknown-scheduler", known schedulers: [default-scheduler yunikorn]`},
+		{Line: 16, Path: "scenarios.test.cases[1].matrix.request[0].numPods",
+			Message: `unknown parameter, did you mean "requests"?`},
+		{Line: 17, Path: "scenarios.test.cases[1].matrix.description",
+			Message: "expected a non-empty list of values"},
+		{Line: 20, Path: "scenarios.test.cases[2].matrix",
+			Message: "expected a mapping of parameters to lists of values"},
+		{Line: 20, Path: "scenarios.test.cases[2].requests", Message: "missing required key"},
+	})
+}
+
+func TestDescribeScenarioConfig(t *testing.T) {
+	assert.DeepEqual(t, DescribeScenarioConfig(&testScenarioConfig{}), []string{
+		"schedulerNames: []string (required, scheduler)",
+		"delayMs: int (nonnegative)",
+		"requests: []object",
+		"  numPods: int (required, positive)",
+		"  requestResources: map[string]string (quantity)",
+	})
+}
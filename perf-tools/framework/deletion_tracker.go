@@ -0,0 +1,225 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/apache/yunikorn-release/perf-tools/constants"
+	"github.com/apache/yunikorn-release/perf-tools/utils"
+)
+
+// DeletionTracker watches pods of an app and records when they are terminating and deleted
+type DeletionTracker struct {
+	kubeClient *utils.KubeClient
+	appInfo    *AppInfo
+	watcher    watch.Interface
+	startTime  time.Time
+	deletions  map[string]*PodDeletion
+	lock       sync.RWMutex
+}
+
+type PodDeletion struct {
+	PodName          string
+	RequestResources *resources.Resource
+	// time when the deletion timestamp of the pod is observed
+	TerminatingTime time.Time
+	// time when the removal of the pod is observed by the watch, schedulers may release its resources later
+	DeletedTime time.Time
+}
+
+type DeletionStats struct {
+	NumPods int
+	// number of deleted pods in every second since the deletion started
+	DeletedPerSecond []int
+	// cumulative requested resources of removed pods at the end of every second since the deletion started
+	RemovedResources map[string][]int64
+	// seconds from the start of the deletion to the pod being deleted
+	DeletionLatencies []float64
+	// seconds from the pod being terminating to the pod being deleted
+	TerminationLatencies []float64
+	// seconds since the deletion started until pods holding half/all of requested resources of every type are
+	// removed, this is the pod-removal time observed by the client rather than the release seen by the scheduler
+	HalfResourcesRemovedSeconds float64
+	AllResourcesRemovedSeconds  float64
+}
+
+func NewDeletionTracker(kubeClient *utils.KubeClient, appInfo *AppInfo) *DeletionTracker {
+	return &DeletionTracker{
+		kubeClient: kubeClient,
+		appInfo:    appInfo,
+		deletions:  make(map[string]*PodDeletion),
+	}
+}
+
+// Start starts to watch pods of the app, it should be called right before the app is deleted
+func (dt *DeletionTracker) Start() error {
+	watcher, err := dt.kubeClient.WatchPods(dt.appInfo.Namespace,
+		utils.GetListOptions(map[string]string{constants.LabelAppID: dt.appInfo.AppID}))
+	if err != nil {
+		return err
+	}
+	dt.watcher = watcher
+	dt.startTime = time.Now()
+	go dt.handleEvents()
+	return nil
+}
+
+func (dt *DeletionTracker) Stop() {
+	if dt.watcher != nil {
+		dt.watcher.Stop()
+	}
+}
+
+func (dt *DeletionTracker) handleEvents() {
+	for event := range dt.watcher.ResultChan() {
+		pod, ok := event.Object.(*apiv1.Pod)
+		if !ok {
+			continue
+		}
+		now := time.Now()
+		dt.lock.Lock()
+		deletion, ok := dt.deletions[pod.Name]
+		if !ok {
+			deletion = &PodDeletion{PodName: pod.Name}
+			deletion.RequestResources = GetPodRequestResource(pod)
+			dt.deletions[pod.Name] = deletion
+		}
+		if pod.DeletionTimestamp != nil && deletion.TerminatingTime.IsZero() {
+			deletion.TerminatingTime = now
+		}
+		if event.Type == watch.Deleted {
+			if deletion.TerminatingTime.IsZero() {
+				deletion.TerminatingTime = now
+			}
+			deletion.DeletedTime = now
+		}
+		dt.lock.Unlock()
+	}
+	utils.Logger.Debug("stopped watching pods", zap.String("appID", dt.appInfo.AppID))
+}
+
+// WaitForPodsToBeDeleted waits for all observed pods to be deleted
+func (dt *DeletionTracker) WaitForPodsToBeDeleted(timeout time.Duration) error {
+	err := WaitForCondition(func() bool {
+		dt.lock.RLock()
+		defer dt.lock.RUnlock()
+		for _, deletion := range dt.deletions {
+			if deletion.DeletedTime.IsZero() {
+				return false
+			}
+		}
+		return true
+	}, 1*time.Second, timeout)
+	if err != nil {
+		return fmt.Errorf("failed to wait for pods to be deleted: %s", err.Error())
+	}
+	return nil
+}
+
+func (dt *DeletionTracker) GetDeletionStats() *DeletionStats {
+	dt.lock.RLock()
+	defer dt.lock.RUnlock()
+	deletions := make([]*PodDeletion, 0, len(dt.deletions))
+	for _, deletion := range dt.deletions {
+		deletions = append(deletions, deletion)
+	}
+	return CalculateDeletionStats(dt.startTime, deletions)
+}
+
+// CalculateDeletionStats calculates throughput, latencies and requested resources of removed pods of the deletion
+// started at the specified time, pods which are not deleted are skipped.
+func CalculateDeletionStats(startTime time.Time, deletions []*PodDeletion) *DeletionStats {
+	deleted := make([]*PodDeletion, 0, len(deletions))
+	totalResources := resources.NewResource()
+	for _, deletion := range deletions {
+		if deletion.DeletedTime.IsZero() {
+			continue
+		}
+		deleted = append(deleted, deletion)
+		if deletion.RequestResources != nil {
+			totalResources.AddTo(deletion.RequestResources)
+		}
+	}
+	sort.SliceStable(deleted, func(i, j int) bool {
+		return deleted[i].DeletedTime.Before(deleted[j].DeletedTime)
+	})
+	stats := &DeletionStats{
+		NumPods:              len(deleted),
+		RemovedResources:     make(map[string][]int64),
+		DeletionLatencies:    make([]float64, len(deleted)),
+		TerminationLatencies: make([]float64, len(deleted)),
+	}
+	if len(deleted) == 0 {
+		return stats
+	}
+	numSeconds := int(math.Floor(deleted[len(deleted)-1].DeletedTime.Sub(startTime).Seconds())) + 1
+	stats.DeletedPerSecond = make([]int, numSeconds)
+	for resourceName := range totalResources.Resources {
+		stats.RemovedResources[resourceName] = make([]int64, numSeconds)
+	}
+	removed := resources.NewResource()
+	halfRemoved := make(map[string]bool)
+	for i, deletion := range deleted {
+		latency := deletion.DeletedTime.Sub(startTime).Seconds()
+		stats.DeletionLatencies[i] = latency
+		stats.TerminationLatencies[i] = deletion.DeletedTime.Sub(deletion.TerminatingTime).Seconds()
+		second := int(math.Max(0, math.Floor(latency)))
+		stats.DeletedPerSecond[second]++
+		if deletion.RequestResources == nil {
+			continue
+		}
+		removed.AddTo(deletion.RequestResources)
+		for resourceName, quantity := range deletion.RequestResources.Resources {
+			if quantity <= 0 {
+				continue
+			}
+			if !halfRemoved[resourceName] &&
+				removed.Resources[resourceName]*2 >= totalResources.Resources[resourceName] {
+				halfRemoved[resourceName] = true
+				stats.HalfResourcesRemovedSeconds = math.Max(stats.HalfResourcesRemovedSeconds, latency)
+			}
+			stats.AllResourcesRemovedSeconds = math.Max(stats.AllResourcesRemovedSeconds, latency)
+		}
+	}
+	// fill cumulative removed resources for every second
+	removed = resources.NewResource()
+	index := 0
+	for second := 0; second < numSeconds; second++ {
+		secondEndTime := startTime.Add(time.Duration(second+1) * time.Second)
+		for ; index < len(deleted) && deleted[index].DeletedTime.Before(secondEndTime); index++ {
+			if deleted[index].RequestResources != nil {
+				removed.AddTo(deleted[index].RequestResources)
+			}
+		}
+		for resourceName, values := range stats.RemovedResources {
+			values[second] = int64(removed.Resources[resourceName])
+		}
+	}
+	return stats
+}
@@ -0,0 +1,65 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+)
+
+func TestCalculateDeletionStats(t *testing.T) {
+	startTime := time.Now()
+	newDeletion := func(name string, cpu resources.Quantity, terminatingMs, deletedMs int) *PodDeletion {
+		deletion := &PodDeletion{
+			PodName:          name,
+			RequestResources: resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": cpu}),
+		}
+		if terminatingMs >= 0 {
+			deletion.TerminatingTime = startTime.Add(time.Duration(terminatingMs) * time.Millisecond)
+		}
+		if deletedMs >= 0 {
+			deletion.DeletedTime = startTime.Add(time.Duration(deletedMs) * time.Millisecond)
+		}
+		return deletion
+	}
+
+	// no deleted pods
+	stats := CalculateDeletionStats(startTime, []*PodDeletion{newDeletion("pod-0", 100, 100, -1)})
+	assert.Equal(t, stats.NumPods, 0)
+	assert.Equal(t, len(stats.DeletedPerSecond), 0)
+
+	stats = CalculateDeletionStats(startTime, []*PodDeletion{
+		newDeletion("pod-3", 100, 2000, 2500),
+		newDeletion("pod-1", 100, 100, 500),
+		newDeletion("pod-2", 200, 100, 1500),
+		newDeletion("pod-4", 100, -1, -1),
+	})
+	assert.Equal(t, stats.NumPods, 3)
+	assert.DeepEqual(t, stats.DeletedPerSecond, []int{1, 1, 1})
+	assert.DeepEqual(t, stats.RemovedResources["vcore"], []int64{100, 300, 400})
+	assert.DeepEqual(t, stats.DeletionLatencies, []float64{0.5, 1.5, 2.5})
+	assert.DeepEqual(t, stats.TerminationLatencies, []float64{0.4, 1.4, 0.5})
+	// half of 400 vcore is removed after pod-2 is deleted
+	assert.Equal(t, stats.HalfResourcesRemovedSeconds, 1.5)
+	assert.Equal(t, stats.AllResourcesRemovedSeconds, 2.5)
+}
@@ -0,0 +1,242 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+
+	"github.com/apache/yunikorn-release/perf-tools/constants"
+	"github.com/apache/yunikorn-release/perf-tools/utils"
+)
+
+// CasePlan describes the apps a case of a scenario would create, which is used by the dry-run mode
+type CasePlan struct {
+	Description    string
+	SchedulerNames []string
+	AppInfos       []*AppInfo
+	// pods of the apps are created directly by the scenario rather than via deployments by the app manager
+	CreatePods bool
+	// apps are run one after another rather than at the same time
+	Sequential bool
+}
+
+// CaseLoad is the estimated peak load of a case against free resources of the selected nodes
+type CaseLoad struct {
+	NumPods           int
+	RequestedResource *resources.Resource
+	FreeResource      *resources.Resource
+	// reasons why the case can't fit on the selected nodes, empty if it fits
+	Warnings []string
+}
+
+// DryRunner renders the manifests which planned cases would create without creating them,
+// and estimates the load of every case against the free resources of the selected nodes
+type DryRunner struct {
+	kubeClient *utils.KubeClient
+	commonConf *CommonConfig
+}
+
+func NewDryRunner(kubeClient *utils.KubeClient, commonConf *CommonConfig) *DryRunner {
+	return &DryRunner{
+		kubeClient: kubeClient,
+		commonConf: commonConf,
+	}
+}
+
+// Run plans cases of the scenario, writes their manifests into the output directory and
+// outputs a table of estimated loads, a warning is logged for every case which can't fit.
+func (dr *DryRunner) Run(testScenario TestScenario) error {
+	plans, err := testScenario.Plan()
+	if err != nil {
+		return fmt.Errorf("failed to plan scenario %s: %s", testScenario.GetName(), err.Error())
+	}
+	nodeAnalyzer := NewNodeAnalyzer(dr.kubeClient, dr.commonConf.NodeSelector)
+	if err = nodeAnalyzer.InitNodeInfosBeforeTesting(); err != nil {
+		return fmt.Errorf("failed to init nodes: %v", err)
+	}
+	nodeAnalyzer.CalculateAllocatedResource()
+	freeResources := make([]*resources.Resource, 0, len(nodeAnalyzer.GetAllocatableNodes()))
+	for _, nodeInfo := range nodeAnalyzer.GetAllocatableNodes() {
+		freeResources = append(freeResources, resources.SubEliminateNegative(nodeInfo.Capacity,
+			nodeInfo.AllocatedResource.NodeResourceBefore))
+	}
+	table := &utils.Table{
+		Headers: []string{"Case", "Description", "Schedulers", "Apps", "Pods", "Requested", "Free", "Fits"},
+	}
+	for caseIndex, plan := range plans {
+		filePath := fmt.Sprintf("%s/%s-case%d-manifests.yaml", dr.commonConf.OutputPath, testScenario.GetName(),
+			caseIndex)
+		if err = WriteManifests(filePath, plan); err != nil {
+			return err
+		}
+		var load *CaseLoad
+		load, err = EstimateCaseLoad(plan, freeResources)
+		if err != nil {
+			return err
+		}
+		fits := "yes"
+		if len(load.Warnings) > 0 {
+			fits = "no: " + strings.Join(load.Warnings, "; ")
+			utils.Logger.Warn("case can't fit on the selected nodes",
+				zap.String("scenarioName", testScenario.GetName()), zap.Int("caseIndex", caseIndex),
+				zap.String("nodeSelector", dr.commonConf.NodeSelector), zap.Strings("warnings", load.Warnings))
+		}
+		table.Data = append(table.Data, []string{fmt.Sprintf("Case-%d", caseIndex), plan.Description,
+			strings.Join(plan.SchedulerNames, ","), strconv.Itoa(len(plan.AppInfos)), strconv.Itoa(load.NumPods),
+			load.RequestedResource.String(), load.FreeResource.String(), fits})
+		utils.Logger.Info("rendered manifests of case", zap.String("scenarioName", testScenario.GetName()),
+			zap.Int("caseIndex", caseIndex), zap.String("filePath", filePath))
+	}
+	fmt.Printf("dry run of scenario %s on %d selected nodes:\n", testScenario.GetName(), len(freeResources))
+	table.Print()
+	formats, err := utils.ParseTableFormats(dr.commonConf.TableFormats)
+	if err != nil {
+		return err
+	}
+	_, err = table.OutputFormats(fmt.Sprintf("%s/%s-dry-run", dr.commonConf.OutputPath, testScenario.GetName()),
+		formats)
+	return err
+}
+
+// WriteManifests writes the objects planned for every scheduler as a multi-document YAML file,
+// deployments are written as they are created by the app manager, pods created directly by the scenario
+// are written as one pod per request with a comment of the number of such pods.
+func WriteManifests(filePath string, plan *CasePlan) error {
+	var buffer bytes.Buffer
+	for _, schedulerName := range plan.SchedulerNames {
+		for _, appInfo := range plan.AppInfos {
+			objects, comments, err := getPlannedObjects(schedulerName, appInfo, plan.CreatePods)
+			if err != nil {
+				return fmt.Errorf("failed to render app %s: %s", appInfo.AppID, err.Error())
+			}
+			for i, object := range objects {
+				manifest, marshalErr := yaml.Marshal(object)
+				if marshalErr != nil {
+					return fmt.Errorf("failed to render app %s: %s", appInfo.AppID, marshalErr.Error())
+				}
+				buffer.WriteString("---\n# scheduler: " + schedulerName + ", " + comments[i] + "\n")
+				buffer.Write(manifest)
+			}
+		}
+	}
+	// #nosec G306 - manifests are not sensitive
+	if err := os.WriteFile(filePath, buffer.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write manifests: %s", err.Error())
+	}
+	return nil
+}
+
+func getPlannedObjects(schedulerName string, appInfo *AppInfo, createPods bool) ([]interface{}, []string, error) {
+	var objects []interface{}
+	var comments []string
+	if !createPods {
+		deployments, err := NewDeployments(schedulerName, appInfo)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, deployment := range deployments {
+			objects = append(objects, deployment)
+			comments = append(comments, fmt.Sprintf("app: %s, replicas: %d", appInfo.AppID, *deployment.Spec.Replicas))
+		}
+		return objects, comments, nil
+	}
+	for reqIndex, requestInfo := range appInfo.RequestInfos {
+		podTemplateSpec, err := NewPodTemplateSpec(schedulerName, appInfo, requestInfo)
+		if err != nil {
+			return nil, nil, err
+		}
+		pod := &apiv1.Pod{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: apiv1.SchemeGroupVersion.String(),
+				Kind:       "Pod",
+			},
+			ObjectMeta: podTemplateSpec.ObjectMeta,
+			Spec:       podTemplateSpec.Spec,
+		}
+		pod.Name = fmt.Sprintf("%s-%d", appInfo.AppID, reqIndex)
+		pod.Namespace = appInfo.Namespace
+		objects = append(objects, pod)
+		comments = append(comments, fmt.Sprintf("app: %s, pods: %d", appInfo.AppID, requestInfo.Number))
+	}
+	return objects, comments, nil
+}
+
+// EstimateCaseLoad estimates the peak load of a case, which is the sum of all apps if they run at the same time
+// or the max of them if they run one after another. Warnings are returned if the peak load exceeds the total
+// free resources, or a pod can't fit on any node. Other constraints such as affinity are not considered.
+func EstimateCaseLoad(plan *CasePlan, freeResources []*resources.Resource) (*CaseLoad, error) {
+	load := &CaseLoad{
+		RequestedResource: resources.NewResource(),
+		FreeResource:      resources.NewResource(),
+	}
+	for _, freeResource := range freeResources {
+		load.FreeResource.AddTo(freeResource)
+	}
+	schedulerName := constants.SchedulerNameDefault
+	if len(plan.SchedulerNames) > 0 {
+		schedulerName = plan.SchedulerNames[0]
+	}
+	for _, appInfo := range plan.AppInfos {
+		appResource := resources.NewResource()
+		for _, requestInfo := range appInfo.RequestInfos {
+			podTemplateSpec, err := NewPodTemplateSpec(schedulerName, appInfo, requestInfo)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render app %s: %s", appInfo.AppID, err.Error())
+			}
+			podResource := GetPodRequestResource(&apiv1.Pod{Spec: podTemplateSpec.Spec})
+			appResource.AddTo(resources.Multiply(podResource, int64(requestInfo.Number)))
+			if !fitsOnAnyNode(podResource, freeResources) {
+				load.Warnings = append(load.Warnings, fmt.Sprintf("pod %s of app %s fits on no node",
+					podResource.String(), appInfo.AppID))
+			}
+		}
+		numPods := int(appInfo.GetDesiredNumTasks())
+		if plan.Sequential {
+			load.NumPods = max(load.NumPods, numPods)
+			load.RequestedResource = resources.ComponentWiseMax(load.RequestedResource, appResource)
+		} else {
+			load.NumPods += numPods
+			load.RequestedResource.AddTo(appResource)
+		}
+	}
+	if !load.FreeResource.FitIn(load.RequestedResource) {
+		load.Warnings = append(load.Warnings, "requested resources exceed free resources of selected nodes")
+	}
+	return load, nil
+}
+
+func fitsOnAnyNode(podResource *resources.Resource, freeResources []*resources.Resource) bool {
+	for _, freeResource := range freeResources {
+		if freeResource.FitIn(podResource) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+)
+
+func TestEstimateCaseLoad(t *testing.T) {
+	podSpec := apiv1.PodSpec{
+		Containers: []apiv1.Container{{Name: "test", Image: "test:1.0"}},
+	}
+	app1 := NewAppInfo("ns", "app-1", "root.test", []*RequestInfo{
+		NewRequestInfo(4, "", map[string]string{"cpu": "500m"}, nil),
+	}, apiv1.PodTemplateSpec{}, podSpec)
+	app2 := NewAppInfo("ns", "app-2", "root.test", []*RequestInfo{
+		NewRequestInfo(2, "", map[string]string{"cpu": "1"}, nil),
+	}, apiv1.PodTemplateSpec{}, podSpec)
+	freeResources := []*resources.Resource{
+		resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 2000}),
+		resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1000}),
+	}
+
+	// apps run at the same time
+	plan := &CasePlan{SchedulerNames: []string{"yunikorn"}, AppInfos: []*AppInfo{app1, app2}}
+	load, err := EstimateCaseLoad(plan, freeResources)
+	assert.NilError(t, err)
+	assert.Equal(t, load.NumPods, 6)
+	assert.Equal(t, load.RequestedResource.Resources["vcore"], resources.Quantity(4000))
+	assert.Equal(t, load.FreeResource.Resources["vcore"], resources.Quantity(3000))
+	assert.Equal(t, len(load.Warnings), 1)
+	assert.Assert(t, strings.Contains(load.Warnings[0], "exceed free resources"))
+
+	// apps run one after another
+	plan.Sequential = true
+	load, err = EstimateCaseLoad(plan, freeResources)
+	assert.NilError(t, err)
+	assert.Equal(t, load.NumPods, 4)
+	assert.Equal(t, load.RequestedResource.Resources["vcore"], resources.Quantity(2000))
+	assert.Equal(t, len(load.Warnings), 0)
+
+	// a pod larger than every node
+	app3 := NewAppInfo("ns", "app-3", "root.test", []*RequestInfo{
+		NewRequestInfo(1, "", map[string]string{"cpu": "2500m"}, nil),
+	}, apiv1.PodTemplateSpec{}, podSpec)
+	load, err = EstimateCaseLoad(&CasePlan{AppInfos: []*AppInfo{app3}}, freeResources)
+	assert.NilError(t, err)
+	assert.Equal(t, len(load.Warnings), 1)
+	assert.Assert(t, strings.Contains(load.Warnings[0], "app-3 fits on no node"))
+}
+
+func TestWriteManifests(t *testing.T) {
+	podSpec := apiv1.PodSpec{
+		Containers: []apiv1.Container{{Name: "test", Image: "test:1.0"}},
+	}
+	appInfo := NewAppInfo("ns", "app-1", "root.test", []*RequestInfo{
+		NewRequestInfo(3, "", map[string]string{"cpu": "100m"}, nil),
+		NewRequestInfo(2, "", map[string]string{"memory": "1Gi"}, nil),
+	}, apiv1.PodTemplateSpec{}, podSpec)
+	plan := &CasePlan{SchedulerNames: []string{"yunikorn", "default-scheduler"}, AppInfos: []*AppInfo{appInfo}}
+	filePath := filepath.Join(t.TempDir(), "manifests.yaml")
+
+	// one deployment per request for every scheduler
+	assert.NilError(t, WriteManifests(filePath, plan))
+	content, err := os.ReadFile(filePath)
+	assert.NilError(t, err)
+	assert.Equal(t, strings.Count(string(content), "kind: Deployment"), 4)
+	assert.Equal(t, strings.Count(string(content), "schedulerName: default-scheduler"), 2)
+	assert.Assert(t, strings.Contains(string(content), "# scheduler: yunikorn, app: app-1, replicas: 3"))
+
+	// one pod per request if pods are created directly
+	plan.CreatePods = true
+	assert.NilError(t, WriteManifests(filePath, plan))
+	content, err = os.ReadFile(filePath)
+	assert.NilError(t, err)
+	assert.Equal(t, strings.Count(string(content), "kind: Pod"), 4)
+	assert.Assert(t, strings.Contains(string(content), "# scheduler: yunikorn, app: app-1, pods: 2"))
+}
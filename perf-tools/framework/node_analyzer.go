@@ -20,6 +20,8 @@ package framework
 
 import (
 	"fmt"
+	"math"
+	"sort"
 
 	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
@@ -132,10 +134,11 @@ func (na *NodeAnalyzer) GetTotalAllocatableResource() *resources.Resource {
 func (na *NodeAnalyzer) GetNodeResourceDistribution(tasksDistribution [][]*TaskStatus,
 	resourceName string) [10][]int {
 	// init node resources, key(string): <NodeID>, value([]int64): <CapacityResourceValue>, <AllocatedResourceValue>
+	// allocated resource starts from the snapshot before testing since tasks are replayed below
 	nodeResources := make(map[string][]int64)
 	for nodeID, nodeInfo := range na.allocatableNodes {
 		nodeResources[nodeID] = []int64{int64(nodeInfo.Capacity.Resources[resourceName]),
-			int64(nodeInfo.AllocatedResource.GetResource().Resources[resourceName])}
+			int64(nodeInfo.AllocatedResource.NodeResourceBefore.Resources[resourceName])}
 	}
 	// statistic the number of nodes in 10 buckets with different resource utilization levels
 	// ([0%,10%), [10%,20%), ..., [90%,100%]) in every second
@@ -154,6 +157,157 @@ func (na *NodeAnalyzer) GetNodeResourceDistribution(tasksDistribution [][]*TaskS
 	return buckets
 }
 
+// GetNodeUtilizationTimeline returns IDs of allocatable nodes in order and their utilization ratio
+// of the specified resource in every second, indexed by [node][second].
+func (na *NodeAnalyzer) GetNodeUtilizationTimeline(tasksDistribution [][]*TaskStatus,
+	resourceName string) ([]string, [][]float64) {
+	nodeIDs := na.getSortedNodeIDs()
+	nodeIndexes := make(map[string]int, len(nodeIDs))
+	capacities := make([]float64, len(nodeIDs))
+	allocated := make([]float64, len(nodeIDs))
+	for i, nodeID := range nodeIDs {
+		nodeInfo := na.allocatableNodes[nodeID]
+		nodeIndexes[nodeID] = i
+		capacities[i] = float64(nodeInfo.Capacity.Resources[resourceName])
+		allocated[i] = float64(nodeInfo.AllocatedResource.NodeResourceBefore.Resources[resourceName])
+	}
+	utilization := make([][]float64, len(nodeIDs))
+	for i := range utilization {
+		utilization[i] = make([]float64, len(tasksDistribution))
+	}
+	for second, tasksInCurSecond := range tasksDistribution {
+		for _, taskStatus := range tasksInCurSecond {
+			if i, ok := nodeIndexes[taskStatus.NodeID]; ok {
+				allocated[i] += float64(taskStatus.RequestResources.Resources[resourceName])
+			}
+		}
+		for i := range nodeIDs {
+			if capacities[i] > 0 {
+				utilization[i][second] = allocated[i] / capacities[i]
+			}
+		}
+	}
+	return nodeIDs, utilization
+}
+
+// GetNodeTasksTimeline returns IDs of allocatable nodes in order and the cumulative number of tasks
+// scheduled on them in every second, indexed by [node][second].
+func (na *NodeAnalyzer) GetNodeTasksTimeline(tasksDistribution [][]*TaskStatus) ([]string, [][]float64) {
+	nodeIDs := na.getSortedNodeIDs()
+	nodeIndexes := make(map[string]int, len(nodeIDs))
+	for i, nodeID := range nodeIDs {
+		nodeIndexes[nodeID] = i
+	}
+	numTasks := make([]float64, len(nodeIDs))
+	timeline := make([][]float64, len(nodeIDs))
+	for i := range timeline {
+		timeline[i] = make([]float64, len(tasksDistribution))
+	}
+	for second, tasksInCurSecond := range tasksDistribution {
+		for _, taskStatus := range tasksInCurSecond {
+			if i, ok := nodeIndexes[taskStatus.NodeID]; ok {
+				numTasks[i]++
+			}
+		}
+		for i := range nodeIDs {
+			timeline[i][second] = numTasks[i]
+		}
+	}
+	return nodeIDs, timeline
+}
+
+// GetNodeDominantShareTimeline returns IDs of allocatable nodes in order and their dominant share
+// (the max utilization ratio among the specified resources) in every second, indexed by [node][second].
+func (na *NodeAnalyzer) GetNodeDominantShareTimeline(tasksDistribution [][]*TaskStatus,
+	resourceNames []string) ([]string, [][]float64) {
+	var nodeIDs []string
+	var dominantShares [][]float64
+	for _, resourceName := range resourceNames {
+		var utilization [][]float64
+		nodeIDs, utilization = na.GetNodeUtilizationTimeline(tasksDistribution, resourceName)
+		if dominantShares == nil {
+			dominantShares = utilization
+			continue
+		}
+		for i := range utilization {
+			for second, ratio := range utilization[i] {
+				dominantShares[i][second] = math.Max(dominantShares[i][second], ratio)
+			}
+		}
+	}
+	return nodeIDs, dominantShares
+}
+
+// AnalyzeMultiResource analyzes the current usage of the specified resources on allocatable nodes:
+// dominant-resource share per node, free resources stranded on nodes where another resource is full
+// (utilization ratio >= fullThreshold) and fragmentation of free resources.
+func (na *NodeAnalyzer) AnalyzeMultiResource(resourceNames []string, fullThreshold float64) *MultiResourceAnalysis {
+	analysis := &MultiResourceAnalysis{
+		ResourceNames:     resourceNames,
+		Nodes:             make([]*NodeResourceUsage, 0, len(na.allocatableNodes)),
+		StrandedResources: make(map[string]int64),
+		Fragmentation:     make(map[string]float64),
+	}
+	totalFree := make(map[string]int64)
+	maxFree := make(map[string]int64)
+	dominantShares := make([]float64, 0, len(na.allocatableNodes))
+	for _, nodeID := range na.getSortedNodeIDs() {
+		nodeInfo := na.allocatableNodes[nodeID]
+		allocatedRes := nodeInfo.AllocatedResource.GetResource()
+		usage := &NodeResourceUsage{
+			NodeID:      nodeID,
+			Utilization: make(map[string]float64),
+			Free:        make(map[string]int64),
+		}
+		for _, resourceName := range resourceNames {
+			capacity := int64(nodeInfo.Capacity.Resources[resourceName])
+			allocated := int64(allocatedRes.Resources[resourceName])
+			free := max(capacity-allocated, 0)
+			usage.Free[resourceName] = free
+			if capacity > 0 {
+				usage.Utilization[resourceName] = float64(allocated) / float64(capacity)
+			}
+			if usage.DominantResource == "" || usage.Utilization[resourceName] > usage.DominantShare {
+				usage.DominantResource = resourceName
+				usage.DominantShare = usage.Utilization[resourceName]
+			}
+			totalFree[resourceName] += free
+			maxFree[resourceName] = max(maxFree[resourceName], free)
+		}
+		// free resources are stranded if any resource on this node is full
+		if usage.DominantShare >= fullThreshold {
+			for _, resourceName := range resourceNames {
+				if usage.Utilization[resourceName] < fullThreshold && usage.Free[resourceName] > 0 {
+					usage.Stranded = true
+					analysis.StrandedResources[resourceName] += usage.Free[resourceName]
+				}
+			}
+		}
+		if usage.Stranded {
+			analysis.NumStrandedNodes++
+		}
+		dominantShares = append(dominantShares, usage.DominantShare)
+		analysis.Nodes = append(analysis.Nodes, usage)
+	}
+	// fragmentation is 0 if all free resource is on a single node and approaches 1 as it scatters
+	for _, resourceName := range resourceNames {
+		if totalFree[resourceName] > 0 {
+			analysis.Fragmentation[resourceName] = 1 - float64(maxFree[resourceName])/float64(totalFree[resourceName])
+		}
+	}
+	analysis.DominantShareFairness = utils.CalculateFairnessIndices(dominantShares)
+	return analysis
+}
+
+func (na *NodeAnalyzer) getSortedNodeIDs() []string {
+	nodeIDs := make([]string, 0, len(na.allocatableNodes))
+	for nodeID := range na.allocatableNodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+	return nodeIDs
+}
+
 func calculateResourceDistribution(resources map[string][]int64) [10]int {
 	var buckets [10]int
 	for _, resourceValues := range resources {
@@ -194,10 +348,24 @@ func IsNodeReady(node *v1.Node) bool {
 	return false
 }
 
+// GetPodRequestResource returns the effective request of a pod as the scheduler sees it: the sum of all containers
+// and sidecars (init containers which are always restarted), or the request of an init container plus sidecars
+// started before it if that is larger.
 func GetPodRequestResource(pod *v1.Pod) *resources.Resource {
-	resource := resources.NewResource()
+	sidecarResource := resources.NewResource()
+	initResource := resources.NewResource()
+	for _, container := range pod.Spec.InitContainers {
+		containerResource := ParseResourceFromResourceList(&container.Resources.Requests)
+		if container.RestartPolicy != nil && *container.RestartPolicy == v1.ContainerRestartPolicyAlways {
+			sidecarResource.AddTo(containerResource)
+			initResource = resources.ComponentWiseMax(initResource, sidecarResource)
+		} else {
+			initResource = resources.ComponentWiseMax(initResource, resources.Add(sidecarResource, containerResource))
+		}
+	}
+	resource := sidecarResource.Clone()
 	for _, container := range pod.Spec.Containers {
 		resource.AddTo(ParseResourceFromResourceList(&container.Resources.Requests))
 	}
-	return resource
+	return resources.ComponentWiseMax(resource, initResource)
 }
@@ -0,0 +1,125 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	apiv1 "k8s.io/api/core/v1"
+	resource "k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/apache/yunikorn-core/pkg/common/resources"
+)
+
+func newTestNodeAnalyzer(capacities map[string]map[string]resources.Quantity) *NodeAnalyzer {
+	na := &NodeAnalyzer{allocatableNodes: make(map[string]*NodeInfo)}
+	for nodeID, capacity := range capacities {
+		na.allocatableNodes[nodeID] = NewNodeInfo(nodeID, resources.NewResourceFromMap(capacity),
+			resources.NewResource())
+	}
+	return na
+}
+
+func newTestTask(taskID, nodeID string, requests map[string]resources.Quantity) *TaskStatus {
+	return NewTaskStatus(taskID, nodeID, time.Time{}, time.Time{}, resources.NewResourceFromMap(requests), nil)
+}
+
+func TestNodeTimelines(t *testing.T) {
+	na := newTestNodeAnalyzer(map[string]map[string]resources.Quantity{
+		"node-1": {"vcore": 1000, "memory": 1000},
+		"node-2": {"vcore": 1000, "memory": 1000},
+	})
+	tasksDistribution := [][]*TaskStatus{
+		{newTestTask("t1", "node-1", map[string]resources.Quantity{"vcore": 500, "memory": 100})},
+		{},
+		{newTestTask("t2", "node-2", map[string]resources.Quantity{"vcore": 100, "memory": 800}),
+			newTestTask("t3", "unknown", map[string]resources.Quantity{"vcore": 100})},
+	}
+	nodeIDs, utilization := na.GetNodeUtilizationTimeline(tasksDistribution, "vcore")
+	assert.DeepEqual(t, nodeIDs, []string{"node-1", "node-2"})
+	assert.DeepEqual(t, utilization, [][]float64{{0.5, 0.5, 0.5}, {0, 0, 0.1}})
+
+	_, numTasks := na.GetNodeTasksTimeline(tasksDistribution)
+	assert.DeepEqual(t, numTasks, [][]float64{{1, 1, 1}, {0, 0, 1}})
+
+	_, dominantShares := na.GetNodeDominantShareTimeline(tasksDistribution, []string{"vcore", "memory"})
+	assert.DeepEqual(t, dominantShares, [][]float64{{0.5, 0.5, 0.5}, {0, 0, 0.8}})
+}
+
+func TestAnalyzeMultiResource(t *testing.T) {
+	na := newTestNodeAnalyzer(map[string]map[string]resources.Quantity{
+		"node-1": {"vcore": 1000, "memory": 1000},
+		"node-2": {"vcore": 1000, "memory": 1000},
+		"node-3": {"vcore": 1000, "memory": 1000},
+	})
+	// node-1: cpu is full while memory is mostly free
+	na.allocatableNodes["node-1"].AddTask(newTestTask("t1", "node-1",
+		map[string]resources.Quantity{"vcore": 1000, "memory": 200}))
+	// node-2: half used
+	na.allocatableNodes["node-2"].AddTask(newTestTask("t2", "node-2",
+		map[string]resources.Quantity{"vcore": 500, "memory": 500}))
+
+	analysis := na.AnalyzeMultiResource([]string{"vcore", "memory"}, 0.95)
+	assert.Equal(t, len(analysis.Nodes), 3)
+	node1 := analysis.Nodes[0]
+	assert.Equal(t, node1.NodeID, "node-1")
+	assert.Equal(t, node1.DominantResource, "vcore")
+	assert.Equal(t, node1.DominantShare, float64(1))
+	assert.Equal(t, node1.Stranded, true)
+	assert.Equal(t, analysis.Nodes[1].Stranded, false)
+	assert.Equal(t, analysis.Nodes[2].DominantShare, float64(0))
+	assert.Equal(t, analysis.NumStrandedNodes, 1)
+	assert.DeepEqual(t, analysis.StrandedResources, map[string]int64{"memory": 800})
+	// free vcore: 0 + 500 + 1000, the largest free chunk is 1000
+	assert.Assert(t, math.Abs(analysis.Fragmentation["vcore"]-1.0/3) < 1e-9)
+	assert.Equal(t, analysis.GetAvgDominantShare(), 0.5)
+}
+
+func TestGetPodRequestResource(t *testing.T) {
+	newContainer := func(cpu, memory string) apiv1.Container {
+		return apiv1.Container{Resources: apiv1.ResourceRequirements{Requests: apiv1.ResourceList{
+			apiv1.ResourceCPU:    resource.MustParse(cpu),
+			apiv1.ResourceMemory: resource.MustParse(memory),
+		}}}
+	}
+	sidecar := newContainer("100m", "100Mi")
+	restartPolicyAlways := apiv1.ContainerRestartPolicyAlways
+	sidecar.RestartPolicy = &restartPolicyAlways
+	pod := &apiv1.Pod{Spec: apiv1.PodSpec{
+		// a large init container, a sidecar, then an init container started after the sidecar
+		InitContainers: []apiv1.Container{newContainer("1", "10Mi"), sidecar, newContainer("50m", "1Gi")},
+		Containers:     []apiv1.Container{newContainer("200m", "200Mi"), newContainer("300m", "300Mi")},
+	}}
+	assert.DeepEqual(t, GetPodRequestResource(pod), resources.NewResourceFromMap(map[string]resources.Quantity{
+		// max of the first init container and containers plus the sidecar
+		"vcore": 1000,
+		// max of the last init container plus the sidecar and containers plus the sidecar
+		"memory": (1024 + 100) * 1024 * 1024,
+	}))
+
+	// containers only
+	pod.Spec.InitContainers = nil
+	assert.DeepEqual(t, GetPodRequestResource(pod), resources.NewResourceFromMap(map[string]resources.Quantity{
+		"vcore":  500,
+		"memory": 500 * 1024 * 1024,
+	}))
+}
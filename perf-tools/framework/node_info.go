@@ -22,6 +22,8 @@ import (
 	"fmt"
 
 	"github.com/apache/yunikorn-core/pkg/common/resources"
+
+	"github.com/apache/yunikorn-release/perf-tools/utils"
 )
 
 type NodeInfo struct {
@@ -37,6 +39,42 @@ type ResourceInfo struct {
 	NumTasks           int
 }
 
+// NodeResourceUsage describes the usage of multiple resources on a node
+type NodeResourceUsage struct {
+	NodeID string
+	// utilization ratio and free amount keyed by resource name
+	Utilization map[string]float64
+	Free        map[string]int64
+	// resource with the max utilization ratio and its ratio
+	DominantResource string
+	DominantShare    float64
+	// whether free resources are stranded since another resource on this node is full
+	Stranded bool
+}
+
+type MultiResourceAnalysis struct {
+	ResourceNames []string
+	Nodes         []*NodeResourceUsage
+	// total amount of free resources stranded on nodes, keyed by resource name
+	StrandedResources map[string]int64
+	NumStrandedNodes  int
+	// fragmentation score of free resources in [0, 1), keyed by resource name
+	Fragmentation         map[string]float64
+	DominantShareFairness utils.FairnessIndices
+}
+
+// GetAvgDominantShare returns the average dominant share of all nodes
+func (mra *MultiResourceAnalysis) GetAvgDominantShare() float64 {
+	if len(mra.Nodes) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, node := range mra.Nodes {
+		sum += node.DominantShare
+	}
+	return sum / float64(len(mra.Nodes))
+}
+
 func NewNodeInfo(nodeID string, capacityRes *resources.Resource, allocatedRes *resources.Resource) *NodeInfo {
 	return &NodeInfo{
 		NodeID:   nodeID,
@@ -0,0 +1,217 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+var nodeSelectorOperators = map[apiv1.NodeSelectorOperator]selection.Operator{
+	apiv1.NodeSelectorOpIn:           selection.In,
+	apiv1.NodeSelectorOpNotIn:        selection.NotIn,
+	apiv1.NodeSelectorOpExists:       selection.Exists,
+	apiv1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	apiv1.NodeSelectorOpGt:           selection.GreaterThan,
+	apiv1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// CheckPlacement checks whether scheduled pods satisfy their required scheduling constraints on the nodes
+// described by node labels (keyed by node name): node selector, required node affinity, required pod
+// affinity/anti-affinity and topology spread constraints with DoNotSchedule. Inter-pod constraints are only
+// evaluated among the specified pods. Descriptions of all violations are returned.
+func CheckPlacement(pods []*apiv1.Pod, nodeLabels map[string]map[string]string) []string {
+	var violations []string
+	checkedSpreadConstraints := make(map[string]bool)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		podNodeLabels := nodeLabels[pod.Spec.NodeName]
+		if !matchesNodeConstraints(pod, pod.Spec.NodeName, podNodeLabels) {
+			violations = append(violations, fmt.Sprintf("pod %s: node %s does not match node selector or affinity",
+				pod.Name, pod.Spec.NodeName))
+		}
+		if affinity := pod.Spec.Affinity; affinity != nil {
+			if affinity.PodAffinity != nil {
+				for _, term := range affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+					if !satisfiesPodAffinityTerm(pod, &term, pods, nodeLabels) {
+						violations = append(violations, fmt.Sprintf("pod %s: pod affinity on %s is not satisfied",
+							pod.Name, term.TopologyKey))
+					}
+				}
+			}
+			if affinity.PodAntiAffinity != nil {
+				for _, term := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+					if !satisfiesPodAntiAffinityTerm(pod, &term, pods, nodeLabels) {
+						violations = append(violations, fmt.Sprintf("pod %s: pod anti-affinity on %s is not satisfied",
+							pod.Name, term.TopologyKey))
+					}
+				}
+			}
+		}
+		for _, constraint := range pod.Spec.TopologySpreadConstraints {
+			if constraint.WhenUnsatisfiable != apiv1.DoNotSchedule {
+				continue
+			}
+			// pods created from the same template share the constraint, only check it once
+			constraintKey := fmt.Sprintf("%s/%d/%s", constraint.TopologyKey, constraint.MaxSkew,
+				metav1.FormatLabelSelector(constraint.LabelSelector))
+			if checkedSpreadConstraints[constraintKey] {
+				continue
+			}
+			checkedSpreadConstraints[constraintKey] = true
+			if skew := getTopologySkew(pod, &constraint, pods, nodeLabels); skew > int(constraint.MaxSkew) {
+				violations = append(violations, fmt.Sprintf("topology spread on %s: skew %d exceeds max skew %d",
+					constraint.TopologyKey, skew, constraint.MaxSkew))
+			}
+		}
+	}
+	return violations
+}
+
+// matchesNodeConstraints returns whether the node matches node selector and required node affinity of the pod
+func matchesNodeConstraints(pod *apiv1.Pod, nodeName string, nodeLabels map[string]string) bool {
+	if !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(labels.Set(nodeLabels)) {
+		return false
+	}
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil ||
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	// terms are ORed
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if matchesNodeSelectorTerm(&term, nodeName, nodeLabels) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesNodeSelectorTerm(term *apiv1.NodeSelectorTerm, nodeName string, nodeLabels map[string]string) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	for _, expr := range term.MatchExpressions {
+		if !matchesNodeSelectorRequirement(&expr, labels.Set(nodeLabels)) {
+			return false
+		}
+	}
+	for _, expr := range term.MatchFields {
+		// metadata.name is the only supported field
+		if expr.Key != "metadata.name" || !matchesNodeSelectorRequirement(&expr, labels.Set{expr.Key: nodeName}) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesNodeSelectorRequirement(expr *apiv1.NodeSelectorRequirement, labelSet labels.Set) bool {
+	op, ok := nodeSelectorOperators[expr.Operator]
+	if !ok {
+		return false
+	}
+	requirement, err := labels.NewRequirement(expr.Key, op, expr.Values)
+	if err != nil {
+		return false
+	}
+	return requirement.Matches(labelSet)
+}
+
+// getMatchingPods returns the number of pods other than the specified one which match the selector
+// in the same topology domain, and the number of those pods in all domains
+func getMatchingPods(pod *apiv1.Pod, term *apiv1.PodAffinityTerm, pods []*apiv1.Pod,
+	nodeLabels map[string]map[string]string) (int, int) {
+	selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+	if err != nil {
+		return 0, 0
+	}
+	domain, ok := nodeLabels[pod.Spec.NodeName][term.TopologyKey]
+	var inDomain, total int
+	for _, other := range pods {
+		if other.Name == pod.Name || other.Spec.NodeName == "" || !selector.Matches(labels.Set(other.Labels)) {
+			continue
+		}
+		total++
+		if otherDomain, otherOK := nodeLabels[other.Spec.NodeName][term.TopologyKey]; ok && otherOK &&
+			otherDomain == domain {
+			inDomain++
+		}
+	}
+	return inDomain, total
+}
+
+func satisfiesPodAffinityTerm(pod *apiv1.Pod, term *apiv1.PodAffinityTerm, pods []*apiv1.Pod,
+	nodeLabels map[string]map[string]string) bool {
+	inDomain, total := getMatchingPods(pod, term, pods, nodeLabels)
+	// the affinity can't be satisfied by other pods if there is none
+	return inDomain > 0 || total == 0
+}
+
+func satisfiesPodAntiAffinityTerm(pod *apiv1.Pod, term *apiv1.PodAffinityTerm, pods []*apiv1.Pod,
+	nodeLabels map[string]map[string]string) bool {
+	inDomain, _ := getMatchingPods(pod, term, pods, nodeLabels)
+	return inDomain == 0
+}
+
+// getTopologySkew returns the difference between the most and least number of matching pods in topology
+// domains of nodes which match node constraints of the pod
+func getTopologySkew(pod *apiv1.Pod, constraint *apiv1.TopologySpreadConstraint, pods []*apiv1.Pod,
+	nodeLabels map[string]map[string]string) int {
+	selector, err := metav1.LabelSelectorAsSelector(constraint.LabelSelector)
+	if err != nil {
+		return 0
+	}
+	domainCounts := make(map[string]int)
+	for nodeName, labelsOfNode := range nodeLabels {
+		if domain, ok := labelsOfNode[constraint.TopologyKey]; ok && matchesNodeConstraints(pod, nodeName,
+			labelsOfNode) {
+			// count pods in eligible domains only, domains without matching pods count as zero
+			domainCounts[domain] = 0
+		}
+	}
+	for _, other := range pods {
+		if other.Spec.NodeName == "" || !selector.Matches(labels.Set(other.Labels)) {
+			continue
+		}
+		if domain, ok := nodeLabels[other.Spec.NodeName][constraint.TopologyKey]; ok {
+			if _, eligible := domainCounts[domain]; eligible {
+				domainCounts[domain]++
+			}
+		}
+	}
+	if len(domainCounts) == 0 {
+		return 0
+	}
+	minCount, maxCount := -1, 0
+	for _, count := range domainCounts {
+		if minCount < 0 || count < minCount {
+			minCount = count
+		}
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+	return maxCount - minCount
+}
@@ -0,0 +1,109 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestPod(name, nodeName string, affinity *apiv1.Affinity,
+	spreadConstraints []apiv1.TopologySpreadConstraint) *apiv1.Pod {
+	return &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"app": "test"}},
+		Spec: apiv1.PodSpec{
+			NodeName:                  nodeName,
+			Affinity:                  affinity,
+			TopologySpreadConstraints: spreadConstraints,
+		},
+	}
+}
+
+func TestCheckPlacement(t *testing.T) {
+	nodeLabels := map[string]map[string]string{
+		"node-1": {"zone": "a", "disk": "ssd"},
+		"node-2": {"zone": "a", "disk": "hdd"},
+		"node-3": {"zone": "b", "disk": "ssd"},
+	}
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}}
+
+	// required node affinity
+	nodeAffinity := &apiv1.Affinity{NodeAffinity: &apiv1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &apiv1.NodeSelector{
+			NodeSelectorTerms: []apiv1.NodeSelectorTerm{{MatchExpressions: []apiv1.NodeSelectorRequirement{
+				{Key: "disk", Operator: apiv1.NodeSelectorOpIn, Values: []string{"ssd"}},
+			}}},
+		},
+	}}
+	violations := CheckPlacement([]*apiv1.Pod{
+		newTestPod("pod-1", "node-1", nodeAffinity, nil),
+		newTestPod("pod-2", "node-2", nodeAffinity, nil),
+		newTestPod("pod-3", "", nodeAffinity, nil),
+	}, nodeLabels)
+	assert.Equal(t, len(violations), 1)
+
+	// required pod anti-affinity on zone
+	antiAffinity := &apiv1.Affinity{PodAntiAffinity: &apiv1.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []apiv1.PodAffinityTerm{
+			{LabelSelector: selector, TopologyKey: "zone"},
+		},
+	}}
+	violations = CheckPlacement([]*apiv1.Pod{
+		newTestPod("pod-1", "node-1", antiAffinity, nil),
+		newTestPod("pod-2", "node-3", antiAffinity, nil),
+	}, nodeLabels)
+	assert.Equal(t, len(violations), 0)
+	violations = CheckPlacement([]*apiv1.Pod{
+		newTestPod("pod-1", "node-1", antiAffinity, nil),
+		newTestPod("pod-2", "node-2", antiAffinity, nil),
+	}, nodeLabels)
+	assert.Equal(t, len(violations), 2)
+
+	// required pod affinity on zone
+	podAffinity := &apiv1.Affinity{PodAffinity: &apiv1.PodAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []apiv1.PodAffinityTerm{
+			{LabelSelector: selector, TopologyKey: "zone"},
+		},
+	}}
+	violations = CheckPlacement([]*apiv1.Pod{
+		newTestPod("pod-1", "node-1", podAffinity, nil),
+		newTestPod("pod-2", "node-2", podAffinity, nil),
+		newTestPod("pod-3", "node-3", podAffinity, nil),
+	}, nodeLabels)
+	assert.Equal(t, len(violations), 1)
+
+	// topology spread on zone with max skew 1
+	spreadConstraints := []apiv1.TopologySpreadConstraint{{
+		MaxSkew: 1, TopologyKey: "zone", WhenUnsatisfiable: apiv1.DoNotSchedule, LabelSelector: selector,
+	}}
+	violations = CheckPlacement([]*apiv1.Pod{
+		newTestPod("pod-1", "node-1", nil, spreadConstraints),
+		newTestPod("pod-2", "node-3", nil, spreadConstraints),
+		newTestPod("pod-3", "node-2", nil, spreadConstraints),
+	}, nodeLabels)
+	assert.Equal(t, len(violations), 0)
+	violations = CheckPlacement([]*apiv1.Pod{
+		newTestPod("pod-1", "node-1", nil, spreadConstraints),
+		newTestPod("pod-2", "node-2", nil, spreadConstraints),
+	}, nodeLabels)
+	assert.Equal(t, len(violations), 1)
+}
@@ -0,0 +1,148 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/apache/yunikorn-release/perf-tools/constants"
+)
+
+// NewPodTemplateSpec returns the template of pods for the specified request of the app.
+// The template is deep-merged from the following layers, later layers take precedence:
+//  1. the pod template spec of the app (labels, annotations and spec)
+//  2. the pod spec of the app
+//  3. overrides of the request (labels, annotations, priority class, node selector, tolerations, affinity,
+//     topology spread constraints and runtime class)
+//  4. generated fields: applicationId, queue, requestGroup and managed-by labels, scheduler name
+//
+// Maps are merged by keys, other fields (including lists such as containers and volumes) are replaced
+// by the later layer only if they are set. Image, command, requested and limited resources of the request
+// are applied to the first container, a default container is used if no container is configured.
+func NewPodTemplateSpec(schedulerName string, appInfo *AppInfo, requestInfo *RequestInfo) (apiv1.PodTemplateSpec,
+	error) {
+	podTemplateSpec := apiv1.PodTemplateSpec{}
+	requestOverrides := apiv1.PodTemplateSpec{}
+	requestOverrides.Labels = requestInfo.Labels
+	requestOverrides.Annotations = requestInfo.Annotations
+	requestOverrides.Spec = apiv1.PodSpec{
+		PriorityClassName:         requestInfo.PriorityClass,
+		NodeSelector:              requestInfo.NodeSelector,
+		Tolerations:               requestInfo.Tolerations,
+		Affinity:                  requestInfo.Affinity,
+		TopologySpreadConstraints: requestInfo.TopologySpreadConstraints,
+	}
+	if requestInfo.RuntimeClassName != "" {
+		requestOverrides.Spec.RuntimeClassName = &requestInfo.RuntimeClassName
+	}
+	generatedTemplateSpec := apiv1.PodTemplateSpec{}
+	generatedTemplateSpec.Labels = map[string]string{
+		constants.LabelAppID:     appInfo.AppID,
+		constants.LabelQueue:     requestInfo.GetQueue(appInfo),
+		constants.LabelManagedBy: constants.ManagedByPerfTools,
+	}
+	if requestInfo.Group != "" {
+		generatedTemplateSpec.Labels[constants.LabelRequestGroup] = requestInfo.Group
+	}
+	generatedTemplateSpec.Spec.SchedulerName = schedulerName
+	layers := []interface{}{
+		appInfo.PodTemplateSpec,
+		apiv1.PodTemplateSpec{Spec: appInfo.PodSpec},
+		requestOverrides,
+		generatedTemplateSpec,
+	}
+	if err := DeepMerge(&podTemplateSpec, layers...); err != nil {
+		return podTemplateSpec, fmt.Errorf("failed to merge pod template for app %s: %s", appInfo.AppID, err.Error())
+	}
+
+	// apply image, command and resources of the request to the first container
+	if len(podTemplateSpec.Spec.Containers) == 0 {
+		podTemplateSpec.Spec.Containers = []apiv1.Container{{
+			Name:  constants.DefaultContainerName,
+			Image: constants.DefaultContainerImage,
+		}}
+	}
+	container := &podTemplateSpec.Spec.Containers[0]
+	if requestInfo.Image != "" {
+		container.Image = requestInfo.Image
+	}
+	if len(requestInfo.Command) > 0 {
+		container.Command = requestInfo.Command
+	}
+	if requestInfo.RequestResources != nil {
+		if container.Resources.Requests == nil {
+			container.Resources.Requests = apiv1.ResourceList{}
+		}
+		for resourceName, resourceValue := range requestInfo.RequestResources {
+			container.Resources.Requests[apiv1.ResourceName(resourceName)] = resource.MustParse(resourceValue)
+		}
+	}
+	if requestInfo.LimitResources != nil {
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = apiv1.ResourceList{}
+		}
+		for resourceName, resourceValue := range requestInfo.LimitResources {
+			container.Resources.Limits[apiv1.ResourceName(resourceName)] = resource.MustParse(resourceValue)
+		}
+	}
+	return podTemplateSpec, nil
+}
+
+// DeepMerge merges the JSON representations of all layers in order into the target,
+// maps are merged recursively and other values of a later layer replace previous ones.
+// Fields omitted in JSON, such as unset optional fields, never override previous layers.
+func DeepMerge(target interface{}, layers ...interface{}) error {
+	merged := make(map[string]interface{})
+	for _, layer := range layers {
+		content, err := json.Marshal(layer)
+		if err != nil {
+			return err
+		}
+		var layerMap map[string]interface{}
+		if err = json.Unmarshal(content, &layerMap); err != nil {
+			return err
+		}
+		mergeMaps(merged, layerMap)
+	}
+	content, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, target)
+}
+
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, srcValue := range src {
+		// null means the field is not set in this layer, e.g. lists without omitempty
+		if srcValue == nil {
+			continue
+		}
+		srcMap, srcIsMap := srcValue.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[key] = srcValue
+	}
+}
@@ -0,0 +1,159 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apache/yunikorn-release/perf-tools/constants"
+)
+
+func TestNewPodTemplateSpec(t *testing.T) {
+	podSpec := apiv1.PodSpec{
+		Containers: []apiv1.Container{{Name: "test", Image: "test:1.0"}},
+	}
+	appInfo := NewAppInfo("ns", "app-1", "root.test", []*RequestInfo{
+		NewRequestInfo(1, "high", map[string]string{"cpu": "100m"}, nil),
+		NewRequestInfo(1, "", map[string]string{"memory": "1Gi"}, nil),
+	}, apiv1.PodTemplateSpec{}, podSpec)
+
+	template, err := NewPodTemplateSpec("yunikorn", appInfo, appInfo.RequestInfos[0])
+	assert.NilError(t, err)
+	assert.Equal(t, template.Labels[constants.LabelAppID], "app-1")
+	assert.Equal(t, template.Labels[constants.LabelQueue], "root.test")
+	assert.Equal(t, template.Spec.SchedulerName, "yunikorn")
+	assert.Equal(t, template.Spec.PriorityClassName, "high")
+	assert.Equal(t, template.Spec.Containers[0].Image, "test:1.0")
+	assert.Equal(t, template.Spec.Containers[0].Resources.Requests.Cpu().MilliValue(), int64(100))
+
+	// resources of one request should not leak into others or the configured pod spec
+	template, err = NewPodTemplateSpec("yunikorn", appInfo, appInfo.RequestInfos[1])
+	assert.NilError(t, err)
+	_, ok := template.Spec.Containers[0].Resources.Requests[apiv1.ResourceCPU]
+	assert.Assert(t, !ok)
+	assert.Equal(t, template.Spec.Containers[0].Resources.Requests.Memory().String(), "1Gi")
+	assert.Assert(t, podSpec.Containers[0].Resources.Requests == nil)
+}
+
+func TestNewPodTemplateSpecMerge(t *testing.T) {
+	podTemplateSpec := apiv1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{"team": "perf", constants.LabelQueue: "root.ignored"},
+			Annotations: map[string]string{"note": "template"},
+		},
+		Spec: apiv1.PodSpec{
+			InitContainers: []apiv1.Container{{Name: "init", Image: "init:1.0"}},
+			Volumes:        []apiv1.Volume{{Name: "data"}},
+			NodeSelector:   map[string]string{"disk": "ssd"},
+		},
+	}
+	podSpec := apiv1.PodSpec{
+		Containers: []apiv1.Container{
+			{Name: "main", Image: "main:1.0"},
+			{Name: "sidecar", Image: "sidecar:1.0"},
+		},
+		NodeSelector:      map[string]string{"zone": "a"},
+		PriorityClassName: "low",
+	}
+	requestInfo := NewRequestInfo(1, "high", map[string]string{"cpu": "1"}, nil)
+	requestInfo.RuntimeClassName = "kata"
+	appInfo := NewAppInfo("ns", "app-1", "root.test", []*RequestInfo{requestInfo}, podTemplateSpec, podSpec)
+
+	template, err := NewPodTemplateSpec("yunikorn", appInfo, requestInfo)
+	assert.NilError(t, err)
+	// labels of the template are kept, generated labels take precedence
+	assert.Equal(t, template.Labels["team"], "perf")
+	assert.Equal(t, template.Labels[constants.LabelQueue], "root.test")
+	assert.Equal(t, template.Annotations["note"], "template")
+	// fields of the template which are not set in the pod spec are kept, maps are merged
+	assert.Equal(t, len(template.Spec.InitContainers), 1)
+	assert.Equal(t, len(template.Spec.Volumes), 1)
+	assert.DeepEqual(t, template.Spec.NodeSelector, map[string]string{"disk": "ssd", "zone": "a"})
+	// all containers of the pod spec are kept, resources are only applied to the first one
+	assert.Equal(t, len(template.Spec.Containers), 2)
+	assert.Equal(t, template.Spec.Containers[0].Resources.Requests.Cpu().Value(), int64(1))
+	assert.Assert(t, template.Spec.Containers[1].Resources.Requests == nil)
+	// overrides of the request take precedence over the pod spec
+	assert.Equal(t, template.Spec.PriorityClassName, "high")
+	assert.Equal(t, *template.Spec.RuntimeClassName, "kata")
+	// configured specs are not modified
+	assert.Equal(t, len(podTemplateSpec.Labels), 2)
+	assert.Assert(t, podSpec.Containers[0].Resources.Requests == nil)
+}
+
+func TestNewPodTemplateSpecDefaultContainer(t *testing.T) {
+	appInfo := NewAppInfo("ns", "app-1", "root.test", []*RequestInfo{
+		NewRequestInfo(1, "", map[string]string{"cpu": "100m"}, nil),
+	}, apiv1.PodTemplateSpec{}, apiv1.PodSpec{})
+
+	template, err := NewPodTemplateSpec("yunikorn", appInfo, appInfo.RequestInfos[0])
+	assert.NilError(t, err)
+	assert.Equal(t, len(template.Spec.Containers), 1)
+	assert.Equal(t, template.Spec.Containers[0].Name, constants.DefaultContainerName)
+	assert.Equal(t, template.Spec.Containers[0].Image, constants.DefaultContainerImage)
+	assert.Assert(t, template.Spec.RuntimeClassName == nil)
+}
+
+func TestNewPodTemplateSpecRequestGroup(t *testing.T) {
+	podSpec := apiv1.PodSpec{
+		Containers:   []apiv1.Container{{Name: "main", Image: "main:1.0", Command: []string{"sleep", "10"}}},
+		NodeSelector: map[string]string{"zone": "a"},
+		Tolerations:  []apiv1.Toleration{{Key: "common", Operator: apiv1.TolerationOpExists}},
+	}
+	driverInfo := NewRequestInfo(1, "", map[string]string{"cpu": "1"}, nil)
+	driverInfo.Group = "driver"
+	driverInfo.Image = "driver:1.0"
+	driverInfo.Command = []string{"run-driver"}
+	driverInfo.Labels = map[string]string{"role": "driver", constants.LabelAppID: "ignored"}
+	driverInfo.Annotations = map[string]string{"note": "driver"}
+	driverInfo.Queue = "root.drivers"
+	driverInfo.NodeSelector = map[string]string{"disk": "ssd"}
+	driverInfo.Tolerations = []apiv1.Toleration{{Key: "driver", Operator: apiv1.TolerationOpExists}}
+	executorInfo := NewRequestInfo(2, "", map[string]string{"cpu": "100m"}, nil)
+	appInfo := NewAppInfo("ns", "app-1", "root.test", []*RequestInfo{driverInfo, executorInfo},
+		apiv1.PodTemplateSpec{}, podSpec)
+
+	template, err := NewPodTemplateSpec("yunikorn", appInfo, driverInfo)
+	assert.NilError(t, err)
+	assert.Equal(t, template.Labels["role"], "driver")
+	assert.Equal(t, template.Labels[constants.LabelAppID], "app-1")
+	assert.Equal(t, template.Labels[constants.LabelQueue], "root.drivers")
+	assert.Equal(t, template.Labels[constants.LabelRequestGroup], "driver")
+	assert.Equal(t, template.Annotations["note"], "driver")
+	assert.Equal(t, template.Spec.Containers[0].Image, "driver:1.0")
+	assert.DeepEqual(t, template.Spec.Containers[0].Command, []string{"run-driver"})
+	assert.DeepEqual(t, template.Spec.NodeSelector, map[string]string{"zone": "a", "disk": "ssd"})
+	assert.Equal(t, len(template.Spec.Tolerations), 1)
+	assert.Equal(t, template.Spec.Tolerations[0].Key, "driver")
+
+	// settings of the app are used for the request group without overrides
+	template, err = NewPodTemplateSpec("yunikorn", appInfo, executorInfo)
+	assert.NilError(t, err)
+	assert.Equal(t, template.Labels[constants.LabelQueue], "root.test")
+	_, ok := template.Labels[constants.LabelRequestGroup]
+	assert.Assert(t, !ok)
+	assert.Equal(t, template.Spec.Containers[0].Image, "main:1.0")
+	assert.DeepEqual(t, template.Spec.Containers[0].Command, []string{"sleep", "10"})
+	assert.Equal(t, template.Spec.Tolerations[0].Key, "common")
+	assert.Equal(t, podSpec.Containers[0].Image, "main:1.0")
+}
@@ -45,6 +45,22 @@ func GetRegisteredTestScenarios() map[string]TestScenario {
 
 type TestScenario interface {
 	GetName() string
+	// NewScenarioConfig returns an empty config of this scenario, which is used to validate the config file
+	NewScenarioConfig() interface{}
 	Init(kubeClient *utils.KubeClient, config *Config) error
 	Run(results *utils.Results)
+	// Plan returns the apps every case would create without creating them, which is used by the dry-run mode
+	Plan() ([]*CasePlan, error)
+}
+
+// DisruptiveScenario is implemented by scenarios which disrupt the cluster (e.g. restart the scheduler),
+// they only run when specified by name
+type DisruptiveScenario interface {
+	IsDisruptive() bool
+}
+
+// IsDisruptive returns true if the scenario only runs when specified by name
+func IsDisruptive(ts TestScenario) bool {
+	disruptive, ok := ts.(DisruptiveScenario)
+	return ok && disruptive.IsDisruptive()
 }
@@ -0,0 +1,282 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apache/yunikorn-core/pkg/webservice/dao"
+
+	"github.com/apache/yunikorn-release/perf-tools/utils"
+)
+
+const (
+	QueuesConfigKey = "queues.yaml"
+
+	DefaultYuniKornNamespace            = "yunikorn"
+	DefaultYuniKornConfigMapName        = "yunikorn-defaults"
+	DefaultYuniKornServiceName          = "yunikorn-service"
+	DefaultYuniKornServicePort          = "9080"
+	DefaultYuniKornReloadTimeoutSeconds = 60
+
+	yuniKornConfigPath      = "/ws/v1/config"
+	yuniKornHealthCheckPath = "/ws/v1/scheduler/healthcheck"
+)
+
+// YuniKornConfig describes where the config of YuniKorn is stored and served
+type YuniKornConfig struct {
+	Namespace            string
+	ConfigMapName        string
+	ServiceName          string
+	ServicePort          string
+	ReloadTimeoutSeconds int `validate:"nonnegative"`
+}
+
+// SchedulerConfigManager patches the ConfigMap of YuniKorn and waits for the scheduler to confirm the reload
+// through its REST config endpoint
+type SchedulerConfigManager struct {
+	kubeClient *utils.KubeClient
+	conf       YuniKornConfig
+}
+
+func NewSchedulerConfigManager(kubeClient *utils.KubeClient, conf *YuniKornConfig) *SchedulerConfigManager {
+	scm := &SchedulerConfigManager{
+		kubeClient: kubeClient,
+		conf: YuniKornConfig{
+			Namespace:            DefaultYuniKornNamespace,
+			ConfigMapName:        DefaultYuniKornConfigMapName,
+			ServiceName:          DefaultYuniKornServiceName,
+			ServicePort:          DefaultYuniKornServicePort,
+			ReloadTimeoutSeconds: DefaultYuniKornReloadTimeoutSeconds,
+		},
+	}
+	if conf != nil {
+		if conf.Namespace != "" {
+			scm.conf.Namespace = conf.Namespace
+		}
+		if conf.ConfigMapName != "" {
+			scm.conf.ConfigMapName = conf.ConfigMapName
+		}
+		if conf.ServiceName != "" {
+			scm.conf.ServiceName = conf.ServiceName
+		}
+		if conf.ServicePort != "" {
+			scm.conf.ServicePort = conf.ServicePort
+		}
+		if conf.ReloadTimeoutSeconds > 0 {
+			scm.conf.ReloadTimeoutSeconds = conf.ReloadTimeoutSeconds
+		}
+	}
+	return scm
+}
+
+// Apply sets the entries of the patch in the ConfigMap and waits for the scheduler to reload them,
+// the original entries are returned for restoring, in which nil values mean the entries didn't exist.
+// The original entries are also returned if the ConfigMap is updated but the reload can't be confirmed.
+func (scm *SchedulerConfigManager) Apply(patch map[string]string) (map[string]*string, error) {
+	newData := make(map[string]*string, len(patch))
+	for key, value := range patch {
+		newValue := value
+		newData[key] = &newValue
+	}
+	original, err := scm.update(newData)
+	if err != nil {
+		return nil, err
+	}
+	utils.Logger.Info("applied scheduler config, wait for it to be reloaded", zap.Any("keys", getKeys(patch)))
+	if err = scm.waitForReload(newData); err != nil {
+		return original, err
+	}
+	return original, nil
+}
+
+// Restore sets the original entries back to the ConfigMap and waits for the scheduler to reload them
+func (scm *SchedulerConfigManager) Restore(original map[string]*string) error {
+	if _, err := scm.update(original); err != nil {
+		return err
+	}
+	utils.Logger.Info("restored scheduler config, wait for it to be reloaded")
+	return scm.waitForReload(original)
+}
+
+// update sets or removes (for nil values) entries of the ConfigMap and returns the previous entries
+func (scm *SchedulerConfigManager) update(data map[string]*string) (map[string]*string, error) {
+	configMap, err := scm.kubeClient.GetConfigMap(scm.conf.Namespace, scm.conf.ConfigMapName, &metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config map %s/%s: %s", scm.conf.Namespace,
+			scm.conf.ConfigMapName, err.Error())
+	}
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	previous := make(map[string]*string, len(data))
+	for key, value := range data {
+		if previousValue, ok := configMap.Data[key]; ok {
+			previous[key] = &previousValue
+		} else {
+			previous[key] = nil
+		}
+		if value != nil {
+			configMap.Data[key] = *value
+		} else {
+			delete(configMap.Data, key)
+		}
+	}
+	if _, err = scm.kubeClient.UpdateConfigMap(scm.conf.Namespace, configMap); err != nil {
+		return nil, fmt.Errorf("failed to update config map %s/%s: %s", scm.conf.Namespace,
+			scm.conf.ConfigMapName, err.Error())
+	}
+	return previous, nil
+}
+
+func (scm *SchedulerConfigManager) waitForReload(expected map[string]*string) error {
+	var lastErr error
+	err := WaitForCondition(func() bool {
+		content, err := scm.kubeClient.GetServiceProxy(scm.conf.Namespace, scm.conf.ServiceName,
+			scm.conf.ServicePort, yuniKornConfigPath)
+		if err != nil {
+			lastErr = err
+			return false
+		}
+		var reloaded bool
+		reloaded, lastErr = IsSchedulerConfigReloaded(content, expected)
+		return reloaded
+	}, time.Second, time.Duration(scm.conf.ReloadTimeoutSeconds)*time.Second)
+	if err != nil {
+		if lastErr != nil {
+			return fmt.Errorf("failed to wait for scheduler config to be reloaded: %s", lastErr.Error())
+		}
+		return fmt.Errorf("failed to wait for scheduler config to be reloaded: %s", err.Error())
+	}
+	return nil
+}
+
+// CheckHealth returns whether the scheduler serves its REST API through the service and reports itself healthy,
+// the names of failed health checks are returned if it is not healthy
+func (scm *SchedulerConfigManager) CheckHealth() (bool, []string, error) {
+	content, err := scm.kubeClient.GetServiceProxy(scm.conf.Namespace, scm.conf.ServiceName, scm.conf.ServicePort,
+		yuniKornHealthCheckPath)
+	if err != nil {
+		return false, nil, err
+	}
+	return ParseSchedulerHealth(content)
+}
+
+// ParseSchedulerHealth parses the health served by the REST endpoint of YuniKorn, and returns whether it is healthy
+// with names of failed health checks
+func ParseSchedulerHealth(content []byte) (bool, []string, error) {
+	var health dao.SchedulerHealthDAOInfo
+	if err := json.Unmarshal(content, &health); err != nil {
+		return false, nil, fmt.Errorf("failed to parse scheduler health: %s", err.Error())
+	}
+	var failedChecks []string
+	for _, healthCheck := range health.HealthChecks {
+		if !healthCheck.Succeeded {
+			failedChecks = append(failedChecks, healthCheck.Name)
+		}
+	}
+	return health.Healthy, failedChecks, nil
+}
+
+// IsSchedulerConfigReloaded checks whether the config served by the REST endpoint of YuniKorn (in YAML or JSON)
+// reflects the expected entries of the ConfigMap: queues.yaml is checked by its checksum, other entries are
+// checked in the extra config, entries with nil values are expected to be absent. The content of queues.yaml
+// can't be checked if it is removed since the scheduler falls back to its default.
+func IsSchedulerConfigReloaded(content []byte, expected map[string]*string) (bool, error) {
+	var served map[string]interface{}
+	if err := yaml.Unmarshal(content, &served); err != nil {
+		return false, fmt.Errorf("failed to parse scheduler config: %s", err.Error())
+	}
+	var checksum string
+	if value, ok := getIgnoreCase(served, "checksum").(string); ok {
+		checksum = value
+	}
+	// entries other than queues.yaml are served as Extra of dao.ConfigDAOInfo
+	extraConfig := make(map[string]interface{})
+	if value, ok := getIgnoreCase(served, "extra").(map[string]interface{}); ok {
+		extraConfig = value
+	}
+	for key, value := range expected {
+		if key == QueuesConfigKey {
+			if value != nil && !strings.EqualFold(checksum, fmt.Sprintf("%X", sha256.Sum256([]byte(*value)))) {
+				return false, nil
+			}
+			continue
+		}
+		servedValue, ok := extraConfig[key]
+		if value == nil && ok || value != nil && (!ok || fmt.Sprint(servedValue) != *value) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func getIgnoreCase(m map[string]interface{}, key string) interface{} {
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
+
+func getKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// FindSchedulerConfigOverrides returns paths of cases of the scenarios which override the scheduler config
+// (schedulerConfig or schedulerConfigs), matrices should have been expanded.
+func FindSchedulerConfigOverrides(yamlContent []byte, scenarioNames []string) ([]string, error) {
+	document, err := parseConfigDocument(yamlContent, "effective config")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	scenariosNode := getValueNode(document.Content[0], scenariosKey)
+	for _, scenarioName := range scenarioNames {
+		casesNode := getValueNode(getValueNode(scenariosNode, scenarioName), casesKey)
+		if casesNode == nil || casesNode.Kind != yaml.SequenceNode {
+			continue
+		}
+		for caseIndex, caseNode := range casesNode.Content {
+			if caseNode.Kind != yaml.MappingNode {
+				continue
+			}
+			for _, key := range []string{"schedulerConfig", "schedulerConfigs"} {
+				if index := getKeyIndex(caseNode, key); index >= 0 && !isEmptyNode(caseNode.Content[index+1]) {
+					paths = append(paths, fmt.Sprintf("%s.%s.%s[%d].%s", scenariosKey, scenarioName, casesKey,
+						caseIndex, caseNode.Content[index].Value))
+				}
+			}
+		}
+	}
+	return paths, nil
+}
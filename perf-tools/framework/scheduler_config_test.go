@@ -0,0 +1,147 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-core/pkg/common/configs"
+	"github.com/apache/yunikorn-core/pkg/webservice/dao"
+)
+
+func TestIsSchedulerConfigReloaded(t *testing.T) {
+	queues := "partitions:\n  - name: default\n    queues:\n      - name: root\n"
+	checksum := fmt.Sprintf("%X", sha256.Sum256([]byte(queues)))
+	interval := "2s"
+	expected := map[string]*string{QueuesConfigKey: &queues, "service.schedulingInterval": &interval}
+	// configs served by the scheduler, in YAML and JSON
+	getServedConfigs := func(checksum string, extra map[string]string) [][]byte {
+		configDAOInfo := &dao.ConfigDAOInfo{
+			SchedulerConfig: &configs.SchedulerConfig{
+				Partitions: []configs.PartitionConfig{{Name: "default", Queues: []configs.QueueConfig{{Name: "root"}}}},
+				Checksum:   checksum,
+			},
+			Extra: extra,
+		}
+		yamlContent, err := yaml.Marshal(configDAOInfo)
+		assert.NilError(t, err)
+		jsonContent, err := json.Marshal(configDAOInfo)
+		assert.NilError(t, err)
+		return [][]byte{yamlContent, jsonContent}
+	}
+
+	for _, content := range getServedConfigs(checksum, map[string]string{"service.schedulingInterval": "2s"}) {
+		reloaded, err := IsSchedulerConfigReloaded(content, expected)
+		assert.NilError(t, err, string(content))
+		assert.Assert(t, reloaded, string(content))
+	}
+
+	// queues.yaml is not reloaded yet
+	for _, content := range getServedConfigs("ABC", map[string]string{"service.schedulingInterval": "2s"}) {
+		reloaded, err := IsSchedulerConfigReloaded(content, expected)
+		assert.NilError(t, err)
+		assert.Assert(t, !reloaded, string(content))
+	}
+
+	// extra config is not reloaded yet
+	for _, content := range getServedConfigs(checksum, map[string]string{"service.schedulingInterval": "1s"}) {
+		reloaded, err := IsSchedulerConfigReloaded(content, expected)
+		assert.NilError(t, err)
+		assert.Assert(t, !reloaded, string(content))
+	}
+
+	// removed entries are expected to be absent
+	expected = map[string]*string{"service.schedulingInterval": nil}
+	for _, content := range getServedConfigs(checksum, map[string]string{"service.schedulingInterval": "1s"}) {
+		reloaded, err := IsSchedulerConfigReloaded(content, expected)
+		assert.NilError(t, err)
+		assert.Assert(t, !reloaded, string(content))
+	}
+	for _, content := range getServedConfigs(checksum, nil) {
+		reloaded, err := IsSchedulerConfigReloaded(content, expected)
+		assert.NilError(t, err)
+		assert.Assert(t, reloaded, string(content))
+	}
+
+	_, err := IsSchedulerConfigReloaded([]byte("{"), expected)
+	assert.ErrorContains(t, err, "failed to parse scheduler config")
+}
+
+func TestFindSchedulerConfigOverrides(t *testing.T) {
+	content := `
+scenarios:
+  throughput:
+    cases:
+      - description: a
+      - schedulerConfig:
+          service.schedulingInterval: 2s
+      - schedulerConfig: {}
+  bin_packing:
+    cases:
+      - schedulerConfigs:
+          - name: fair
+            config:
+              queues.yaml: x
+  churn:
+    cases:
+      - schedulerConfig:
+          service.schedulingInterval: 2s
+`
+	paths, err := FindSchedulerConfigOverrides([]byte(content), []string{"throughput", "bin_packing", "node_fairness"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, paths, []string{"scenarios.throughput.cases[1].schedulerConfig",
+		"scenarios.bin_packing.cases[0].schedulerConfigs"})
+	paths, err = FindSchedulerConfigOverrides([]byte(content), []string{"node_fairness"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(paths), 0)
+}
+
+func TestParseSchedulerHealth(t *testing.T) {
+	content, err := json.Marshal(dao.SchedulerHealthDAOInfo{
+		Healthy:      true,
+		HealthChecks: []dao.HealthCheckInfo{{Name: "Scheduling errors", Succeeded: true}},
+	})
+	assert.NilError(t, err)
+	healthy, failedChecks, err := ParseSchedulerHealth(content)
+	assert.NilError(t, err)
+	assert.Assert(t, healthy)
+	assert.Equal(t, len(failedChecks), 0)
+
+	content, err = json.Marshal(dao.SchedulerHealthDAOInfo{
+		Healthy: false,
+		HealthChecks: []dao.HealthCheckInfo{
+			{Name: "Scheduling errors", Succeeded: true},
+			{Name: "Consistency of data", Succeeded: false},
+		},
+	})
+	assert.NilError(t, err)
+	healthy, failedChecks, err = ParseSchedulerHealth(content)
+	assert.NilError(t, err)
+	assert.Assert(t, !healthy)
+	assert.DeepEqual(t, failedChecks, []string{"Consistency of data"})
+
+	_, _, err = ParseSchedulerHealth([]byte("not json"))
+	assert.ErrorContains(t, err, "failed to parse scheduler health")
+}
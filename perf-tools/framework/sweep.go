@@ -0,0 +1,185 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sweep is a case of which the matrix is expanded into cases of the cartesian product of values of parameters
+type Sweep struct {
+	ScenarioName string
+	Description  string
+	// paths of swept parameters relative to the case in the order of the matrix,
+	// the first one is on the X axis of sweep charts
+	Parameters []string
+	Cases      []*SweepCase
+}
+
+// SweepCase is a case expanded from the matrix of a sweep
+type SweepCase struct {
+	// index of the case in expanded cases of the scenario, which is the index of its verification group
+	CaseIndex int
+	// values of parameters in the order of parameters
+	Values []string
+}
+
+// ExpandMatrices expands every case with a matrix of parameters into cases of the cartesian product of values
+// of the parameters, in which the first parameter changes slowest. Parameters are paths of fields relative to
+// the case, e.g. requestConfigs[0].numPods, and descriptions of expanded cases are suffixed with values of the
+// parameters. The content is returned as it is if there is no matrix.
+func ExpandMatrices(yamlContent []byte) ([]byte, []*Sweep, error) {
+	document, err := parseConfigDocument(yamlContent, "effective config")
+	if err != nil {
+		return nil, nil, err
+	}
+	scenariosNode := getValueNode(document.Content[0], scenariosKey)
+	if scenariosNode == nil || scenariosNode.Kind != yaml.MappingNode {
+		return yamlContent, nil, nil
+	}
+	var sweeps []*Sweep
+	for i := 0; i+1 < len(scenariosNode.Content); i += 2 {
+		scenarioName := scenariosNode.Content[i].Value
+		casesNode := getValueNode(scenariosNode.Content[i+1], casesKey)
+		if casesNode == nil || casesNode.Kind != yaml.SequenceNode {
+			continue
+		}
+		expandedCases := make([]*yaml.Node, 0, len(casesNode.Content))
+		for _, caseNode := range casesNode.Content {
+			matrixIndex := -1
+			if caseNode.Kind == yaml.MappingNode {
+				matrixIndex = getKeyIndex(caseNode, matrixKey)
+			}
+			if matrixIndex < 0 {
+				expandedCases = append(expandedCases, caseNode)
+				continue
+			}
+			sweep, sweepCases, expandErr := expandMatrix(caseNode, matrixIndex, len(expandedCases))
+			if expandErr != nil {
+				return nil, nil, fmt.Errorf("failed to expand the matrix of scenario %s at line %d: %s",
+					scenarioName, caseNode.Line, expandErr.Error())
+			}
+			sweep.ScenarioName = scenarioName
+			sweeps = append(sweeps, sweep)
+			expandedCases = append(expandedCases, sweepCases...)
+		}
+		casesNode.Content = expandedCases
+	}
+	if len(sweeps) == 0 {
+		return yamlContent, nil, nil
+	}
+	content, err := encodeConfigDocument(document)
+	return content, sweeps, err
+}
+
+func expandMatrix(caseNode *yaml.Node, matrixIndex, firstCaseIndex int) (*Sweep, []*yaml.Node, error) {
+	matrixNode := caseNode.Content[matrixIndex+1]
+	if matrixNode.Kind != yaml.MappingNode || len(matrixNode.Content) == 0 {
+		return nil, nil, fmt.Errorf("expected a mapping of parameters to lists of values")
+	}
+	template := copyNode(caseNode)
+	template.Content = append(template.Content[:matrixIndex], template.Content[matrixIndex+2:]...)
+	sweep := &Sweep{}
+	var paths [][]string
+	var valueLists [][]*yaml.Node
+	for i := 0; i+1 < len(matrixNode.Content); i += 2 {
+		parameter := matrixNode.Content[i].Value
+		path, err := parseOverridePath(parameter)
+		if err != nil {
+			return nil, nil, err
+		}
+		valuesNode := matrixNode.Content[i+1]
+		if valuesNode.Kind != yaml.SequenceNode || len(valuesNode.Content) == 0 {
+			return nil, nil, fmt.Errorf("expected a non-empty list of values of %s", parameter)
+		}
+		sweep.Parameters = append(sweep.Parameters, parameter)
+		paths = append(paths, path)
+		valueLists = append(valueLists, valuesNode.Content)
+	}
+	descriptionNode := getScalarNode(template, "description")
+	if descriptionNode != nil {
+		sweep.Description = descriptionNode.Value
+	}
+	var cases []*yaml.Node
+	indexes := make([]int, len(paths))
+	for {
+		expandedCase := copyNode(template)
+		values := make([]string, len(paths))
+		for i, path := range paths {
+			valueNode := copyNode(valueLists[i][indexes[i]])
+			if err := setNode(expandedCase, path, valueNode); err != nil {
+				return nil, nil, fmt.Errorf("%s: %s", sweep.Parameters[i], err.Error())
+			}
+			values[i] = formatNodeValue(valueNode)
+		}
+		if descriptionNode = getScalarNode(expandedCase, "description"); descriptionNode != nil {
+			descriptionNode.Value = strings.TrimSpace(descriptionNode.Value + " " +
+				FormatSweepValues(sweep.Parameters, values))
+			descriptionNode.Tag = "!!str"
+		}
+		sweep.Cases = append(sweep.Cases, &SweepCase{CaseIndex: firstCaseIndex + len(cases), Values: values})
+		cases = append(cases, expandedCase)
+		// the last parameter changes fastest
+		i := len(indexes) - 1
+		for ; i >= 0; i-- {
+			indexes[i]++
+			if indexes[i] < len(valueLists[i]) {
+				break
+			}
+			indexes[i] = 0
+		}
+		if i < 0 {
+			return sweep, cases, nil
+		}
+	}
+}
+
+// FormatSweepValues formats values of parameters, e.g. (requestConfigs[0].numPods=1000, schedulerName=yunikorn)
+func FormatSweepValues(parameters, values []string) string {
+	pairs := make([]string, len(parameters))
+	for i, parameter := range parameters {
+		pairs[i] = parameter + "=" + values[i]
+	}
+	return "(" + strings.Join(pairs, ", ") + ")"
+}
+
+// getScalarNode returns the scalar value of the key matched case-insensitively in the mapping, or nil
+func getScalarNode(node *yaml.Node, key string) *yaml.Node {
+	if index := getKeyIndex(node, key); index >= 0 && node.Content[index+1].Kind == yaml.ScalarNode {
+		return node.Content[index+1]
+	}
+	return nil
+}
+
+// formatNodeValue returns the value of a scalar, or the flow style YAML of other nodes
+func formatNodeValue(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	flowNode := copyNode(node)
+	flowNode.Style = yaml.FlowStyle
+	content, err := yaml.Marshal(flowNode)
+	if err != nil {
+		return node.Value
+	}
+	return strings.TrimSpace(string(content))
+}
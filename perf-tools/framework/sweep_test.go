@@ -0,0 +1,107 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestExpandMatrices(t *testing.T) {
+	// the content is returned as it is without matrices
+	content := "scenarios:\n  test:\n    cases:\n      - description: a   # comment\n"
+	expanded, sweeps, err := ExpandMatrices([]byte(content))
+	assert.NilError(t, err)
+	assert.Equal(t, string(expanded), content)
+	assert.Equal(t, len(sweeps), 0)
+
+	expanded, sweeps, err = ExpandMatrices([]byte(`
+scenarios:
+  test:
+    cases:
+      - description: first
+      - description: sweep
+        matrix:
+          requests[0].numPods: [10, 20]
+          schedulerName: [yunikorn, default-scheduler]
+        requests:
+          - numPods: 1
+      - description: last
+  other:
+    cases:
+      - matrix:
+          requests: [[{numPods: 1}]]
+`))
+	assert.NilError(t, err)
+	assert.Equal(t, string(expanded), `scenarios:
+  test:
+    cases:
+      - description: first
+      - description: sweep (requests[0].numPods=10, schedulerName=yunikorn)
+        requests:
+          - numPods: 10
+        schedulerName: yunikorn
+      - description: sweep (requests[0].numPods=10, schedulerName=default-scheduler)
+        requests:
+          - numPods: 10
+        schedulerName: default-scheduler
+      - description: sweep (requests[0].numPods=20, schedulerName=yunikorn)
+        requests:
+          - numPods: 20
+        schedulerName: yunikorn
+      - description: sweep (requests[0].numPods=20, schedulerName=default-scheduler)
+        requests:
+          - numPods: 20
+        schedulerName: default-scheduler
+      - description: last
+  other:
+    cases:
+      - requests: [{numPods: 1}]
+`)
+	assert.DeepEqual(t, sweeps, []*Sweep{
+		{
+			ScenarioName: "test",
+			Description:  "sweep",
+			Parameters:   []string{"requests[0].numPods", "schedulerName"},
+			Cases: []*SweepCase{
+				{CaseIndex: 1, Values: []string{"10", "yunikorn"}},
+				{CaseIndex: 2, Values: []string{"10", "default-scheduler"}},
+				{CaseIndex: 3, Values: []string{"20", "yunikorn"}},
+				{CaseIndex: 4, Values: []string{"20", "default-scheduler"}},
+			},
+		},
+		{
+			ScenarioName: "other",
+			Parameters:   []string{"requests"},
+			Cases:        []*SweepCase{{CaseIndex: 0, Values: []string{"[{numPods: 1}]"}}},
+		},
+	})
+
+	_, _, err = ExpandMatrices([]byte(`
+scenarios:
+  test:
+    cases:
+      - matrix:
+          requests[1].numPods: [1]
+        requests:
+          - numPods: 1
+`))
+	assert.ErrorContains(t, err, "1 is not an index of the list at requests")
+}
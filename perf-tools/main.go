@@ -19,74 +19,260 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/apache/yunikorn-release/perf-tools/utils"
-
 	"go.uber.org/zap"
 
 	"github.com/apache/yunikorn-release/perf-tools/framework"
-	_ "github.com/apache/yunikorn-release/perf-tools/scenarios"
+	"github.com/apache/yunikorn-release/perf-tools/scenarios"
+	"github.com/apache/yunikorn-release/perf-tools/utils"
 )
 
 const (
 	DateTimeLayout      = "20060102150405"
 	ConfigFileName      = "conf.yaml"
+	EventsFileName      = "events.jsonl"
 	OutputDirNamePrefix = "YK-PERF"
 	DefaultLoggingLevel = 0
+
+	// commands specified by the first argument, tests are run if no command is specified
+	CommandRun           = "run"
+	CommandListScenarios = "list-scenarios"
+	CommandValidate      = "validate"
+	CommandReport        = "report"
+	CommandCompare       = "compare"
+	CommandCleanup       = "cleanup"
+
+	exitCodeFailed = 1
+	exitCodeUsage  = 2
 )
 
-type CommandLineConfig struct {
-	ConfigFilePath string
-	ScenarioNames  string
-	LogLevel       int
+// Command is a subcommand of this tool, its flags are parsed from the arguments following the command name
+type Command struct {
+	Name        string
+	Arguments   string
+	Description string
+	// Run runs the command with arguments following the command name and returns the exit code
+	Run func(args []string) int
 }
 
-var commandLineConfig *CommandLineConfig
-
-func init() {
-	// test options
-	configFile := flag.String("config", "",
-		"absolute path to the config file for performance tests")
-	scenarioNames := flag.String("scenarios", "",
-		"The comma separated names of scenarios which are expected to run")
-	logLevel := flag.Int("logLevel", DefaultLoggingLevel,
-		"logging level, available range [-1, 5], from DEBUG to FATAL.")
-	flag.Parse()
-	commandLineConfig = &CommandLineConfig{
-		ConfigFilePath: *configFile,
-		ScenarioNames:  *scenarioNames,
-		LogLevel:       *logLevel,
+func getCommands() []*Command {
+	return []*Command{
+		{Name: CommandRun, Arguments: "[flags]", Run: runTests,
+			Description: "run test scenarios, which is the default command"},
+		{Name: CommandListScenarios, Arguments: "[flags]", Run: listScenarios,
+			Description: "list registered scenarios with the schema of their configs"},
+		{Name: CommandValidate, Arguments: "[flags]", Run: validate,
+			Description: "validate the config file without touching the cluster"},
+		{Name: CommandReport, Arguments: "[flags] <output-dir>", Run: report,
+			Description: "regenerate reports from the results saved in the output directory of a run"},
+		{Name: CommandCompare, Arguments: "[flags] <baseline-output-dir> <target-output-dir>", Run: compare,
+			Description: "compare metrics saved in the output directories of two runs"},
+		{Name: CommandCleanup, Arguments: "[flags]", Run: cleanup,
+			Description: "delete deployments and pods left behind by previous runs"},
 	}
 }
 
 func main() {
-	utils.SetLogLevel(commandLineConfig.LogLevel)
-	configFilePath := commandLineConfig.ConfigFilePath
-	if configFilePath == "" {
-		err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			} else if info.Name() == ConfigFileName {
-				configFilePath = path
-				return io.EOF
+	os.Exit(runCommand(os.Args[1:]))
+}
+
+// runCommand runs the command specified by the first argument and returns the exit code,
+// tests are run if the first argument is a flag or there is no argument.
+func runCommand(args []string) int {
+	commandName := CommandRun
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		commandName = args[0]
+		args = args[1:]
+	}
+	if commandName == "help" {
+		printUsage()
+		return 0
+	}
+	for _, command := range getCommands() {
+		if command.Name == commandName {
+			return command.Run(args)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n", commandName)
+	printUsage()
+	return exitCodeUsage
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags] [arguments]\n\nCommands:\n", filepath.Base(os.Args[0]))
+	for _, command := range getCommands() {
+		fmt.Fprintf(os.Stderr, "  %-16s%s\n", command.Name, command.Description)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun \"%s <command> -h\" for flags of a command.\n", filepath.Base(os.Args[0]))
+}
+
+// newFlagSet returns the flag set of a command, the usage of which shows arguments of the command
+func newFlagSet(command string) *flag.FlagSet {
+	flagSet := flag.NewFlagSet(command, flag.ContinueOnError)
+	flagSet.Usage = func() {
+		for _, c := range getCommands() {
+			if c.Name == command {
+				fmt.Fprintf(flagSet.Output(), "Usage: %s %s %s\n  %s\n", filepath.Base(os.Args[0]), c.Name,
+					c.Arguments, c.Description)
 			}
-			return nil
-		})
-		if err != io.EOF {
-			utils.Logger.Fatal("failed to find config file", zap.Error(err))
 		}
-		if configFilePath == "" {
-			utils.Logger.Fatal("can't find required config file for integration testing")
+		flagSet.PrintDefaults()
+	}
+	return flagSet
+}
+
+// stringsFlag is a flag which can be repeated, values of all occurrences are kept in order
+type stringsFlag []string
+
+func (sf *stringsFlag) String() string {
+	return strings.Join(*sf, ",")
+}
+
+func (sf *stringsFlag) Set(value string) error {
+	*sf = append(*sf, value)
+	return nil
+}
+
+// addConfigFlags adds flags of config layers, the returned function builds the layers after flags are parsed,
+// environment variables with the prefix PERF_ are applied as well.
+func addConfigFlags(flagSet *flag.FlagSet) func() *framework.ConfigLayers {
+	configFilePath := flagSet.String("config", ConfigFileName, "path to the base config file for performance tests")
+	var overlayFiles, overrides stringsFlag
+	flagSet.Var(&overlayFiles, "overlay",
+		"path to an overlay config file merged into the base config, can be repeated")
+	flagSet.Var(&overrides, "set",
+		"override of a config field in the form of path=value, e.g. common.maxWaitSeconds=900, can be repeated")
+	return func() *framework.ConfigLayers {
+		return &framework.ConfigLayers{
+			BaseFile:     *configFilePath,
+			OverlayFiles: overlayFiles,
+			Env:          os.Environ(),
+			Overrides:    overrides,
 		}
 	}
-	conf, err := framework.InitConfig(configFilePath)
+}
+
+func addLogLevelFlag(flagSet *flag.FlagSet) *int {
+	return flagSet.Int("logLevel", DefaultLoggingLevel,
+		"logging level, available range [-1, 5], from DEBUG to FATAL.")
+}
+
+func addScenariosFlag(flagSet *flag.FlagSet) *string {
+	return flagSet.String("scenarios", "",
+		"The comma separated names of scenarios which are expected to run, all registered scenarios except "+
+			"disruptive ones (e.g. recovery) if not specified")
+}
+
+// parseFlags parses flags of a command and returns false with the exit code if the command shouldn't go on,
+// which is 0 if help is requested
+func parseFlags(flagSet *flag.FlagSet, args []string, numArgs int) (int, bool) {
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0, false
+		}
+		return exitCodeUsage, false
+	}
+	if flagSet.NArg() != numArgs {
+		fmt.Fprintf(flagSet.Output(), "expected %d arguments but got %d\n", numArgs, flagSet.NArg())
+		flagSet.Usage()
+		return exitCodeUsage, false
+	}
+	return 0, true
+}
+
+// runTests runs expected test scenarios and generates reports into a new output directory,
+// or only renders what would be created if dry-run is enabled
+func runTests(args []string) int {
+	flagSet := newFlagSet(CommandRun)
+	getConfigLayers := addConfigFlags(flagSet)
+	scenarioNames := addScenariosFlag(flagSet)
+	logLevel := addLogLevelFlag(flagSet)
+	parallel := flagSet.Bool("parallel", false,
+		"run scenarios in parallel, scenarios should be isolated from each other via common.isolation, "+
+			"disruptive scenarios and cases overriding the scheduler config are refused")
+	dryRun := flagSet.Bool("dry-run", false,
+		"render manifests and estimate the load of every case into the output directory without running tests")
+	if exitCode, ok := parseFlags(flagSet, args, 0); !ok {
+		return exitCode
+	}
+	utils.SetLogLevel(*logLevel)
+	expectedTestScenarios, err := getExpectedTestScenarios(*scenarioNames, false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitCodeUsage
+	}
+	// validate the config before anything touches the cluster
+	configLayers := getConfigLayers()
+	configContent, valid := loadConfig(configLayers, expectedTestScenarios)
+	if !valid {
+		utils.Logger.Error("invalid config", zap.String("configFile", configLayers.BaseFile))
+		return exitCodeFailed
+	}
+	if *parallel && !*dryRun {
+		if err = checkParallel(configContent, expectedTestScenarios); err != nil {
+			fmt.Fprintf(os.Stderr, "can't run scenarios in parallel: %s\n", err.Error())
+			return exitCodeUsage
+		}
+	}
+	conf, kubeClient, sweeps := initRun(configContent, *scenarioNames, expectedTestScenarios)
+	if *dryRun {
+		dryRunScenarios(kubeClient, conf, expectedTestScenarios)
+		return 0
+	}
+	// run expected test scenarios
+	runMetadata := &utils.RunMetadata{
+		StartTime:  time.Now(),
+		ConfigFile: configLayers.BaseFile,
+		OutputPath: conf.Common.OutputPath,
+	}
+	results := utils.NewResults()
+	addObservers(results, conf.Common)
+	for _, testScenario := range expectedTestScenarios {
+		runMetadata.ScenarioNames = append(runMetadata.ScenarioNames, testScenario.GetName())
+	}
+	if *parallel {
+		runScenariosInParallel(expectedTestScenarios, conf.Common, results)
+	} else {
+		for _, testScenario := range expectedTestScenarios {
+			testScenario.Run(results)
+		}
+	}
+	if err = scenarios.OutputSweeps(results, sweeps, conf.Common); err != nil {
+		utils.Logger.Error("failed to output sweep charts", zap.Error(err))
+	}
+	runMetadata.EndTime = time.Now()
+	utils.Logger.Info("all tests have been done, generate report")
+	results.RefreshStatus()
+	fmt.Println(results.String())
+	if err = utils.SaveResults(results, runMetadata); err != nil {
+		utils.Logger.Error("failed to save results", zap.Error(err))
+	}
+	if err = utils.NewHTMLReporter(results, runMetadata).GenerateReport(); err != nil {
+		utils.Logger.Error("failed to generate HTML report", zap.Error(err))
+	}
+	// observers are closed at last since a slow webhook may take a while to flush its events
+	results.Close()
+	return 0
+}
+
+// initRun expands case matrices of the effective config into sweeps and parses it, creates the output directory
+// into which the effective config is written as it is (with matrices), and inits expected test scenarios with
+// their own isolation config if configured
+func initRun(configContent []byte, scenarioNames string, testScenarios []framework.TestScenario) (
+	*framework.Config, *utils.KubeClient, []*framework.Sweep) {
+	expandedContent, sweeps, err := framework.ExpandMatrices(configContent)
+	if err != nil {
+		utils.Logger.Fatal("failed to expand matrices", zap.Error(err))
+	}
+	conf, err := framework.ParseConfig(expandedContent)
 	if err != nil {
 		utils.Logger.Fatal("failed to initialize config", zap.Error(err))
 	}
@@ -95,48 +281,181 @@ func main() {
 	if err != nil {
 		utils.Logger.Fatal("failed to initialize kube-client", zap.Error(err))
 	}
-	// prepare expected test scenarios due to optional flag "scenarios",
-	// set to all registered test scenarios if not configured.
-	expectedTestScenarios := make([]framework.TestScenario, 0)
-	if commandLineConfig.ScenarioNames != "" {
-		for _, scenarioName := range strings.Split(commandLineConfig.ScenarioNames, ",") {
-			if ts := framework.GetRegisteredTestScenarios()[scenarioName]; ts != nil {
-				expectedTestScenarios = append(expectedTestScenarios, ts)
-			} else {
-				utils.Logger.Fatal("can't find specified scenario",
-					zap.String("specifiedScenarioName", scenarioName),
-					zap.Any("registeredTestScenarios", framework.GetRegisteredTestScenarios()))
-			}
-		}
-	} else {
-		for _, ts := range framework.GetRegisteredTestScenarios() {
-			expectedTestScenarios = append(expectedTestScenarios, ts)
-		}
-	}
 	// prepare output directory
 	outputTime := time.Now().Format(DateTimeLayout)
 	conf.Common.OutputPath = fmt.Sprintf("%s/%s-%s-%s",
-		conf.Common.OutputRootPath, OutputDirNamePrefix, commandLineConfig.ScenarioNames, outputTime)
+		conf.Common.OutputRootPath, OutputDirNamePrefix, scenarioNames, outputTime)
 	err = os.Mkdir(conf.Common.OutputPath, os.ModePerm)
 	if err != nil {
 		utils.Logger.Fatal("failed to create output directory",
 			zap.String("outputPath", conf.Common.OutputPath), zap.Error(err))
 	}
-	// init expected test scenarios first
-	for _, testScenario := range expectedTestScenarios {
-		err := testScenario.Init(kubeClient, conf)
+	effectiveConfigFile := filepath.Join(conf.Common.OutputPath, framework.EffectiveConfigFileName)
+	// #nosec G306 - the config is not more sensitive than the config file
+	if err = os.WriteFile(effectiveConfigFile, configContent, 0644); err != nil {
+		utils.Logger.Fatal("failed to write effective config", zap.String("filePath", effectiveConfigFile),
+			zap.Error(err))
+	}
+	for _, testScenario := range testScenarios {
+		scenarioConf, err := conf.ForScenario(testScenario.GetName())
+		if err == nil {
+			err = testScenario.Init(kubeClient, scenarioConf)
+		}
 		if err != nil {
 			utils.Logger.Fatal("failed to initialize scenario",
 				zap.String("scenarioName", testScenario.GetName()),
 				zap.Error(err))
 		}
 	}
-	// run expected test scenarios
-	results := utils.NewResults()
-	for _, testScenario := range expectedTestScenarios {
-		testScenario.Run(results)
+	return conf, kubeClient, sweeps
+}
+
+// getExpectedTestScenarios returns test scenarios specified by the comma separated names, or all registered
+// test scenarios if not specified, disruptive ones are only included if specified or includeDisruptive is true.
+func getExpectedTestScenarios(scenarioNames string, includeDisruptive bool) ([]framework.TestScenario, error) {
+	expectedTestScenarios := make([]framework.TestScenario, 0)
+	if scenarioNames != "" {
+		for _, scenarioName := range strings.Split(scenarioNames, ",") {
+			ts := framework.GetRegisteredTestScenarios()[scenarioName]
+			if ts == nil {
+				return nil, fmt.Errorf("can't find specified scenario %q, run %q to see registered scenarios",
+					scenarioName, CommandListScenarios)
+			}
+			expectedTestScenarios = append(expectedTestScenarios, ts)
+		}
+	} else {
+		for _, ts := range framework.GetRegisteredTestScenarios() {
+			if includeDisruptive || !framework.IsDisruptive(ts) {
+				expectedTestScenarios = append(expectedTestScenarios, ts)
+			}
+		}
+	}
+	return expectedTestScenarios, nil
+}
+
+// loadConfig merges config layers into the effective config and validates it, all invalid entries are printed
+// with line numbers of the base file, or of the effective config if there are other layers. Matrices of cases
+// are validated as they are and kept in the returned config, which is expanded by initRun.
+func loadConfig(configLayers *framework.ConfigLayers, testScenarios []framework.TestScenario) ([]byte, bool) {
+	configName := configLayers.BaseFile
+	if configLayers.IsLayered() {
+		configName = fmt.Sprintf("effective config of %s (see %s -print)", configLayers.BaseFile,
+			CommandValidate)
+	}
+	configContent, err := configLayers.Merge()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", configName, err.Error())
+		return nil, false
+	}
+	scenarioNames := make([]string, 0, len(testScenarios))
+	for _, testScenario := range testScenarios {
+		scenarioNames = append(scenarioNames, testScenario.GetName())
+	}
+	configErrors, err := framework.ValidateConfigContent(configContent, scenarioNames)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", configName, err.Error())
+		return nil, false
+	}
+	for _, configErr := range configErrors {
+		fmt.Fprintf(os.Stderr, "%s:%d: %s: %s\n", configName, configErr.Line, configErr.Path, configErr.Message)
+	}
+	if len(configErrors) > 0 {
+		return configContent, false
+	}
+	// matrices can still refer to list entries which don't exist
+	if _, _, err = framework.ExpandMatrices(configContent); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", configName, err.Error())
+		return configContent, false
+	}
+	return configContent, true
+}
+
+// dryRunScenarios renders manifests and estimates the load of every case of expected test scenarios
+// against the nodes selected for them, nothing is created in the cluster.
+func dryRunScenarios(kubeClient *utils.KubeClient, conf *framework.Config, testScenarios []framework.TestScenario) {
+	for _, testScenario := range testScenarios {
+		scenarioConf, err := conf.ForScenario(testScenario.GetName())
+		if err == nil {
+			err = framework.NewDryRunner(kubeClient, scenarioConf.Common).Run(testScenario)
+		}
+		if err != nil {
+			utils.Logger.Fatal("failed to dry run scenario",
+				zap.String("scenarioName", testScenario.GetName()),
+				zap.Error(err))
+		}
+	}
+	utils.Logger.Info("dry run has been done, nothing was created in the cluster",
+		zap.String("outputPath", conf.Common.OutputPath))
+}
+
+// runScenariosInParallel runs every scenario in its own goroutine and waits for all of them to be done,
+// a warning is logged for scenarios sharing the same node selector since they may interfere with each other.
+// checkParallel returns an error if the scenarios can't run in parallel: disruptive scenarios break other
+// scenarios, and cases overriding the scheduler config would patch and restore the same ConfigMap concurrently.
+func checkParallel(configContent []byte, testScenarios []framework.TestScenario) error {
+	scenarioNames := make([]string, 0, len(testScenarios))
+	for _, testScenario := range testScenarios {
+		if framework.IsDisruptive(testScenario) {
+			return fmt.Errorf("scenario %s is disruptive", testScenario.GetName())
+		}
+		scenarioNames = append(scenarioNames, testScenario.GetName())
+	}
+	expandedContent, _, err := framework.ExpandMatrices(configContent)
+	if err != nil {
+		return err
+	}
+	paths, err := framework.FindSchedulerConfigOverrides(expandedContent, scenarioNames)
+	if err != nil {
+		return err
+	}
+	if len(paths) > 0 {
+		return fmt.Errorf("the scheduler config is overridden by %s", strings.Join(paths, ", "))
+	}
+	return nil
+}
+
+func runScenariosInParallel(testScenarios []framework.TestScenario, commonConf *framework.CommonConfig,
+	results *utils.Results) {
+	scenariosByNodeSelector := make(map[string][]string)
+	for _, testScenario := range testScenarios {
+		nodeSelector := commonConf.NodeSelector
+		if isolation := commonConf.Isolation[testScenario.GetName()]; isolation != nil && isolation.NodeSelector != "" {
+			nodeSelector = isolation.NodeSelector
+		}
+		scenariosByNodeSelector[nodeSelector] = append(scenariosByNodeSelector[nodeSelector], testScenario.GetName())
+	}
+	for nodeSelector, scenarioNames := range scenariosByNodeSelector {
+		if len(scenarioNames) > 1 {
+			utils.Logger.Warn("scenarios share the same nodes and may interfere with each other",
+				zap.String("nodeSelector", nodeSelector), zap.Strings("scenarioNames", scenarioNames))
+		}
+	}
+	var wg sync.WaitGroup
+	for _, testScenario := range testScenarios {
+		wg.Add(1)
+		go func(testScenario framework.TestScenario) {
+			defer wg.Done()
+			utils.Logger.Info("start running scenario in parallel", zap.String("scenarioName", testScenario.GetName()))
+			testScenario.Run(results)
+			utils.Logger.Info("finished running scenario", zap.String("scenarioName", testScenario.GetName()))
+		}(testScenario)
+	}
+	wg.Wait()
+}
+
+// addObservers streams events of results to a JSON Lines file in the output directory,
+// and optionally to the console and a webhook
+func addObservers(results *utils.Results, commonConf *framework.CommonConfig) {
+	eventsFilePath := filepath.Join(commonConf.OutputPath, EventsFileName)
+	jsonLinesObserver, err := utils.NewJSONLinesObserver(eventsFilePath)
+	if err != nil {
+		utils.Logger.Fatal("failed to create events file", zap.String("filePath", eventsFilePath), zap.Error(err))
+	}
+	results.AddObserver(jsonLinesObserver)
+	if commonConf.ConsoleProgress {
+		results.AddObserver(utils.NewConsoleObserver(os.Stdout))
+	}
+	if commonConf.EventsWebhookURL != "" {
+		results.AddObserver(utils.NewWebhookObserver(commonConf.EventsWebhookURL))
 	}
-	utils.Logger.Info("all tests have been done, generate report")
-	results.RefreshStatus()
-	fmt.Println(results.String())
 }
@@ -0,0 +1,103 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/apache/yunikorn-release/perf-tools/framework"
+	"github.com/apache/yunikorn-release/perf-tools/utils"
+)
+
+func TestRunCommand(t *testing.T) {
+	assert.Equal(t, runCommand([]string{"unknown"}), exitCodeUsage)
+	assert.Equal(t, runCommand([]string{CommandListScenarios}), 0)
+	assert.Equal(t, runCommand([]string{CommandListScenarios, "-scenarios", "unknown"}), exitCodeUsage)
+	assert.Equal(t, runCommand([]string{CommandValidate, "-h"}), 0)
+	// the config file shipped with this tool must be valid
+	assert.Equal(t, runCommand([]string{CommandValidate, "-config", ConfigFileName}), 0)
+	assert.Equal(t, runCommand([]string{CommandValidate, "-config", "not-exist.yaml"}), exitCodeFailed)
+	// disruptive scenarios are opt-in, so the config is only required if they are specified
+	assert.Equal(t, runCommand([]string{CommandValidate, "-scenarios", "recovery"}), exitCodeFailed)
+	// overrides are validated as part of the effective config
+	assert.Equal(t, runCommand([]string{CommandValidate, "-set", "common.maxWaitSeconds=900",
+		"--set", "scenarios.throughput.cleanUpDelayMs=100"}), 0)
+	assert.Equal(t, runCommand([]string{CommandValidate, "-set", "common.maxWaitSeconds=-1"}), exitCodeFailed)
+	assert.Equal(t, runCommand([]string{CommandValidate, "-set", "common.maxWaitSecond"}), exitCodeFailed)
+
+	// report and compare need output directories as arguments
+	assert.Equal(t, runCommand([]string{CommandReport}), exitCodeUsage)
+	assert.Equal(t, runCommand([]string{CommandCompare, t.TempDir()}), exitCodeUsage)
+	outputPaths := make([]string, 2)
+	for i, totalSeconds := range []float64{10, 12} {
+		outputPaths[i] = t.TempDir()
+		results := utils.NewResults()
+		results.CreateScenarioResults("throughput").AddVerificationGroup("Case-0", "").
+			AddMetric("totalSeconds", totalSeconds, utils.UnitSeconds)
+		assert.NilError(t, utils.SaveResults(results, &utils.RunMetadata{OutputPath: outputPaths[i]}))
+	}
+	assert.Equal(t, runCommand([]string{CommandReport, outputPaths[0]}), 0)
+	_, err := os.Stat(filepath.Join(outputPaths[0], utils.HTMLReportFileName))
+	assert.NilError(t, err)
+	assert.Equal(t, runCommand([]string{CommandCompare, "-tableFormats", "csv", outputPaths[0], outputPaths[1]}), 0)
+	_, err = os.Stat(filepath.Join(outputPaths[1], "compare.csv"))
+	assert.NilError(t, err)
+	assert.Equal(t, runCommand([]string{CommandReport, t.TempDir()}), exitCodeFailed)
+}
+
+func TestGetExpectedTestScenarios(t *testing.T) {
+	testScenarios, err := getExpectedTestScenarios("", false)
+	assert.NilError(t, err)
+	for _, testScenario := range testScenarios {
+		assert.Assert(t, !framework.IsDisruptive(testScenario), testScenario.GetName())
+	}
+	allTestScenarios, err := getExpectedTestScenarios("", true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(allTestScenarios), len(framework.GetRegisteredTestScenarios()))
+	assert.Assert(t, len(testScenarios) < len(allTestScenarios))
+	testScenarios, err = getExpectedTestScenarios("recovery,throughput", false)
+	assert.NilError(t, err)
+	assert.Equal(t, len(testScenarios), 2)
+	_, err = getExpectedTestScenarios("unknown", false)
+	assert.ErrorContains(t, err, "can't find specified scenario")
+}
+
+func TestCheckParallel(t *testing.T) {
+	registered := framework.GetRegisteredTestScenarios()
+	testScenarios := []framework.TestScenario{registered["throughput"], registered["churn"]}
+	assert.NilError(t, checkParallel([]byte("scenarios:\n  throughput:\n    cases:\n      - description: a\n"),
+		testScenarios))
+	err := checkParallel([]byte(`
+scenarios:
+  throughput:
+    cases:
+      - matrix:
+          description: [a, b]
+        schedulerConfig:
+          service.schedulingInterval: 2s
+`), testScenarios)
+	assert.ErrorContains(t, err, "overridden by scenarios.throughput.cases[0].schedulerConfig, "+
+		"scenarios.throughput.cases[1].schedulerConfig")
+	err = checkParallel([]byte("scenarios: {}\n"), []framework.TestScenario{registered["recovery"]})
+	assert.ErrorContains(t, err, "scenario recovery is disruptive")
+}
@@ -0,0 +1,317 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scenarios
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/apache/yunikorn-release/perf-tools/constants"
+	"github.com/apache/yunikorn-release/perf-tools/framework"
+	"github.com/apache/yunikorn-release/perf-tools/utils"
+)
+
+const (
+	AdmissionOverheadScenarioName = "admission_overhead"
+
+	DefaultAdmissionSchedulerName = "yunikorn"
+	DefaultCreateTimeoutSeconds   = 10
+	DefaultCreateConcurrency      = 1
+
+	webhookColumnName  = "webhook"
+	bypassedColumnName = "bypassed"
+)
+
+// AdmissionComparedMetrics are the metrics compared between namespaces with and without the admission webhook
+var AdmissionComparedMetrics = []*utils.ComparedMetric{
+	{Name: MetricP50CreateLatency, Unit: utils.UnitMilliseconds, LowerIsBetter: true},
+	{Name: MetricP90CreateLatency, Unit: utils.UnitMilliseconds, LowerIsBetter: true},
+	{Name: MetricP99CreateLatency, Unit: utils.UnitMilliseconds, LowerIsBetter: true},
+	{Name: MetricMaxCreateLatency, Unit: utils.UnitMilliseconds, LowerIsBetter: true},
+	{Name: MetricCreateQPS, Unit: utils.UnitQPS},
+	{Name: MetricNumCreateErrors, Unit: utils.UnitPods, LowerIsBetter: true},
+	{Name: MetricNumCreateTimeouts, Unit: utils.UnitPods, LowerIsBetter: true},
+}
+
+type AdmissionOverheadScenario struct {
+	kubeClient   *utils.KubeClient
+	commonConf   *framework.CommonConfig
+	scenarioConf *AdmissionOverheadScenarioConfig
+	tableFormats []utils.TableFormat
+	chartFormats []utils.ChartFormat
+}
+
+type AdmissionOverheadScenarioConfig struct {
+	SchedulerName string `validate:"scheduler"`
+	// timeout of every create call, a call exceeding it is counted as a timeout
+	CreateTimeoutSeconds int `validate:"nonnegative"`
+	TableFormats         []string
+	Cases                []*AdmissionOverheadCaseConfig `validate:"required"`
+}
+
+type AdmissionOverheadCaseConfig struct {
+	Description string
+	NumPods     int `validate:"required,positive"`
+	// number of concurrent create calls
+	Concurrency      int               `validate:"nonnegative"`
+	RequestResources map[string]string `validate:"quantity"`
+	// namespace where the admission webhook applies, defaults to the namespace in common config
+	WebhookNamespace string
+	// optional existing namespace bypassed by the admission webhook, in which test pods are created as well
+	BypassedNamespace string
+	SchedulerConfig   SchedulerConfigOverrides
+}
+
+// createResult keeps the client-side outcome of a pod create call
+type createResult struct {
+	latency time.Duration
+	err     error
+}
+
+func init() {
+	framework.Register(&AdmissionOverheadScenario{})
+}
+
+func (aos *AdmissionOverheadScenario) GetName() string {
+	return AdmissionOverheadScenarioName
+}
+
+func (aos *AdmissionOverheadScenario) NewScenarioConfig() interface{} {
+	return &AdmissionOverheadScenarioConfig{}
+}
+
+func (aos *AdmissionOverheadScenario) Init(kubeClient *utils.KubeClient, conf *framework.Config) error {
+	aos.kubeClient = kubeClient
+	aos.commonConf = conf.Common
+	aos.scenarioConf = &AdmissionOverheadScenarioConfig{}
+	if err := LoadScenarioConf(conf, aos.GetName(), aos.scenarioConf); err != nil {
+		return err
+	}
+	if aos.scenarioConf.SchedulerName == "" {
+		aos.scenarioConf.SchedulerName = DefaultAdmissionSchedulerName
+	}
+	if aos.scenarioConf.CreateTimeoutSeconds <= 0 {
+		aos.scenarioConf.CreateTimeoutSeconds = DefaultCreateTimeoutSeconds
+	}
+	for _, testCase := range aos.scenarioConf.Cases {
+		if testCase.Concurrency <= 0 {
+			testCase.Concurrency = DefaultCreateConcurrency
+		}
+		if testCase.WebhookNamespace == "" {
+			testCase.WebhookNamespace = aos.commonConf.Namespace
+		}
+	}
+	var err error
+	aos.tableFormats, err = GetTableFormats(aos.commonConf, aos.scenarioConf.TableFormats)
+	if err != nil {
+		return err
+	}
+	aos.chartFormats, err = utils.ParseChartFormats(aos.commonConf.ChartFormats)
+	return err
+}
+
+func (aos *AdmissionOverheadScenario) Run(results *utils.Results) {
+	scenarioResults := results.CreateScenarioResults(aos.GetName())
+	maxWaitTime := time.Duration(aos.commonConf.MaxWaitSeconds) * time.Second
+	restoreSchedulerConfig := func() {}
+	// make sure scheduler config is restored when error occurred
+	defer func() {
+		restoreSchedulerConfig()
+	}()
+
+	for caseIndex, testCase := range aos.scenarioConf.Cases {
+		verGroupName := fmt.Sprintf("Case-%d", caseIndex)
+		verGroupDescription := fmt.Sprintf("%+v", testCase.Description)
+		caseVerification := scenarioResults.AddVerificationGroup(verGroupName, verGroupDescription)
+		utils.Logger.Info("[Prepare] add verification group", zap.String("name", verGroupName),
+			zap.String("description", verGroupDescription))
+		appInfo := aos.newAppInfo(caseIndex, testCase)
+		filePathPrefix := fmt.Sprintf("%s/%s-case%d", aos.commonConf.OutputPath, aos.GetName(), caseIndex)
+		podTemplateSpec, err := framework.NewPodTemplateSpec(aos.scenarioConf.SchedulerName, appInfo,
+			appInfo.RequestInfos[0])
+		if err != nil {
+			utils.Logger.Error("failed to build pod template", zap.Error(err))
+			caseVerification.AddSubVerification("build pod template", err.Error(), utils.FAILED)
+			return
+		}
+		if restoreSchedulerConfig, err = ApplySchedulerConfig(aos.kubeClient, aos.commonConf,
+			testCase.SchedulerConfig, caseVerification); err != nil {
+			return
+		}
+
+		// test for namespaces with and without the admission webhook
+		namespaces := map[string]string{webhookColumnName: testCase.WebhookNamespace}
+		columnNames := []string{webhookColumnName}
+		if testCase.BypassedNamespace != "" {
+			namespaces[bypassedColumnName] = testCase.BypassedNamespace
+			columnNames = append(columnNames, bypassedColumnName)
+		}
+		comparison := utils.NewComparison(AdmissionComparedMetrics)
+		latencies := make(map[string][]float64, len(columnNames))
+		for _, columnName := range columnNames {
+			appInfo.Namespace = namespaces[columnName]
+			namespaceVerification := caseVerification.AddSubVerificationGroup(
+				fmt.Sprintf("test for %s namespace %s", columnName, appInfo.Namespace), verGroupDescription).Start()
+			createResults := aos.createPods(testCase, appInfo, &podTemplateSpec)
+			namespaceVerification.Finish()
+			latencies[columnName] = addCreateMetrics(namespaceVerification, createResults,
+				namespaceVerification.Duration)
+			comparison.AddVerification(columnName, namespaceVerification)
+
+			// delete pods and wait for them to be cleaned up
+			utils.Logger.Info("[Cleanup] delete pods then wait for them to be cleaned up",
+				zap.String("namespace", appInfo.Namespace), zap.String("appID", appInfo.AppID))
+			if err = DeletePodsWait(aos.kubeClient, appInfo.Namespace, appInfo.AppID, maxWaitTime); err != nil {
+				utils.Logger.Error("failed to delete/wait pods", zap.Error(err))
+				namespaceVerification.AddSubVerification("cleanup pods", err.Error(), utils.FAILED)
+				return
+			}
+		}
+		if err = OutputComparison(caseVerification, comparison, filePathPrefix+"-comparison",
+			aos.tableFormats); err != nil {
+			return
+		}
+		chart := &utils.Chart{
+			Kind:           utils.ChartKindBoxPlot,
+			Title:          "Pod Create Latency",
+			YLabel:         "Latency (ms)",
+			Width:          constants.ChartWidth,
+			Height:         constants.ChartHeight,
+			Values:         latencies,
+			FilePathPrefix: filePathPrefix + "-create-latency",
+			Formats:        aos.chartFormats,
+		}
+		if err = OutputChart(caseVerification, "output create latency chart", chart); err != nil {
+			return
+		}
+		restoreSchedulerConfig()
+	}
+}
+
+func (aos *AdmissionOverheadScenario) Plan() ([]*framework.CasePlan, error) {
+	plans := make([]*framework.CasePlan, 0, len(aos.scenarioConf.Cases))
+	for caseIndex, testCase := range aos.scenarioConf.Cases {
+		// pods are created in namespaces with and without the admission webhook one after another
+		namespaces := []string{testCase.WebhookNamespace}
+		if testCase.BypassedNamespace != "" {
+			namespaces = append(namespaces, testCase.BypassedNamespace)
+		}
+		appInfos := make([]*framework.AppInfo, 0, len(namespaces))
+		for _, namespace := range namespaces {
+			appInfo := aos.newAppInfo(caseIndex, testCase)
+			appInfo.Namespace = namespace
+			// #nosec G115 - This is a false positive, the input is controlled and safe
+			appInfo.RequestInfos[0].Number = int32(testCase.NumPods)
+			appInfos = append(appInfos, appInfo)
+		}
+		plans = append(plans, &framework.CasePlan{
+			Description:    testCase.Description,
+			SchedulerNames: []string{aos.scenarioConf.SchedulerName},
+			AppInfos:       appInfos,
+			CreatePods:     true,
+			Sequential:     true,
+		})
+	}
+	return plans, nil
+}
+
+// newAppInfo returns the app of which pods are created directly, its namespace is set for every test
+func (aos *AdmissionOverheadScenario) newAppInfo(caseIndex int,
+	testCase *AdmissionOverheadCaseConfig) *framework.AppInfo {
+	return framework.NewAppInfo("", fmt.Sprintf("admission-overhead-case%d", caseIndex),
+		aos.commonConf.Queue,
+		[]*framework.RequestInfo{framework.NewRequestInfo(1, "", testCase.RequestResources, nil)},
+		aos.commonConf.PodTemplateSpec, aos.commonConf.PodSpec)
+}
+
+// createPods creates pods directly with the configured concurrency and returns the outcome of every call
+func (aos *AdmissionOverheadScenario) createPods(testCase *AdmissionOverheadCaseConfig,
+	appInfo *framework.AppInfo, podTemplateSpec *apiv1.PodTemplateSpec) []*createResult {
+	utils.Logger.Info("[Testing] create pods", zap.String("namespace", appInfo.Namespace),
+		zap.Int("numPods", testCase.NumPods), zap.Int("concurrency", testCase.Concurrency))
+	timeout := time.Duration(aos.scenarioConf.CreateTimeoutSeconds) * time.Second
+	createResults := make([]*createResult, testCase.NumPods)
+	podIndexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < testCase.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for podIndex := range podIndexes {
+				pod := NewPodFromTemplate(appInfo.Namespace, fmt.Sprintf("%s-%d", appInfo.AppID, podIndex),
+					podTemplateSpec)
+				startTime := time.Now()
+				err := aos.kubeClient.CreatePod(appInfo.Namespace, pod, timeout)
+				createResults[podIndex] = &createResult{latency: time.Since(startTime), err: err}
+			}
+		}()
+	}
+	for i := 0; i < testCase.NumPods; i++ {
+		podIndexes <- i
+	}
+	close(podIndexes)
+	wg.Wait()
+	return createResults
+}
+
+// addCreateMetrics records latency percentiles, QPS, errors and timeouts of create calls onto the verification,
+// latencies (in milliseconds) of successful calls are returned.
+func addCreateMetrics(verification *utils.Verification, createResults []*createResult,
+	elapsedTime time.Duration) []float64 {
+	latencies := make([]float64, 0, len(createResults))
+	var numErrors, numTimeouts int
+	for _, result := range createResults {
+		switch {
+		case result.err == nil:
+			latencies = append(latencies, float64(result.latency.Microseconds())/1000)
+		case errors.Is(result.err, context.DeadlineExceeded) || k8serrors.IsTimeout(result.err) ||
+			k8serrors.IsServerTimeout(result.err):
+			numTimeouts++
+		default:
+			numErrors++
+			utils.Logger.Debug("failed to create pod", zap.Error(result.err))
+		}
+	}
+	var createQPS float64
+	if elapsedTime > 0 {
+		createQPS = float64(len(latencies)) / elapsedTime.Seconds()
+	}
+	verification.AddMetric(MetricP50CreateLatency, utils.Percentile(latencies, 50), utils.UnitMilliseconds).
+		AddMetric(MetricP90CreateLatency, utils.Percentile(latencies, 90), utils.UnitMilliseconds).
+		AddMetric(MetricP99CreateLatency, utils.Percentile(latencies, 99), utils.UnitMilliseconds).
+		AddMetric(MetricMaxCreateLatency, utils.Percentile(latencies, 100), utils.UnitMilliseconds).
+		AddMetric(MetricCreateQPS, createQPS, utils.UnitQPS).
+		AddMetric(MetricNumCreateErrors, float64(numErrors), utils.UnitPods).
+		AddMetric(MetricNumCreateTimeouts, float64(numTimeouts), utils.UnitPods)
+	verification.AddAssertSubVerification(numErrors == 0 && numTimeouts == 0, "create pods",
+		fmt.Sprintf("numPods=%d, numErrors=%d, numTimeouts=%d", len(createResults), numErrors, numTimeouts))
+	utils.Logger.Info("[Analyze] pod create latency",
+		zap.Float64("p50(ms)", utils.Percentile(latencies, 50)),
+		zap.Float64("p99(ms)", utils.Percentile(latencies, 99)),
+		zap.Int("numErrors", numErrors),
+		zap.Int("numTimeouts", numTimeouts))
+	return latencies
+}
@@ -0,0 +1,391 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scenarios
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/yunikorn-release/perf-tools/constants"
+	"github.com/apache/yunikorn-release/perf-tools/framework"
+	"github.com/apache/yunikorn-release/perf-tools/utils"
+)
+
+const (
+	BinPackingScenarioName = "bin_packing"
+
+	DefaultProbeTimeoutSeconds = 60
+)
+
+var DefaultBinPackingResourceNames = []string{"cpu", "memory"}
+
+type BinPackingScenario struct {
+	kubeClient   *utils.KubeClient
+	commonConf   *framework.CommonConfig
+	scenarioConf *BinPackingScenarioConfig
+	tableFormats []utils.TableFormat
+	chartFormats []utils.ChartFormat
+}
+
+type BinPackingScenarioConfig struct {
+	SchedulerNames []string `validate:"required,scheduler"`
+	TableFormats   []string
+	Cases          []*BinPackingCaseConfig `validate:"required"`
+}
+
+type BinPackingCaseConfig struct {
+	Description string
+	// mixed-size pods to be submitted
+	RequestConfigs []*RequestConfig `validate:"required"`
+	// resources to be analyzed, defaults to cpu and memory
+	ResourceNames []string
+	// utilization ratio at which a resource on a node is considered full,
+	// defaults to DefaultFullUtilizationThreshold
+	FullUtilizationThreshold float64 `validate:"nonnegative"`
+	// optional large pod submitted after all pods are running to check whether it can still be placed
+	ProbePod        *ProbePodConfig
+	SchedulerConfig SchedulerConfigOverrides
+	// optional scheduler configs to be compared side by side (e.g. nodesortpolicy binpacking vs fair), the case
+	// runs for every scheduler once per config, results are labeled by names of configs.
+	// Only one of SchedulerConfig and SchedulerConfigs can be set.
+	SchedulerConfigs []*NamedSchedulerConfig
+}
+
+// NamedSchedulerConfig is a set of scheduler config overrides labeled by its name
+type NamedSchedulerConfig struct {
+	Name   string                   `validate:"required"`
+	Config SchedulerConfigOverrides `validate:"required"`
+}
+
+type ProbePodConfig struct {
+	RequestResources map[string]string `validate:"required,quantity"`
+	TimeoutSeconds   int               `validate:"nonnegative"`
+}
+
+func init() {
+	framework.Register(&BinPackingScenario{})
+}
+
+func (bps *BinPackingScenario) GetName() string {
+	return BinPackingScenarioName
+}
+
+func (bps *BinPackingScenario) NewScenarioConfig() interface{} {
+	return &BinPackingScenarioConfig{}
+}
+
+func (bps *BinPackingScenario) Init(kubeClient *utils.KubeClient, conf *framework.Config) error {
+	bps.kubeClient = kubeClient
+	bps.commonConf = conf.Common
+	bps.scenarioConf = &BinPackingScenarioConfig{}
+	if err := LoadScenarioConf(conf, bps.GetName(), bps.scenarioConf); err != nil {
+		return err
+	}
+	for caseIndex, testCase := range bps.scenarioConf.Cases {
+		if len(testCase.SchedulerConfig) > 0 && len(testCase.SchedulerConfigs) > 0 {
+			return fmt.Errorf("only one of schedulerConfig and schedulerConfigs can be set in case %d", caseIndex)
+		}
+	}
+	var err error
+	bps.tableFormats, err = GetTableFormats(bps.commonConf, bps.scenarioConf.TableFormats)
+	if err != nil {
+		return err
+	}
+	bps.chartFormats, err = utils.ParseChartFormats(bps.commonConf.ChartFormats)
+	return err
+}
+
+func (bps *BinPackingScenario) Run(results *utils.Results) {
+	scenarioResults := results.CreateScenarioResults(bps.GetName())
+	maxWaitTime := time.Duration(bps.commonConf.MaxWaitSeconds) * time.Second
+	var appManager framework.AppManager
+	var appInfo, probeAppInfo *framework.AppInfo
+	restoreSchedulerConfig := func() {}
+	// make sure apps are cleaned up when error occurred
+	defer func() {
+		CleanupApp(appManager, probeAppInfo, maxWaitTime)
+		CleanupApp(appManager, appInfo, maxWaitTime)
+		restoreSchedulerConfig()
+	}()
+
+	// init node analyzer and calculate allocated resource for nodes
+	nodeAnalyzer := framework.NewNodeAnalyzer(bps.kubeClient, bps.commonConf.NodeSelector)
+	err := nodeAnalyzer.InitNodeInfosBeforeTesting()
+	if err != nil {
+		utils.Logger.Error("failed to init nodes", zap.Error(err))
+		scenarioResults.AddVerification("init nodes", err.Error(), utils.FAILED)
+		return
+	}
+	utils.Logger.Info("[Prepare] init nodes", zap.Int("numNodes", len(nodeAnalyzer.GetAllocatableNodes())))
+	nodeAnalyzer.CalculateAllocatedResource()
+
+	for caseIndex, testCase := range bps.scenarioConf.Cases {
+		verGroupName := fmt.Sprintf("Case-%d", caseIndex)
+		verGroupDescription := fmt.Sprintf("%+v", testCase.Description)
+		caseVerification := scenarioResults.AddVerificationGroup(verGroupName, verGroupDescription)
+		utils.Logger.Info("[Prepare] add verification group", zap.String("name", verGroupName),
+			zap.String("description", verGroupDescription))
+
+		// init app info & app manager
+		appInfo, probeAppInfo = bps.newAppInfos(testCase)
+		appManager = framework.NewDeploymentsAppManager(bps.kubeClient)
+		ykResourceNames := testCase.GetYKResourceNames()
+		comparison := utils.NewComparison(getBinPackingComparedMetrics(ykResourceNames))
+
+		// test for different scheduler configs and schedulers, which are compared side by side
+		for _, schedulerConfig := range testCase.GetSchedulerConfigs() {
+			if restoreSchedulerConfig, err = ApplySchedulerConfig(bps.kubeClient, bps.commonConf,
+				schedulerConfig.Config, caseVerification); err != nil {
+				return
+			}
+			for _, schedulerName := range bps.scenarioConf.SchedulerNames {
+				label, fileNameSuffix := schedulerName, schedulerName
+				if schedulerConfig.Name != "" {
+					label = fmt.Sprintf("%s (%s)", schedulerName, schedulerConfig.Name)
+					fileNameSuffix = schedulerName + "-" + schedulerConfig.Name
+				}
+				utils.Logger.Info("start testing for scheduler " + label)
+				schedulerVerification := caseVerification.AddSubVerificationGroup(
+					fmt.Sprintf("test for %s", label), verGroupDescription).Start()
+				filePathPrefix := fmt.Sprintf("%s/%s-case%d-%s",
+					bps.commonConf.OutputPath, bps.GetName(), caseIndex, fileNameSuffix)
+				if err = bps.runForScheduler(schedulerName, testCase, ykResourceNames, appManager, appInfo,
+					probeAppInfo, nodeAnalyzer, schedulerVerification, filePathPrefix, maxWaitTime); err != nil {
+					return
+				}
+				schedulerVerification.Finish()
+				comparison.AddVerification(label, schedulerVerification)
+			}
+			restoreSchedulerConfig()
+		}
+		if err = OutputComparison(caseVerification, comparison, fmt.Sprintf("%s/%s-case%d-comparison",
+			bps.commonConf.OutputPath, bps.GetName(), caseIndex), bps.tableFormats); err != nil {
+			return
+		}
+	}
+}
+
+func (bps *BinPackingScenario) Plan() ([]*framework.CasePlan, error) {
+	plans := make([]*framework.CasePlan, 0, len(bps.scenarioConf.Cases))
+	for _, testCase := range bps.scenarioConf.Cases {
+		appInfo, probeAppInfo := bps.newAppInfos(testCase)
+		appInfos := []*framework.AppInfo{appInfo}
+		if probeAppInfo != nil {
+			appInfos = append(appInfos, probeAppInfo)
+		}
+		plans = append(plans, &framework.CasePlan{
+			Description:    testCase.Description,
+			SchedulerNames: bps.scenarioConf.SchedulerNames,
+			AppInfos:       appInfos,
+		})
+	}
+	return plans, nil
+}
+
+// newAppInfos returns the app of mixed-size pods and the optional probe app which is nil if not configured
+func (bps *BinPackingScenario) newAppInfos(testCase *BinPackingCaseConfig) (*framework.AppInfo, *framework.AppInfo) {
+	appInfo := framework.NewAppInfo(bps.commonConf.Namespace, BinPackingScenarioName, bps.commonConf.Queue,
+		ConvertToRequestInfos(testCase.RequestConfigs), bps.commonConf.PodTemplateSpec, bps.commonConf.PodSpec)
+	if testCase.ProbePod == nil {
+		return appInfo, nil
+	}
+	probeRequestInfo := framework.NewRequestInfo(1, "", testCase.ProbePod.RequestResources, nil)
+	probeAppInfo := framework.NewAppInfo(bps.commonConf.Namespace, BinPackingScenarioName+"-probe",
+		bps.commonConf.Queue, []*framework.RequestInfo{probeRequestInfo},
+		bps.commonConf.PodTemplateSpec, bps.commonConf.PodSpec)
+	return appInfo, probeAppInfo
+}
+
+// runForScheduler runs a case for the specified scheduler and analyzes the result,
+// an error is returned if the scenario should stop.
+func (bps *BinPackingScenario) runForScheduler(schedulerName string, testCase *BinPackingCaseConfig,
+	ykResourceNames []string, appManager framework.AppManager, appInfo, probeAppInfo *framework.AppInfo,
+	nodeAnalyzer *framework.NodeAnalyzer, verification *utils.Verification, filePathPrefix string,
+	maxWaitTime time.Duration) error {
+	appAnalyzer := framework.NewAppAnalyzer(appInfo)
+	nodeAnalyzer.ClearApps()
+
+	// create app and wait for it to be running
+	utils.Logger.Info("[Testing] create an app and wait for it to be running, refresh tasks status at last",
+		zap.String("appID", appInfo.AppID))
+	beginTime := time.Now().Truncate(time.Second)
+	err := appManager.CreateWaitAndRefreshTasksStatus(schedulerName, appInfo, maxWaitTime)
+	if err != nil {
+		utils.Logger.Error("failed to create/wait/refresh app", zap.Error(err))
+		verification.AddSubVerification("test app", err.Error(), utils.FAILED)
+		return err
+	}
+	utils.Logger.Info("all requirements of this app are satisfied",
+		zap.String("appID", appInfo.AppID),
+		zap.Duration("elapseTime", time.Since(beginTime)))
+	AddThroughputMetrics(verification, appAnalyzer.GetTimeDistribution(framework.PodScheduled))
+
+	// analyze packing quality of nodes
+	nodeAnalyzer.AnalyzeApp(appInfo)
+	scheduledNodes := nodeAnalyzer.GetScheduledNodes()
+	fullThreshold := testCase.FullUtilizationThreshold
+	if fullThreshold <= 0 {
+		fullThreshold = DefaultFullUtilizationThreshold
+	}
+	analysis := nodeAnalyzer.AnalyzeMultiResource(ykResourceNames, fullThreshold)
+	fillRatios := make([]float64, 0, len(scheduledNodes))
+	for _, node := range analysis.Nodes {
+		if _, ok := scheduledNodes[node.NodeID]; ok {
+			fillRatios = append(fillRatios, node.DominantShare)
+		}
+	}
+	var avgFillRatio float64
+	for _, fillRatio := range fillRatios {
+		avgFillRatio += fillRatio
+	}
+	if len(fillRatios) > 0 {
+		avgFillRatio /= float64(len(fillRatios))
+	}
+	verification.AddMetric(MetricNumUsedNodes, float64(len(scheduledNodes)), utils.UnitNodes).
+		AddMetric(MetricAvgFillRatio, avgFillRatio, utils.UnitNone).
+		AddMetric(MetricMinFillRatio, utils.Percentile(fillRatios, 0), utils.UnitNone).
+		AddMetric(MetricNumStrandedNodes, float64(analysis.NumStrandedNodes), utils.UnitNodes)
+	for _, ykResourceName := range ykResourceNames {
+		verification.AddMetric(ykResourceName+MetricSuffixFragmentation,
+			analysis.Fragmentation[ykResourceName], utils.UnitNone)
+	}
+	utils.Logger.Info("[Analyze] bin-packing quality",
+		zap.Int("numUsedNodes", len(scheduledNodes)),
+		zap.Int("numAllocatableNodes", len(nodeAnalyzer.GetAllocatableNodes())),
+		zap.Float64("avgFillRatio", avgFillRatio),
+		zap.Any("fragmentation", analysis.Fragmentation),
+		zap.Any("strandedResources", analysis.StrandedResources))
+	if err = OutputTable(verification, "output node usage table", parseTableFromMultiResourceAnalysis(analysis),
+		filePathPrefix+"-node-usage", bps.tableFormats); err != nil {
+		return err
+	}
+	chart := &utils.Chart{
+		Kind:           utils.ChartKindHistogram,
+		Title:          "Fill Ratio of Used Nodes",
+		XLabel:         "Dominant Share",
+		YLabel:         "Number of Nodes",
+		Width:          constants.ChartWidth,
+		Height:         constants.ChartHeight,
+		Values:         map[string][]float64{schedulerName: fillRatios},
+		Bins:           10,
+		FilePathPrefix: filePathPrefix + "-fill-ratio",
+		Formats:        bps.chartFormats,
+	}
+	if err = OutputChart(verification, "output fill ratio chart", chart); err != nil {
+		return err
+	}
+
+	// check whether a large probe pod can still be placed
+	if probeAppInfo != nil {
+		bps.checkProbePod(schedulerName, testCase.ProbePod, appManager, probeAppInfo, verification, maxWaitTime)
+	}
+
+	// delete this app and wait for it to be cleaned up
+	utils.Logger.Info("[Cleanup] delete this app then wait for it to be cleaned up",
+		zap.String("appID", appInfo.AppID))
+	err = DeleteWaitAndAnalyze(bps.kubeClient, bps.commonConf, appManager, appInfo, verification,
+		filePathPrefix, bps.tableFormats)
+	if err != nil {
+		utils.Logger.Error("failed to delete/wait app", zap.Error(err))
+		verification.AddSubVerification("cleanup app", err.Error(), utils.FAILED)
+		return err
+	}
+	return nil
+}
+
+// checkProbePod creates the probe pod and records whether it becomes running within the timeout. A probe pod
+// which can't be placed is a result of the case rather than a failure, the case only fails on errors.
+func (bps *BinPackingScenario) checkProbePod(schedulerName string, probeConf *ProbePodConfig,
+	appManager framework.AppManager, probeAppInfo *framework.AppInfo, verification *utils.Verification,
+	maxWaitTime time.Duration) {
+	timeoutSeconds := probeConf.TimeoutSeconds
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = DefaultProbeTimeoutSeconds
+	}
+	utils.Logger.Info("[Testing] create a probe pod and wait for it to be running",
+		zap.String("appID", probeAppInfo.AppID),
+		zap.Any("requestResources", probeConf.RequestResources),
+		zap.Int("timeoutSeconds", timeoutSeconds))
+	description := fmt.Sprintf("requestResources: %v", probeConf.RequestResources)
+	err := appManager.Create(schedulerName, probeAppInfo)
+	if err == nil {
+		// a timeout only means the probe pod doesn't fit, placement is checked by the refreshed status below
+		_ = appManager.WaitForAppsToBeSatisfied(probeAppInfo, time.Duration(timeoutSeconds)*time.Second)
+		err = appManager.RefreshAppStatus(probeAppInfo)
+	}
+	if err != nil {
+		utils.Logger.Error("failed to check probe pod", zap.Error(err))
+		verification.AddSubVerification("place probe pod", fmt.Sprintf("%s, error: %s", description, err.Error()),
+			utils.FAILED)
+	} else {
+		probePlaced := 0.0
+		if probeAppInfo.AppStatus.DesiredNum > 0 &&
+			probeAppInfo.AppStatus.ReadyNum == probeAppInfo.AppStatus.DesiredNum {
+			probePlaced = 1
+			description += ", placed: true"
+		} else {
+			description += fmt.Sprintf(", placed: false (not running within %d seconds)", timeoutSeconds)
+		}
+		verification.AddMetric(MetricProbePlaced, probePlaced, utils.UnitNone)
+		verification.AddSubVerification("place probe pod", description, utils.SUCCEEDED)
+	}
+	if err = appManager.DeleteWait(probeAppInfo, maxWaitTime); err != nil {
+		utils.Logger.Warn("failed to cleanup probe pod", zap.Error(err))
+	}
+}
+
+// GetSchedulerConfigs returns scheduler configs the case runs with, which is the unnamed SchedulerConfig
+// (maybe empty) if SchedulerConfigs is not set
+func (c *BinPackingCaseConfig) GetSchedulerConfigs() []*NamedSchedulerConfig {
+	if len(c.SchedulerConfigs) > 0 {
+		return c.SchedulerConfigs
+	}
+	return []*NamedSchedulerConfig{{Config: c.SchedulerConfig}}
+}
+
+// GetYKResourceNames returns names used by YuniKorn of all resources to be analyzed for this case
+func (c *BinPackingCaseConfig) GetYKResourceNames() []string {
+	resourceNames := c.ResourceNames
+	if len(resourceNames) == 0 {
+		resourceNames = DefaultBinPackingResourceNames
+	}
+	ykResourceNames := make([]string, len(resourceNames))
+	for i, resourceName := range resourceNames {
+		ykResourceNames[i], _ = getYKResourceName(resourceName)
+	}
+	return ykResourceNames
+}
+
+func getBinPackingComparedMetrics(ykResourceNames []string) []*utils.ComparedMetric {
+	metrics := []*utils.ComparedMetric{
+		{Name: MetricNumUsedNodes, Unit: utils.UnitNodes, LowerIsBetter: true},
+		{Name: MetricAvgFillRatio, Unit: utils.UnitNone},
+		{Name: MetricMinFillRatio, Unit: utils.UnitNone},
+		{Name: MetricNumStrandedNodes, Unit: utils.UnitNodes, LowerIsBetter: true},
+	}
+	for _, ykResourceName := range ykResourceNames {
+		metrics = append(metrics, &utils.ComparedMetric{Name: ykResourceName + MetricSuffixFragmentation,
+			Unit: utils.UnitNone, LowerIsBetter: true})
+	}
+	return append(metrics,
+		&utils.ComparedMetric{Name: MetricProbePlaced, Unit: utils.UnitNone},
+		&utils.ComparedMetric{Name: MetricTotalSeconds, Unit: utils.UnitSeconds, LowerIsBetter: true})
+}
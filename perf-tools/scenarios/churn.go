@@ -0,0 +1,438 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scenarios
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/apache/yunikorn-release/perf-tools/constants"
+	"github.com/apache/yunikorn-release/perf-tools/framework"
+	"github.com/apache/yunikorn-release/perf-tools/utils"
+)
+
+const ChurnScenarioName = "churn"
+
+type ChurnScenario struct {
+	kubeClient   *utils.KubeClient
+	commonConf   *framework.CommonConfig
+	scenarioConf *ChurnScenarioConfig
+	tableFormats []utils.TableFormat
+	chartFormats []utils.ChartFormat
+}
+
+type ChurnScenarioConfig struct {
+	SchedulerNames []string `validate:"required,scheduler"`
+	TableFormats   []string
+	Cases          []*ChurnCaseConfig `validate:"required"`
+}
+
+type ChurnCaseConfig struct {
+	Description string
+	// number of pods kept alive during the churn
+	TargetPods int `validate:"required,positive"`
+	// percentage of target pods deleted and replaced every second
+	ReplacePercentage float64           `validate:"nonnegative"`
+	DurationSeconds   int               `validate:"required,positive"`
+	RequestResources  map[string]string `validate:"quantity"`
+	LimitResources    map[string]string `validate:"quantity"`
+	SchedulerConfig   SchedulerConfigOverrides
+}
+
+// churnPod keeps the times of a pod created directly during the churn
+type churnPod struct {
+	// the second of the churn when this pod is created, -1 for pods created before the churn
+	createSecond  int
+	createTime    time.Time
+	scheduledTime time.Time
+}
+
+// churnTick keeps the statistics of one second during the churn
+type churnTick struct {
+	numCreated int
+	numDeleted int
+	// number of pods observed by the client as not scheduled yet at the beginning of this second
+	numPending int
+	// time taken to replace pods in this second, the churn falls behind if it exceeds a second
+	elapsed time.Duration
+}
+
+// churnRunner creates, deletes and tracks pods for one run of a case
+type churnRunner struct {
+	kubeClient      *utils.KubeClient
+	appInfo         *framework.AppInfo
+	podTemplateSpec apiv1.PodTemplateSpec
+	pods            map[string]*churnPod
+	// pods which are scheduled and not being deleted, candidates to be replaced
+	scheduledPods []string
+	numPending    int
+	nextPodIndex  int
+}
+
+func init() {
+	framework.Register(&ChurnScenario{})
+}
+
+func (cs *ChurnScenario) GetName() string {
+	return ChurnScenarioName
+}
+
+func (cs *ChurnScenario) NewScenarioConfig() interface{} {
+	return &ChurnScenarioConfig{}
+}
+
+func (cs *ChurnScenario) Init(kubeClient *utils.KubeClient, conf *framework.Config) error {
+	cs.kubeClient = kubeClient
+	cs.commonConf = conf.Common
+	cs.scenarioConf = &ChurnScenarioConfig{}
+	if err := LoadScenarioConf(conf, cs.GetName(), cs.scenarioConf); err != nil {
+		return err
+	}
+	var err error
+	cs.tableFormats, err = GetTableFormats(cs.commonConf, cs.scenarioConf.TableFormats)
+	if err != nil {
+		return err
+	}
+	cs.chartFormats, err = utils.ParseChartFormats(cs.commonConf.ChartFormats)
+	return err
+}
+
+func (cs *ChurnScenario) Run(results *utils.Results) {
+	scenarioResults := results.CreateScenarioResults(cs.GetName())
+	maxWaitTime := time.Duration(cs.commonConf.MaxWaitSeconds) * time.Second
+	var appInfo *framework.AppInfo
+	restoreSchedulerConfig := func() {}
+	// make sure pods are cleaned up when error occurred
+	defer func() {
+		if appInfo != nil {
+			if err := DeletePodsWait(cs.kubeClient, appInfo.Namespace, appInfo.AppID, maxWaitTime); err != nil {
+				utils.Logger.Info("failed to cleanup pods", zap.Error(err))
+			}
+		}
+		restoreSchedulerConfig()
+	}()
+
+	for caseIndex, testCase := range cs.scenarioConf.Cases {
+		verGroupName := fmt.Sprintf("Case-%d", caseIndex)
+		verGroupDescription := fmt.Sprintf("%+v", testCase.Description)
+		caseVerification := scenarioResults.AddVerificationGroup(verGroupName, verGroupDescription)
+		utils.Logger.Info("[Prepare] add verification group", zap.String("name", verGroupName),
+			zap.String("description", verGroupDescription))
+		var err error
+		if restoreSchedulerConfig, err = ApplySchedulerConfig(cs.kubeClient, cs.commonConf,
+			testCase.SchedulerConfig, caseVerification); err != nil {
+			return
+		}
+		appInfo = cs.newAppInfo(caseIndex, testCase)
+		comparison := utils.NewComparison(ChurnComparedMetrics)
+
+		// test for different schedulers
+		for _, schedulerName := range cs.scenarioConf.SchedulerNames {
+			utils.Logger.Info("start testing for scheduler " + schedulerName)
+			schedulerVerification := caseVerification.AddSubVerificationGroup(
+				fmt.Sprintf("test for %s", schedulerName), verGroupDescription).Start()
+			filePathPrefix := fmt.Sprintf("%s/%s-case%d-%s",
+				cs.commonConf.OutputPath, cs.GetName(), caseIndex, schedulerName)
+			if err = cs.runForScheduler(schedulerName, testCase, appInfo, schedulerVerification,
+				filePathPrefix, maxWaitTime); err != nil {
+				return
+			}
+			schedulerVerification.Finish()
+			comparison.AddVerification(schedulerName, schedulerVerification)
+		}
+		if err = OutputComparison(caseVerification, comparison, fmt.Sprintf("%s/%s-case%d-comparison",
+			cs.commonConf.OutputPath, cs.GetName(), caseIndex), cs.tableFormats); err != nil {
+			return
+		}
+		restoreSchedulerConfig()
+	}
+}
+
+func (cs *ChurnScenario) Plan() ([]*framework.CasePlan, error) {
+	plans := make([]*framework.CasePlan, 0, len(cs.scenarioConf.Cases))
+	for caseIndex, testCase := range cs.scenarioConf.Cases {
+		appInfo := cs.newAppInfo(caseIndex, testCase)
+		// #nosec G115 - This is a false positive, the input is controlled and safe
+		appInfo.RequestInfos[0].Number = int32(testCase.TargetPods)
+		plans = append(plans, &framework.CasePlan{
+			Description:    testCase.Description,
+			SchedulerNames: cs.scenarioConf.SchedulerNames,
+			AppInfos:       []*framework.AppInfo{appInfo},
+			CreatePods:     true,
+		})
+	}
+	return plans, nil
+}
+
+// newAppInfo returns the app of which pods are created directly and replaced during the churn
+func (cs *ChurnScenario) newAppInfo(caseIndex int, testCase *ChurnCaseConfig) *framework.AppInfo {
+	return framework.NewAppInfo(cs.commonConf.Namespace, fmt.Sprintf("churn-case%d", caseIndex),
+		cs.commonConf.Queue, []*framework.RequestInfo{framework.NewRequestInfo(1, "",
+			testCase.RequestResources, testCase.LimitResources)},
+		cs.commonConf.PodTemplateSpec, cs.commonConf.PodSpec)
+}
+
+// runForScheduler runs the churn of a case for the specified scheduler and analyzes the result,
+// an error is returned if the scenario should stop.
+func (cs *ChurnScenario) runForScheduler(schedulerName string, testCase *ChurnCaseConfig,
+	appInfo *framework.AppInfo, verification *utils.Verification, filePathPrefix string,
+	maxWaitTime time.Duration) error {
+	podTemplateSpec, err := framework.NewPodTemplateSpec(schedulerName, appInfo, appInfo.RequestInfos[0])
+	if err != nil {
+		utils.Logger.Error("failed to build pod template", zap.Error(err))
+		verification.AddSubVerification("build pod template", err.Error(), utils.FAILED)
+		return err
+	}
+	runner := &churnRunner{
+		kubeClient:      cs.kubeClient,
+		appInfo:         appInfo,
+		podTemplateSpec: podTemplateSpec,
+		pods:            make(map[string]*churnPod),
+	}
+
+	// create target pods and wait for them to be scheduled
+	utils.Logger.Info("[Prepare] create target pods and wait for them to be scheduled",
+		zap.String("appID", appInfo.AppID), zap.Int("targetPods", testCase.TargetPods))
+	err = runner.createPods(testCase.TargetPods, -1)
+	if err == nil {
+		err = runner.waitForPodsToBeScheduled(maxWaitTime)
+	}
+	if err != nil {
+		utils.Logger.Error("failed to create target pods", zap.Error(err))
+		verification.AddSubVerification("create target pods", err.Error(), utils.FAILED)
+		return err
+	}
+
+	// replace pods every second during the churn
+	numReplacedPerSecond := int(math.Round(float64(testCase.TargetPods) * testCase.ReplacePercentage / 100))
+	utils.Logger.Info("[Testing] start churn", zap.Int("numReplacedPerSecond", numReplacedPerSecond),
+		zap.Int("durationSeconds", testCase.DurationSeconds))
+	ticks := make([]*churnTick, testCase.DurationSeconds)
+	churnStartTime := time.Now()
+	ticker := time.NewTicker(time.Second)
+	for second := 0; second < testCase.DurationSeconds; second++ {
+		tickStartTime := time.Now()
+		if ticks[second], err = runner.replacePods(second, numReplacedPerSecond); err != nil {
+			ticker.Stop()
+			utils.Logger.Error("failed to replace pods", zap.Error(err))
+			verification.AddSubVerification("replace pods", err.Error(), utils.FAILED)
+			return err
+		}
+		ticks[second].elapsed = time.Since(tickStartTime)
+		if ticks[second].elapsed > time.Second {
+			utils.Logger.Warn("replacing pods took more than a second, the churn falls behind",
+				zap.Int("second", second), zap.Duration("elapsed", ticks[second].elapsed))
+		}
+		<-ticker.C
+	}
+	ticker.Stop()
+	actualDuration := time.Since(churnStartTime)
+	if err = runner.waitForPodsToBeScheduled(maxWaitTime); err != nil {
+		utils.Logger.Error("failed to wait for pods to be scheduled after churn", zap.Error(err))
+		verification.AddSubVerification("wait for pods to be scheduled", err.Error(), utils.FAILED)
+		return err
+	}
+
+	if err = cs.analyze(runner, ticks, actualDuration, verification, filePathPrefix); err != nil {
+		return err
+	}
+
+	// delete pods and wait for them to be cleaned up
+	utils.Logger.Info("[Cleanup] delete pods then wait for them to be cleaned up",
+		zap.String("appID", appInfo.AppID))
+	if err = DeletePodsWait(cs.kubeClient, appInfo.Namespace, appInfo.AppID, maxWaitTime); err != nil {
+		utils.Logger.Error("failed to delete/wait pods", zap.Error(err))
+		verification.AddSubVerification("cleanup pods", err.Error(), utils.FAILED)
+		return err
+	}
+	return nil
+}
+
+// analyze records metrics and outputs scheduling latency and pending pods observed by the client over the churn
+func (cs *ChurnScenario) analyze(runner *churnRunner, ticks []*churnTick, actualDuration time.Duration,
+	verification *utils.Verification, filePathPrefix string) error {
+	latenciesPerSecond := make([][]float64, len(ticks))
+	var latencies []float64
+	for _, pod := range runner.pods {
+		if pod.createSecond < 0 || pod.scheduledTime.IsZero() {
+			continue
+		}
+		latency := pod.scheduledTime.Sub(pod.createTime).Seconds()
+		latenciesPerSecond[pod.createSecond] = append(latenciesPerSecond[pod.createSecond], latency)
+		latencies = append(latencies, latency)
+	}
+	var numReplaced, maxPending, sumPending, numOverrun int
+	p50Latencies := make([]float64, len(ticks))
+	p99Latencies := make([]float64, len(ticks))
+	pendings := make([]int, len(ticks))
+	data := make([][]string, len(ticks))
+	for second, tick := range ticks {
+		numReplaced += tick.numCreated
+		sumPending += tick.numPending
+		if tick.numPending > maxPending {
+			maxPending = tick.numPending
+		}
+		if tick.elapsed > time.Second {
+			numOverrun++
+		}
+		p50Latencies[second] = utils.Percentile(latenciesPerSecond[second], 50)
+		p99Latencies[second] = utils.Percentile(latenciesPerSecond[second], 99)
+		pendings[second] = tick.numPending
+		data[second] = []string{strconv.Itoa(second), strconv.Itoa(tick.numCreated),
+			strconv.Itoa(tick.numDeleted), strconv.Itoa(tick.numPending),
+			fmt.Sprintf("%.2f", tick.elapsed.Seconds()),
+			fmt.Sprintf("%.2f", p50Latencies[second]), fmt.Sprintf("%.2f", p99Latencies[second])}
+	}
+	var avgPending float64
+	if len(ticks) > 0 {
+		avgPending = float64(sumPending) / float64(len(ticks))
+	}
+	verification.AddMetric(MetricNumReplacedPods, float64(numReplaced), utils.UnitPods).
+		AddMetric(MetricMaxPendingPods, float64(maxPending), utils.UnitPods).
+		AddMetric(MetricAvgPendingPods, avgPending, utils.UnitPods).
+		AddMetric(MetricNumOverrunSeconds, float64(numOverrun), utils.UnitNone).
+		AddMetric(MetricActualDurationSeconds, actualDuration.Seconds(), utils.UnitSeconds)
+	AddSchedulingLatencyMetrics(verification, latencies)
+	utils.Logger.Info("[Analyze] churn",
+		zap.Int("numReplacedPods", numReplaced),
+		zap.Int("maxPendingPods", maxPending),
+		zap.Float64("avgPendingPods", avgPending),
+		zap.Int("numOverrunSeconds", numOverrun),
+		zap.Float64("actualDurationSeconds", actualDuration.Seconds()),
+		zap.Float64("p99SchedulingLatency", utils.Percentile(latencies, 99)))
+
+	table := &utils.Table{
+		Headers: []string{"second", "created", "deleted", "pending", "elapsed", "p50Latency", "p99Latency"},
+		Data:    data,
+	}
+	if err := OutputTable(verification, "output churn timeline table", table, filePathPrefix+"-timeline",
+		cs.tableFormats); err != nil {
+		return err
+	}
+	latencyChart := &utils.Chart{
+		Title:  "Scheduling Latency over Time",
+		XLabel: "Seconds",
+		YLabel: "Latency (s)",
+		Width:  constants.ChartWidth,
+		Height: constants.ChartHeight,
+		LinePoints: []interface{}{"p50", utils.GetPointsFromFloatSlice(p50Latencies),
+			"p99", utils.GetPointsFromFloatSlice(p99Latencies)},
+		FilePathPrefix: filePathPrefix + "-latency",
+		Formats:        cs.chartFormats,
+	}
+	if err := OutputChart(verification, "output latency chart", latencyChart); err != nil {
+		return err
+	}
+	pendingChart := &utils.Chart{
+		Title:          "Client-observed Pending Pods over Time",
+		XLabel:         "Seconds",
+		YLabel:         "Pending Pods",
+		Width:          constants.ChartWidth,
+		Height:         constants.ChartHeight,
+		LinePoints:     utils.GetLinePoints(map[string][]int{"pending": pendings}),
+		FilePathPrefix: filePathPrefix + "-pending",
+		Formats:        cs.chartFormats,
+	}
+	return OutputChart(verification, "output pending pods chart", pendingChart)
+}
+
+func (cr *churnRunner) createPods(num, second int) error {
+	for i := 0; i < num; i++ {
+		podName := fmt.Sprintf("%s-%d", cr.appInfo.AppID, cr.nextPodIndex)
+		cr.nextPodIndex++
+		pod := NewPodFromTemplate(cr.appInfo.Namespace, podName, &cr.podTemplateSpec)
+		if err := cr.kubeClient.CreatePod(cr.appInfo.Namespace, pod, 0); err != nil {
+			return err
+		}
+		cr.pods[podName] = &churnPod{createSecond: second}
+	}
+	return nil
+}
+
+// replacePods deletes random scheduled pods and creates the same number of new pods
+func (cr *churnRunner) replacePods(second, num int) (*churnTick, error) {
+	if err := cr.refresh(); err != nil {
+		return nil, err
+	}
+	tick := &churnTick{numPending: cr.numPending}
+	// #nosec G404 -- randomness is only used to pick pods to be replaced
+	for _, i := range rand.Perm(len(cr.scheduledPods)) {
+		if tick.numDeleted >= num {
+			break
+		}
+		if err := cr.kubeClient.DeletePod(cr.appInfo.Namespace, cr.scheduledPods[i]); err != nil {
+			return nil, err
+		}
+		tick.numDeleted++
+	}
+	if tick.numDeleted < num {
+		utils.Logger.Warn("not enough scheduled pods to be replaced", zap.Int("second", second),
+			zap.Int("expected", num), zap.Int("actual", tick.numDeleted))
+	}
+	if err := cr.createPods(tick.numDeleted, second); err != nil {
+		return nil, err
+	}
+	tick.numCreated = tick.numDeleted
+	return tick, nil
+}
+
+// refresh updates times of pods and the number of pending pods from the pods of this app
+func (cr *churnRunner) refresh() error {
+	podList, err := cr.kubeClient.GetPods(cr.appInfo.Namespace,
+		utils.GetListOptions(map[string]string{constants.LabelAppID: cr.appInfo.AppID}))
+	if err != nil {
+		return err
+	}
+	cr.scheduledPods = cr.scheduledPods[:0]
+	cr.numPending = 0
+	for _, pod := range podList.Items {
+		trackedPod, ok := cr.pods[pod.Name]
+		if !ok || pod.DeletionTimestamp != nil {
+			continue
+		}
+		trackedPod.createTime = pod.CreationTimestamp.Time
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == apiv1.PodScheduled && cond.Status == apiv1.ConditionTrue {
+				trackedPod.scheduledTime = cond.LastTransitionTime.Time
+			}
+		}
+		if trackedPod.scheduledTime.IsZero() {
+			cr.numPending++
+		} else {
+			cr.scheduledPods = append(cr.scheduledPods, pod.Name)
+		}
+	}
+	return nil
+}
+
+func (cr *churnRunner) waitForPodsToBeScheduled(timeout time.Duration) error {
+	return framework.WaitForCondition(func() bool {
+		if err := cr.refresh(); err != nil {
+			utils.Logger.Info("failed to refresh pods", zap.Error(err))
+			return false
+		}
+		return cr.numPending == 0
+	}, 1*time.Second, timeout)
+}
@@ -20,21 +20,158 @@ package scenarios
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/mitchellh/mapstructure"
 	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
 
+	"github.com/apache/yunikorn-release/perf-tools/constants"
 	"github.com/apache/yunikorn-release/perf-tools/framework"
 	"github.com/apache/yunikorn-release/perf-tools/utils"
 )
 
+const (
+	MetricTotalSeconds = "totalSeconds"
+	MetricNumPods      = "numPods"
+	MetricAvgQPS       = "avgQPS"
+	MetricMaxQPS       = "maxQPS"
+
+	MetricNumScheduledNodes = "numScheduledNodes"
+
+	MetricP50SchedulingLatency = "p50SchedulingLatency"
+	MetricP99SchedulingLatency = "p99SchedulingLatency"
+	// difference between the most and least number of tasks on nodes
+	MetricTasksPerNodeSpread = "tasksPerNodeSpread"
+
+	// suffixes of fairness metrics, prefixed with what is measured, e.g. utilizationJainIndex
+	MetricSuffixJainIndex   = "JainIndex"
+	MetricSuffixGini        = "Gini"
+	MetricSuffixCV          = "CV"
+	MetricSuffixMaxMinRatio = "MaxMinRatio"
+	MetricUtilizationJain   = "utilization" + MetricSuffixJainIndex
+
+	// multi-resource metrics, fragmentation and stranded metrics are prefixed with the resource name
+	MetricAvgDominantShare    = "avgDominantShare"
+	MetricNumStrandedNodes    = "numStrandedNodes"
+	MetricSuffixFragmentation = "Fragmentation"
+	MetricSuffixStranded      = "Stranded"
+
+	// bin-packing metrics, fill ratio is the dominant share of a used node
+	MetricNumUsedNodes = "numUsedNodes"
+	MetricAvgFillRatio = "avgFillRatio"
+	MetricMinFillRatio = "minFillRatio"
+	MetricProbePlaced  = "probePlaced"
+
+	// recovery metrics, durations are measured since the scheduler restart, the scheduler is ready once its
+	// healthcheck endpoint reports healthy
+	MetricSchedulerReadySeconds   = "schedulerReadySeconds"
+	MetricPendingScheduledSeconds = "pendingScheduledSeconds"
+	MetricNumDisturbedPods        = "numDisturbedPods"
+
+	// admission overhead metrics, latencies are measured on the client side for pod create calls
+	MetricP50CreateLatency  = "p50CreateLatency"
+	MetricP90CreateLatency  = "p90CreateLatency"
+	MetricP99CreateLatency  = "p99CreateLatency"
+	MetricMaxCreateLatency  = "maxCreateLatency"
+	MetricCreateQPS         = "createQPS"
+	MetricNumCreateErrors   = "numCreateErrors"
+	MetricNumCreateTimeouts = "numCreateTimeouts"
+
+	// churn metrics, pending pods are pods observed by the client as not scheduled yet at the beginning of every
+	// second, which is not the backlog of pending asks inside the scheduler. The actual duration of the churn is
+	// longer than the configured one if replacing pods takes more than a second.
+	MetricNumReplacedPods       = "numReplacedPods"
+	MetricMaxPendingPods        = "maxPendingPods"
+	MetricAvgPendingPods        = "avgPendingPods"
+	MetricNumOverrunSeconds     = "numOverrunSeconds"
+	MetricActualDurationSeconds = "actualDurationSeconds"
+
+	// deletion metrics, latencies are measured since the deletion of the app started until pods are observed as
+	// removed by the client
+	MetricNumDeletedPods              = "numDeletedPods"
+	MetricAvgDeletionQPS              = "avgDeletionQPS"
+	MetricMaxDeletionQPS              = "maxDeletionQPS"
+	MetricP50DeletionLatency          = "p50DeletionLatency"
+	MetricP99DeletionLatency          = "p99DeletionLatency"
+	MetricP99TerminationLatency       = "p99TerminationLatency"
+	MetricHalfResourcesRemovedSeconds = "halfResourcesRemovedSeconds"
+	MetricAllResourcesRemovedSeconds  = "allResourcesRemovedSeconds"
+
+	// multi-apps metrics, completion is measured from the submission of an app to all of its pods running,
+	// inversion ratios are fractions of app pairs scheduled or completed in the reverse order of submission
+	MetricAppCompletionSeconds          = "appCompletionSeconds"
+	MetricAvgAppCompletionSeconds       = "avgAppCompletionSeconds"
+	MetricMaxAppCompletionSeconds       = "maxAppCompletionSeconds"
+	MetricSchedulingOrderInversionRatio = "schedulingOrderInversionRatio"
+	MetricCompletionOrderInversionRatio = "completionOrderInversionRatio"
+
+	// number of pods placed on nodes which violate their required scheduling constraints
+	MetricNumConstraintViolations = "numConstraintViolations"
+)
+
+// SchedulerComparedMetrics are the metrics compared side by side for different schedulers in a case
+var SchedulerComparedMetrics = []*utils.ComparedMetric{
+	{Name: MetricTotalSeconds, Unit: utils.UnitSeconds, LowerIsBetter: true},
+	{Name: MetricAvgQPS, Unit: utils.UnitQPS},
+	{Name: MetricMaxQPS, Unit: utils.UnitQPS},
+	{Name: MetricP99SchedulingLatency, Unit: utils.UnitSeconds, LowerIsBetter: true},
+	{Name: MetricTasksPerNodeSpread, Unit: utils.UnitPods, LowerIsBetter: true},
+	{Name: MetricUtilizationJain, Unit: utils.UnitNone},
+}
+
+// GroupComparedMetrics are the metrics compared side by side for different request groups of an app
+var GroupComparedMetrics = []*utils.ComparedMetric{
+	{Name: MetricNumPods, Unit: utils.UnitPods},
+	{Name: MetricTotalSeconds, Unit: utils.UnitSeconds, LowerIsBetter: true},
+	{Name: MetricAvgQPS, Unit: utils.UnitQPS},
+	{Name: MetricP50SchedulingLatency, Unit: utils.UnitSeconds, LowerIsBetter: true},
+	{Name: MetricP99SchedulingLatency, Unit: utils.UnitSeconds, LowerIsBetter: true},
+}
+
+// ChurnComparedMetrics are the metrics compared side by side for different schedulers in a churn case
+var ChurnComparedMetrics = []*utils.ComparedMetric{
+	{Name: MetricP50SchedulingLatency, Unit: utils.UnitSeconds, LowerIsBetter: true},
+	{Name: MetricP99SchedulingLatency, Unit: utils.UnitSeconds, LowerIsBetter: true},
+	{Name: MetricMaxPendingPods, Unit: utils.UnitPods, LowerIsBetter: true},
+	{Name: MetricAvgPendingPods, Unit: utils.UnitPods, LowerIsBetter: true},
+	{Name: MetricNumReplacedPods, Unit: utils.UnitPods},
+}
+
+// GetComparedMetrics returns compared metrics of all scenarios, which tell whether lower values of metrics are
+// better when comparing runs. The first one is returned for a metric compared by multiple scenarios.
+func GetComparedMetrics() []*utils.ComparedMetric {
+	var metrics []*utils.ComparedMetric
+	for _, comparedMetrics := range [][]*utils.ComparedMetric{
+		SchedulerComparedMetrics,
+		GroupComparedMetrics,
+		ChurnComparedMetrics,
+		AdmissionComparedMetrics,
+		ConstraintsComparedMetrics,
+		MultiAppsComparedMetrics,
+		getBinPackingComparedMetrics((&BinPackingCaseConfig{}).GetYKResourceNames()),
+	} {
+		metrics = append(metrics, comparedMetrics...)
+	}
+	return metrics
+}
+
 func LoadScenarioConf(conf *framework.Config, scenarioName string, scenarioConf interface{}) error {
 	rawScenarioConf := conf.Scenarios[scenarioName]
 	if rawScenarioConf == nil {
 		return fmt.Errorf("failed to load %s scenario config", scenarioName)
 	}
-	err := mapstructure.Decode(rawScenarioConf, scenarioConf)
+	// unknown keys are rejected rather than silently ignored
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		ErrorUnused: true,
+		Result:      scenarioConf,
+	})
+	if err == nil {
+		err = decoder.Decode(rawScenarioConf)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to parse %s scenario config: %s", scenarioName, err.Error())
 	}
@@ -43,6 +180,49 @@ func LoadScenarioConf(conf *framework.Config, scenarioName string, scenarioConf
 	return nil
 }
 
+// GetTableFormats returns the table formats configured for a scenario,
+// falls back to the common config if not configured for this scenario.
+func GetTableFormats(commonConf *framework.CommonConfig, scenarioTableFormats []string) ([]utils.TableFormat, error) {
+	if len(scenarioTableFormats) > 0 {
+		return utils.ParseTableFormats(scenarioTableFormats)
+	}
+	return utils.ParseTableFormats(commonConf.TableFormats)
+}
+
+// OutputTable writes the table in all specified formats and adds a sub-verification
+// with the written files as artifacts.
+func OutputTable(verification *utils.Verification, outputName string, table *utils.Table,
+	filePathPrefix string, formats []utils.TableFormat) error {
+	filePaths, err := table.OutputFormats(filePathPrefix, formats)
+	if err != nil {
+		verification.AddSubVerification(outputName,
+			fmt.Sprintf("failed to output %s: %s", outputName, err.Error()),
+			utils.FAILED)
+		return err
+	}
+	subVerification := verification.AddSubVerification(outputName, strings.Join(filePaths, ","), utils.SUCCEEDED)
+	for _, filePath := range filePaths {
+		subVerification.AddArtifact(outputName, utils.ArtifactTable, filePath)
+	}
+	return nil
+}
+
+// OutputChart draws the chart and adds a sub-verification with the written files as artifacts.
+func OutputChart(verification *utils.Verification, outputName string, chart *utils.Chart) error {
+	filePaths, err := utils.DrawChart(chart)
+	if err != nil {
+		verification.AddSubVerification(outputName,
+			fmt.Sprintf("failed to draw chart: %s", err.Error()),
+			utils.FAILED)
+		return err
+	}
+	subVerification := verification.AddSubVerification(outputName, strings.Join(filePaths, ","), utils.SUCCEEDED)
+	for _, filePath := range filePaths {
+		subVerification.AddArtifact(outputName, utils.ArtifactChart, filePath)
+	}
+	return nil
+}
+
 func CleanupApp(appManager framework.AppManager, appInfo *framework.AppInfo, maxWaitTime time.Duration) {
 	if appManager != nil && appInfo != nil {
 		utils.Logger.Info("make sure app is cleaned up", zap.Any("appID", appInfo.AppID))
@@ -52,21 +232,246 @@ func CleanupApp(appManager framework.AppManager, appInfo *framework.AppInfo, max
 	}
 }
 
+// ApplySchedulerConfig applies scheduler config overrides of a case, which are entries of the ConfigMap of
+// YuniKorn, and waits for the scheduler to reload them. A function restoring the original config is returned,
+// only the first call of it takes effect. Nothing is done if there is no override.
+func ApplySchedulerConfig(kubeClient *utils.KubeClient, commonConf *framework.CommonConfig,
+	schedulerConfig SchedulerConfigOverrides, verification *utils.Verification) (func(), error) {
+	if len(schedulerConfig) == 0 {
+		return func() {}, nil
+	}
+	keys := make([]string, 0, len(schedulerConfig))
+	for key := range schedulerConfig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	utils.Logger.Info("[Prepare] apply scheduler config", zap.Strings("keys", keys))
+	configManager := framework.NewSchedulerConfigManager(kubeClient, commonConf.YuniKorn)
+	original, err := configManager.Apply(schedulerConfig)
+	restored := false
+	restore := func() {
+		if restored || original == nil {
+			return
+		}
+		restored = true
+		utils.Logger.Info("[Cleanup] restore scheduler config", zap.Strings("keys", keys))
+		restoreErr := configManager.Restore(original)
+		if restoreErr != nil {
+			utils.Logger.Error("failed to restore scheduler config", zap.Error(restoreErr))
+		}
+		verification.AddErrorSubVerification(restoreErr, "restore scheduler config", "")
+	}
+	verification.AddErrorSubVerification(err, "apply scheduler config", fmt.Sprintf("keys=%v", keys))
+	if err != nil {
+		utils.Logger.Error("failed to apply scheduler config", zap.Error(err))
+		// the config map may have been updated
+		restore()
+		return func() {}, err
+	}
+	return restore, nil
+}
+
+// DeleteWaitAndAnalyze deletes the app and waits for it to be cleaned up. If deletion analysis is enabled
+// in common config, pods are watched during the deletion and deletion metrics, a table of requested resources
+// of removed pods and a deletion throughput chart are output onto a sub-verification group. Removal is observed
+// by the watch of the client, which doesn't imply the resources are already released by the scheduler.
+func DeleteWaitAndAnalyze(kubeClient *utils.KubeClient, commonConf *framework.CommonConfig,
+	appManager framework.AppManager, appInfo *framework.AppInfo, verification *utils.Verification,
+	filePathPrefix string, tableFormats []utils.TableFormat) error {
+	maxWaitTime := time.Duration(commonConf.MaxWaitSeconds) * time.Second
+	if !commonConf.AnalyzeDeletion {
+		return appManager.DeleteWait(appInfo, maxWaitTime)
+	}
+	tracker := framework.NewDeletionTracker(kubeClient, appInfo)
+	if err := tracker.Start(); err != nil {
+		return fmt.Errorf("failed to watch pods: %s", err.Error())
+	}
+	defer tracker.Stop()
+	if err := appManager.DeleteWait(appInfo, maxWaitTime); err != nil {
+		return err
+	}
+	if err := tracker.WaitForPodsToBeDeleted(maxWaitTime); err != nil {
+		return err
+	}
+	stats := tracker.GetDeletionStats()
+	deletionVerification := verification.AddSubVerificationGroup("analyze deletion", appInfo.AppID)
+	var avgDeletionQPS float64
+	maxDeletionQPS := 0
+	for _, numDeleted := range stats.DeletedPerSecond {
+		if numDeleted > maxDeletionQPS {
+			maxDeletionQPS = numDeleted
+		}
+	}
+	if len(stats.DeletedPerSecond) > 0 {
+		avgDeletionQPS = float64(stats.NumPods) / float64(len(stats.DeletedPerSecond))
+	}
+	deletionVerification.AddMetric(MetricNumDeletedPods, float64(stats.NumPods), utils.UnitPods).
+		AddMetric(MetricAvgDeletionQPS, avgDeletionQPS, utils.UnitQPS).
+		AddMetric(MetricMaxDeletionQPS, float64(maxDeletionQPS), utils.UnitQPS).
+		AddMetric(MetricP50DeletionLatency, utils.Percentile(stats.DeletionLatencies, 50), utils.UnitSeconds).
+		AddMetric(MetricP99DeletionLatency, utils.Percentile(stats.DeletionLatencies, 99), utils.UnitSeconds).
+		AddMetric(MetricP99TerminationLatency, utils.Percentile(stats.TerminationLatencies, 99),
+			utils.UnitSeconds).
+		AddMetric(MetricHalfResourcesRemovedSeconds, stats.HalfResourcesRemovedSeconds, utils.UnitSeconds).
+		AddMetric(MetricAllResourcesRemovedSeconds, stats.AllResourcesRemovedSeconds, utils.UnitSeconds)
+	utils.Logger.Info("[Analyze] deletion", zap.String("appID", appInfo.AppID),
+		zap.Int("numDeletedPods", stats.NumPods),
+		zap.Float64("p99DeletionLatency", utils.Percentile(stats.DeletionLatencies, 99)),
+		zap.Float64("allResourcesRemovedSeconds", stats.AllResourcesRemovedSeconds))
+	if err := OutputTable(deletionVerification, "output deletion table", parseTableFromDeletionStats(stats),
+		filePathPrefix+"-deletion", tableFormats); err != nil {
+		return err
+	}
+	chartFormats, err := utils.ParseChartFormats(commonConf.ChartFormats)
+	if err != nil {
+		return err
+	}
+	chart := &utils.Chart{
+		Title:          "Deletion Throughput",
+		XLabel:         "Seconds",
+		YLabel:         "Number of Deleted Pods",
+		Width:          constants.ChartWidth,
+		Height:         constants.ChartHeight,
+		LinePoints:     utils.GetLinePoints(map[string][]int{"deleted": stats.DeletedPerSecond}),
+		FilePathPrefix: filePathPrefix + "-deletion",
+		Formats:        chartFormats,
+	}
+	return OutputChart(deletionVerification, "output deletion chart", chart)
+}
+
+func parseTableFromDeletionStats(stats *framework.DeletionStats) *utils.Table {
+	resourceNames := make([]string, 0, len(stats.RemovedResources))
+	for resourceName := range stats.RemovedResources {
+		resourceNames = append(resourceNames, resourceName)
+	}
+	sort.Strings(resourceNames)
+	headers := []string{"second", "deleted"}
+	for _, resourceName := range resourceNames {
+		headers = append(headers, "removed-"+resourceName)
+	}
+	data := make([][]string, len(stats.DeletedPerSecond))
+	for second, numDeleted := range stats.DeletedPerSecond {
+		row := []string{strconv.Itoa(second), strconv.Itoa(numDeleted)}
+		for _, resourceName := range resourceNames {
+			row = append(row, strconv.FormatInt(stats.RemovedResources[resourceName][second], 10))
+		}
+		data[second] = row
+	}
+	return &utils.Table{
+		Headers: headers,
+		Data:    data,
+	}
+}
+
+// NewPodFromTemplate returns a pod with the specified name created from a copy of the pod template
+func NewPodFromTemplate(namespace, name string, podTemplateSpec *apiv1.PodTemplateSpec) *apiv1.Pod {
+	pod := &apiv1.Pod{
+		ObjectMeta: *podTemplateSpec.ObjectMeta.DeepCopy(),
+		Spec:       *podTemplateSpec.Spec.DeepCopy(),
+	}
+	pod.Name = name
+	pod.Namespace = namespace
+	return pod
+}
+
+// DeletePodsWait deletes pods of the app which are created directly and waits for them to be cleaned up
+func DeletePodsWait(kubeClient *utils.KubeClient, namespace, appID string, timeout time.Duration) error {
+	listOptions := utils.GetListOptions(map[string]string{constants.LabelAppID: appID})
+	if err := kubeClient.DeletePods(namespace, listOptions); err != nil {
+		return fmt.Errorf("failed to delete pods: %s", err.Error())
+	}
+	return framework.WaitForCondition(func() bool {
+		podList, err := kubeClient.GetPods(namespace, listOptions)
+		return err == nil && len(podList.Items) == 0
+	}, 1*time.Second, timeout)
+}
+
+// SchedulerConfigOverrides are optional entries of the ConfigMap of YuniKorn (e.g. queues.yaml) applied before
+// a case and restored after it, see ApplySchedulerConfig. Cases with overrides can't run in parallel since they
+// share the same ConfigMap.
+type SchedulerConfigOverrides map[string]string
+
 type RequestConfig struct {
-	NumPods          int32
-	Repeat           int
+	// optional name of the request group, pods are labeled with it so that results can be broken down by group
+	Name             string
+	NumPods          int32 `validate:"required,positive"`
+	Repeat           int   `validate:"required,positive"`
 	PriorityClass    string
-	RequestResources map[string]string
-	LimitResources   map[string]string
+	RequestResources map[string]string `validate:"quantity"`
+	LimitResources   map[string]string `validate:"quantity"`
+	// optional pod-level scheduling constraints, override those in the pod spec of common config
+	Affinity                  *apiv1.Affinity
+	TopologySpreadConstraints []apiv1.TopologySpreadConstraint
+	RuntimeClassName          string
+	// optional pod-level settings of the request group, override those in the pod spec of common config
+	Image        string
+	Command      []string
+	Labels       map[string]string
+	Annotations  map[string]string
+	Queue        string
+	NodeSelector map[string]string
+	Tolerations  []apiv1.Toleration
 }
 
 func ConvertToRequestInfos(requestConfigs []*RequestConfig) []*framework.RequestInfo {
 	requestInfos := make([]*framework.RequestInfo, 0)
 	for _, requestConfig := range requestConfigs {
 		for i := 0; i < requestConfig.Repeat; i++ {
-			requestInfos = append(requestInfos, framework.NewRequestInfo(requestConfig.NumPods,
-				requestConfig.PriorityClass, requestConfig.RequestResources, requestConfig.LimitResources))
+			requestInfo := framework.NewRequestInfo(requestConfig.NumPods, requestConfig.PriorityClass,
+				requestConfig.RequestResources, requestConfig.LimitResources)
+			requestInfo.Affinity = requestConfig.Affinity
+			requestInfo.TopologySpreadConstraints = requestConfig.TopologySpreadConstraints
+			requestInfo.RuntimeClassName = requestConfig.RuntimeClassName
+			requestInfo.Group = requestConfig.Name
+			requestInfo.Image = requestConfig.Image
+			requestInfo.Command = requestConfig.Command
+			requestInfo.Labels = requestConfig.Labels
+			requestInfo.Annotations = requestConfig.Annotations
+			requestInfo.Queue = requestConfig.Queue
+			requestInfo.NodeSelector = requestConfig.NodeSelector
+			requestInfo.Tolerations = requestConfig.Tolerations
+			requestInfos = append(requestInfos, requestInfo)
 		}
 	}
 	return requestInfos
 }
+
+// AddThroughputMetrics records number of pods, total seconds, average and max QPS
+// calculated from the per-second time distribution onto the specified verification
+func AddThroughputMetrics(verification *utils.Verification, timeDistribution []int) {
+	numPods := 0
+	maxQPS := 0
+	for _, num := range timeDistribution {
+		numPods += num
+		if num > maxQPS {
+			maxQPS = num
+		}
+	}
+	var avgQPS float64
+	if len(timeDistribution) > 0 {
+		avgQPS = float64(numPods) / float64(len(timeDistribution))
+	}
+	verification.AddMetric(MetricNumPods, float64(numPods), utils.UnitPods).
+		AddMetric(MetricTotalSeconds, float64(len(timeDistribution)), utils.UnitSeconds).
+		AddMetric(MetricAvgQPS, avgQPS, utils.UnitQPS).
+		AddMetric(MetricMaxQPS, float64(maxQPS), utils.UnitQPS)
+}
+
+// AddSchedulingLatencyMetrics records percentiles of scheduling latencies onto the specified verification
+func AddSchedulingLatencyMetrics(verification *utils.Verification, latencies []float64) {
+	verification.AddMetric(MetricP50SchedulingLatency, utils.Percentile(latencies, 50), utils.UnitSeconds).
+		AddMetric(MetricP99SchedulingLatency, utils.Percentile(latencies, 99), utils.UnitSeconds)
+}
+
+// OutputComparison outputs the side-by-side comparison of schedulers for a case,
+// nothing will be done if there are less than two schedulers to compare.
+func OutputComparison(verification *utils.Verification, comparison *utils.Comparison,
+	filePathPrefix string, formats []utils.TableFormat) error {
+	if len(comparison.Columns) < 2 {
+		return nil
+	}
+	table := comparison.ToTable()
+	utils.Logger.Info("[Analyze] scheduler comparison")
+	table.Print()
+	return OutputTable(verification, "scheduler comparison", table, filePathPrefix, formats)
+}
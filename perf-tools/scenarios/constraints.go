@@ -0,0 +1,256 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scenarios
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/apache/yunikorn-release/perf-tools/constants"
+	"github.com/apache/yunikorn-release/perf-tools/framework"
+	"github.com/apache/yunikorn-release/perf-tools/utils"
+)
+
+const (
+	ConstraintsScenarioName = "constraints"
+
+	// max number of violations shown in the description of the placement verification
+	maxShownViolations = 10
+)
+
+// ConstraintsComparedMetrics are the metrics compared side by side for constrained and unconstrained pods
+var ConstraintsComparedMetrics = []*utils.ComparedMetric{
+	{Name: MetricTotalSeconds, Unit: utils.UnitSeconds, LowerIsBetter: true},
+	{Name: MetricAvgQPS, Unit: utils.UnitQPS},
+	{Name: MetricP50SchedulingLatency, Unit: utils.UnitSeconds, LowerIsBetter: true},
+	{Name: MetricP99SchedulingLatency, Unit: utils.UnitSeconds, LowerIsBetter: true},
+	{Name: MetricNumConstraintViolations, Unit: utils.UnitPods, LowerIsBetter: true},
+}
+
+type ConstraintsScenario struct {
+	kubeClient   *utils.KubeClient
+	commonConf   *framework.CommonConfig
+	scenarioConf *ConstraintsScenarioConfig
+	tableFormats []utils.TableFormat
+}
+
+type ConstraintsScenarioConfig struct {
+	SchedulerNames []string `validate:"required,scheduler"`
+	TableFormats   []string
+	Cases          []*ConstraintsCaseConfig `validate:"required"`
+}
+
+type ConstraintsCaseConfig struct {
+	Description string
+	// constrained requests, the same requests without affinity and topology spread constraints
+	// are tested as the baseline
+	RequestConfigs  []*RequestConfig `validate:"required"`
+	SchedulerConfig SchedulerConfigOverrides
+}
+
+func init() {
+	framework.Register(&ConstraintsScenario{})
+}
+
+func (cs *ConstraintsScenario) GetName() string {
+	return ConstraintsScenarioName
+}
+
+func (cs *ConstraintsScenario) NewScenarioConfig() interface{} {
+	return &ConstraintsScenarioConfig{}
+}
+
+func (cs *ConstraintsScenario) Init(kubeClient *utils.KubeClient, conf *framework.Config) error {
+	cs.kubeClient = kubeClient
+	cs.commonConf = conf.Common
+	cs.scenarioConf = &ConstraintsScenarioConfig{}
+	if err := LoadScenarioConf(conf, cs.GetName(), cs.scenarioConf); err != nil {
+		return err
+	}
+	var err error
+	cs.tableFormats, err = GetTableFormats(cs.commonConf, cs.scenarioConf.TableFormats)
+	return err
+}
+
+func (cs *ConstraintsScenario) Run(results *utils.Results) {
+	scenarioResults := results.CreateScenarioResults(cs.GetName())
+	maxWaitTime := time.Duration(cs.commonConf.MaxWaitSeconds) * time.Second
+	var appManager framework.AppManager
+	var baselineAppInfo, constrainedAppInfo *framework.AppInfo
+	restoreSchedulerConfig := func() {}
+	// make sure apps are cleaned up when error occurred
+	defer func() {
+		CleanupApp(appManager, baselineAppInfo, maxWaitTime)
+		CleanupApp(appManager, constrainedAppInfo, maxWaitTime)
+		restoreSchedulerConfig()
+	}()
+
+	for caseIndex, testCase := range cs.scenarioConf.Cases {
+		verGroupName := fmt.Sprintf("Case-%d", caseIndex)
+		verGroupDescription := fmt.Sprintf("%+v", testCase.Description)
+		caseVerification := scenarioResults.AddVerificationGroup(verGroupName, verGroupDescription)
+		utils.Logger.Info("[Prepare] add verification group", zap.String("name", verGroupName),
+			zap.String("description", verGroupDescription))
+		var err error
+		if restoreSchedulerConfig, err = ApplySchedulerConfig(cs.kubeClient, cs.commonConf,
+			testCase.SchedulerConfig, caseVerification); err != nil {
+			return
+		}
+
+		// init app infos & app manager
+		baselineAppInfo, constrainedAppInfo = cs.newAppInfos(testCase)
+		appManager = framework.NewDeploymentsAppManager(cs.kubeClient)
+		comparison := utils.NewComparison(ConstraintsComparedMetrics)
+
+		// test unconstrained and constrained pods for different schedulers
+		for _, schedulerName := range cs.scenarioConf.SchedulerNames {
+			utils.Logger.Info("start testing for scheduler " + schedulerName)
+			schedulerVerification := caseVerification.AddSubVerificationGroup(
+				fmt.Sprintf("test for %s", schedulerName), verGroupDescription)
+			for _, appInfo := range []*framework.AppInfo{baselineAppInfo, constrainedAppInfo} {
+				appVerification := schedulerVerification.AddSubVerificationGroup(
+					fmt.Sprintf("test app %s", appInfo.AppID), "").Start()
+				filePathPrefix := fmt.Sprintf("%s/%s-case%d-%s-%s",
+					cs.commonConf.OutputPath, cs.GetName(), caseIndex, schedulerName, appInfo.AppID)
+				if err = cs.runApp(schedulerName, appManager, appInfo, appInfo == constrainedAppInfo,
+					appVerification, filePathPrefix, maxWaitTime); err != nil {
+					return
+				}
+				appVerification.Finish()
+				comparison.AddVerification(schedulerName+"/"+appInfo.AppID, appVerification)
+			}
+		}
+		if err = OutputComparison(caseVerification, comparison, fmt.Sprintf("%s/%s-case%d-comparison",
+			cs.commonConf.OutputPath, cs.GetName(), caseIndex), cs.tableFormats); err != nil {
+			return
+		}
+		restoreSchedulerConfig()
+	}
+}
+
+func (cs *ConstraintsScenario) Plan() ([]*framework.CasePlan, error) {
+	plans := make([]*framework.CasePlan, 0, len(cs.scenarioConf.Cases))
+	for _, testCase := range cs.scenarioConf.Cases {
+		baselineAppInfo, constrainedAppInfo := cs.newAppInfos(testCase)
+		plans = append(plans, &framework.CasePlan{
+			Description:    testCase.Description,
+			SchedulerNames: cs.scenarioConf.SchedulerNames,
+			AppInfos:       []*framework.AppInfo{baselineAppInfo, constrainedAppInfo},
+			Sequential:     true,
+		})
+	}
+	return plans, nil
+}
+
+// newAppInfos returns the baseline app without constraints and the constrained app
+func (cs *ConstraintsScenario) newAppInfos(testCase *ConstraintsCaseConfig) (*framework.AppInfo, *framework.AppInfo) {
+	baselineAppInfo := framework.NewAppInfo(cs.commonConf.Namespace, ConstraintsScenarioName+"-baseline",
+		cs.commonConf.Queue, ConvertToRequestInfos(getUnconstrainedRequestConfigs(testCase.RequestConfigs)),
+		cs.commonConf.PodTemplateSpec, cs.commonConf.PodSpec)
+	constrainedAppInfo := framework.NewAppInfo(cs.commonConf.Namespace, ConstraintsScenarioName,
+		cs.commonConf.Queue, ConvertToRequestInfos(testCase.RequestConfigs),
+		cs.commonConf.PodTemplateSpec, cs.commonConf.PodSpec)
+	return baselineAppInfo, constrainedAppInfo
+}
+
+// runApp runs the app for the specified scheduler, measures the scheduling cost and checks placement of pods
+// if they are constrained, an error is returned if the scenario should stop.
+func (cs *ConstraintsScenario) runApp(schedulerName string, appManager framework.AppManager,
+	appInfo *framework.AppInfo, constrained bool, verification *utils.Verification, filePathPrefix string,
+	maxWaitTime time.Duration) error {
+	// create app and wait for it to be running
+	utils.Logger.Info("[Testing] create an app and wait for it to be running, refresh tasks status at last",
+		zap.String("appID", appInfo.AppID), zap.Bool("constrained", constrained))
+	err := appManager.CreateWaitAndRefreshTasksStatus(schedulerName, appInfo, maxWaitTime)
+	if err != nil {
+		utils.Logger.Error("failed to create/wait/refresh app", zap.Error(err))
+		verification.AddSubVerification("test app", err.Error(), utils.FAILED)
+		return err
+	}
+	appAnalyzer := framework.NewAppAnalyzer(appInfo)
+	AddThroughputMetrics(verification, appAnalyzer.GetTimeDistribution(framework.PodScheduled))
+	AddSchedulingLatencyMetrics(verification, appAnalyzer.GetSchedulingLatencies())
+
+	// check placement of constrained pods
+	if constrained {
+		violations, err := cs.checkPlacement(appInfo)
+		if err != nil {
+			utils.Logger.Error("failed to check placement", zap.Error(err))
+			verification.AddSubVerification("check placement", err.Error(), utils.FAILED)
+			return err
+		}
+		verification.AddMetric(MetricNumConstraintViolations, float64(len(violations)), utils.UnitPods)
+		shownViolations := violations
+		if len(shownViolations) > maxShownViolations {
+			shownViolations = shownViolations[:maxShownViolations]
+		}
+		verification.AddAssertSubVerification(len(violations) == 0, "check placement",
+			fmt.Sprintf("numViolations=%d, violations=[%s]", len(violations), strings.Join(shownViolations, "; ")))
+		utils.Logger.Info("[Analyze] placement of constrained pods", zap.Int("numViolations", len(violations)))
+	}
+
+	// delete this app and wait for it to be cleaned up
+	utils.Logger.Info("[Cleanup] delete this app then wait for it to be cleaned up",
+		zap.String("appID", appInfo.AppID))
+	err = DeleteWaitAndAnalyze(cs.kubeClient, cs.commonConf, appManager, appInfo, verification,
+		filePathPrefix, cs.tableFormats)
+	if err != nil {
+		utils.Logger.Error("failed to delete/wait app", zap.Error(err))
+		verification.AddSubVerification("cleanup app", err.Error(), utils.FAILED)
+		return err
+	}
+	return nil
+}
+
+func (cs *ConstraintsScenario) checkPlacement(appInfo *framework.AppInfo) ([]string, error) {
+	podList, err := cs.kubeClient.GetPods(appInfo.Namespace,
+		utils.GetListOptions(map[string]string{constants.LabelAppID: appInfo.AppID}))
+	if err != nil {
+		return nil, err
+	}
+	nodeList, err := cs.kubeClient.GetNodes(utils.GetEverythingListOptions())
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*apiv1.Pod, len(podList.Items))
+	for i := range podList.Items {
+		pods[i] = &podList.Items[i]
+	}
+	nodeLabels := make(map[string]map[string]string, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		nodeLabels[node.Name] = node.Labels
+	}
+	return framework.CheckPlacement(pods, nodeLabels), nil
+}
+
+// getUnconstrainedRequestConfigs returns copies of request configs without affinity and topology spread constraints
+func getUnconstrainedRequestConfigs(requestConfigs []*RequestConfig) []*RequestConfig {
+	unconstrainedConfigs := make([]*RequestConfig, len(requestConfigs))
+	for i, requestConfig := range requestConfigs {
+		unconstrainedConfig := *requestConfig
+		unconstrainedConfig.Affinity = nil
+		unconstrainedConfig.TopologySpreadConstraints = nil
+		unconstrainedConfigs[i] = &unconstrainedConfig
+	}
+	return unconstrainedConfigs
+}
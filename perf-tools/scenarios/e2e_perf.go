@@ -27,6 +27,7 @@ import (
 	"github.com/TaoYang526/goutils/pkg/profiling"
 	"go.uber.org/zap"
 
+	"github.com/apache/yunikorn-release/perf-tools/constants"
 	"github.com/apache/yunikorn-release/perf-tools/framework"
 	"github.com/apache/yunikorn-release/perf-tools/utils"
 )
@@ -37,18 +38,32 @@ type E2EPerfScenario struct {
 	kubeClient   *utils.KubeClient
 	commonConf   *framework.CommonConfig
 	scenarioConf *E2EPerfScenarioConfig
+	tableFormats []utils.TableFormat
+	chartFormats []utils.ChartFormat
 }
 
 type E2EPerfScenarioConfig struct {
-	CleanUpDelayMs     int
-	ShowNumOfLastTasks int
-	Cases              []*E2EPerfCaseConfig
+	CleanUpDelayMs     int `validate:"nonnegative"`
+	ShowNumOfLastTasks int `validate:"nonnegative"`
+	TableFormats       []string
+	Cases              []*E2EPerfCaseConfig `validate:"required"`
 }
 
 type E2EPerfCaseConfig struct {
-	Description    string
-	SchedulerName  string
-	RequestConfigs []*RequestConfig
+	Description string
+	// SchedulerName is kept for compatibility, SchedulerNames takes precedence if both are configured
+	SchedulerName   string           `validate:"scheduler"`
+	SchedulerNames  []string         `validate:"scheduler"`
+	RequestConfigs  []*RequestConfig `validate:"required"`
+	SchedulerConfig SchedulerConfigOverrides
+}
+
+// GetSchedulerNames returns names of all schedulers to be tested for this case
+func (c *E2EPerfCaseConfig) GetSchedulerNames() []string {
+	if len(c.SchedulerNames) > 0 {
+		return c.SchedulerNames
+	}
+	return []string{c.SchedulerName}
 }
 
 func init() {
@@ -59,11 +74,24 @@ func (ts *E2EPerfScenario) GetName() string {
 	return E2EPerfScenarioName
 }
 
+func (ts *E2EPerfScenario) NewScenarioConfig() interface{} {
+	return &E2EPerfScenarioConfig{}
+}
+
 func (ts *E2EPerfScenario) Init(kubeClient *utils.KubeClient, conf *framework.Config) error {
 	ts.kubeClient = kubeClient
 	ts.commonConf = conf.Common
 	ts.scenarioConf = &E2EPerfScenarioConfig{}
-	return LoadScenarioConf(conf, ts.GetName(), ts.scenarioConf)
+	if err := LoadScenarioConf(conf, ts.GetName(), ts.scenarioConf); err != nil {
+		return err
+	}
+	var err error
+	ts.tableFormats, err = GetTableFormats(ts.commonConf, ts.scenarioConf.TableFormats)
+	if err != nil {
+		return err
+	}
+	ts.chartFormats, err = utils.ParseChartFormats(ts.commonConf.ChartFormats)
+	return err
 }
 
 func (eps *E2EPerfScenario) Run(results *utils.Results) {
@@ -71,9 +99,11 @@ func (eps *E2EPerfScenario) Run(results *utils.Results) {
 	maxWaitTime := time.Duration(eps.commonConf.MaxWaitSeconds) * time.Second
 	var appManager framework.AppManager
 	var appInfo *framework.AppInfo
+	restoreSchedulerConfig := func() {}
 	// make sure app is cleaned up when error occurred
 	defer func() {
 		CleanupApp(appManager, appInfo, maxWaitTime)
+		restoreSchedulerConfig()
 	}()
 
 	for caseIndex, testCase := range eps.scenarioConf.Cases {
@@ -84,128 +114,262 @@ func (eps *E2EPerfScenario) Run(results *utils.Results) {
 			zap.Int("caseIndex", caseIndex),
 			zap.String("name", verGroupName),
 			zap.String("description", verGroupDescription))
-		// init app info, app manager, app analyzer and node analyzer
-		requestInfos := ConvertToRequestInfos(testCase.RequestConfigs)
-		appInfo = framework.NewAppInfo(eps.commonConf.Namespace, E2EPerfScenarioName, eps.commonConf.Queue,
-			requestInfos, eps.commonConf.PodTemplateSpec, eps.commonConf.PodSpec)
+		var err error
+		if restoreSchedulerConfig, err = ApplySchedulerConfig(eps.kubeClient, eps.commonConf,
+			testCase.SchedulerConfig, caseVerification); err != nil {
+			return
+		}
+		// init app info & app manager
+		appInfo = eps.newAppInfo(testCase)
 		appManager = framework.NewDeploymentsAppManager(eps.kubeClient)
-		appAnalyzer := framework.NewAppAnalyzer(appInfo)
-		nodeAnalyzer := framework.NewNodeAnalyzer(eps.kubeClient, eps.commonConf.NodeSelector)
-
-		schedulerName := testCase.SchedulerName
+		comparison := utils.NewComparison(SchedulerComparedMetrics)
 
-		// prepare nodes
-		err := nodeAnalyzer.InitNodeInfosBeforeTesting()
-		if err != nil {
-			utils.Logger.Error("failed to init nodes", zap.Error(err))
-			caseVerification.AddSubVerification("init nodes", err.Error(), utils.FAILED)
-			return
+		// test for different schedulers
+		for _, schedulerName := range testCase.GetSchedulerNames() {
+			utils.Logger.Info("start testing for scheduler " + schedulerName)
+			schedulerVerification := caseVerification.AddSubVerificationGroup(
+				fmt.Sprintf("test for %s", schedulerName), verGroupDescription).Start()
+			filePathPrefix := fmt.Sprintf("%s/%s-case%d-%s",
+				eps.commonConf.OutputPath, eps.GetName(), caseIndex, schedulerName)
+			if err = eps.runForScheduler(schedulerName, appManager, appInfo, schedulerVerification,
+				filePathPrefix, maxWaitTime); err != nil {
+				return
+			}
+			schedulerVerification.Finish()
+			comparison.AddVerification(schedulerName, schedulerVerification)
 		}
-		utils.Logger.Info("[Prepare] init nodes", zap.Int("numNodes", len(nodeAnalyzer.GetAllocatableNodes())))
-
-		// create app and wait for it to be running
-		utils.Logger.Info("[Testing] create an app and wait for it to be running, refresh tasks status at last",
-			zap.String("appID", appInfo.AppID))
-		beginTime := time.Now().Truncate(time.Second)
-		err = appManager.CreateWaitAndRefreshTasksStatus(schedulerName, appInfo, maxWaitTime)
-		if err != nil {
-			utils.Logger.Error("failed to create/wait/refresh app", zap.Error(err))
-			caseVerification.AddSubVerification("test app", err.Error(), utils.FAILED)
+		if err = OutputComparison(caseVerification, comparison, fmt.Sprintf("%s/%s-case%d-comparison",
+			eps.commonConf.OutputPath, eps.GetName(), caseIndex), eps.tableFormats); err != nil {
 			return
 		}
-		utils.Logger.Info("all requirements of this app are satisfied",
-			zap.String("appID", appInfo.AppID),
-			zap.Duration("elapseTime", time.Since(beginTime)))
+		restoreSchedulerConfig()
+	}
+}
+
+func (eps *E2EPerfScenario) Plan() ([]*framework.CasePlan, error) {
+	plans := make([]*framework.CasePlan, 0, len(eps.scenarioConf.Cases))
+	for _, testCase := range eps.scenarioConf.Cases {
+		plans = append(plans, &framework.CasePlan{
+			Description:    testCase.Description,
+			SchedulerNames: testCase.GetSchedulerNames(),
+			AppInfos:       []*framework.AppInfo{eps.newAppInfo(testCase)},
+		})
+	}
+	return plans, nil
+}
+
+func (eps *E2EPerfScenario) newAppInfo(testCase *E2EPerfCaseConfig) *framework.AppInfo {
+	return framework.NewAppInfo(eps.commonConf.Namespace, E2EPerfScenarioName, eps.commonConf.Queue,
+		ConvertToRequestInfos(testCase.RequestConfigs), eps.commonConf.PodTemplateSpec, eps.commonConf.PodSpec)
+}
+
+// runForScheduler runs a case for the specified scheduler and analyzes the result,
+// an error is returned if the scenario should stop.
+func (eps *E2EPerfScenario) runForScheduler(schedulerName string, appManager framework.AppManager,
+	appInfo *framework.AppInfo, verification *utils.Verification, filePathPrefix string,
+	maxWaitTime time.Duration) error {
+	appAnalyzer := framework.NewAppAnalyzer(appInfo)
+	nodeAnalyzer := framework.NewNodeAnalyzer(eps.kubeClient, eps.commonConf.NodeSelector)
+
+	// prepare nodes
+	err := nodeAnalyzer.InitNodeInfosBeforeTesting()
+	if err != nil {
+		utils.Logger.Error("failed to init nodes", zap.Error(err))
+		verification.AddSubVerification("init nodes", err.Error(), utils.FAILED)
+		return err
+	}
+	utils.Logger.Info("[Prepare] init nodes", zap.Int("numNodes", len(nodeAnalyzer.GetAllocatableNodes())))
+
+	// create app and wait for it to be running
+	utils.Logger.Info("[Testing] create an app and wait for it to be running, refresh tasks status at last",
+		zap.String("appID", appInfo.AppID))
+	beginTime := time.Now().Truncate(time.Second)
+	err = appManager.CreateWaitAndRefreshTasksStatus(schedulerName, appInfo, maxWaitTime)
+	if err != nil {
+		utils.Logger.Error("failed to create/wait/refresh app", zap.Error(err))
+		verification.AddSubVerification("test app", err.Error(), utils.FAILED)
+		return err
+	}
+	utils.Logger.Info("all requirements of this app are satisfied",
+		zap.String("appID", appInfo.AppID),
+		zap.Duration("elapseTime", time.Since(beginTime)))
+
+	AddThroughputMetrics(verification, appAnalyzer.GetTimeDistribution(framework.PodScheduled))
+	AddSchedulingLatencyMetrics(verification, appAnalyzer.GetSchedulingLatencies())
+
+	// fulfill nodes info after testing
+	nodeAnalyzer.AnalyzeApp(appInfo)
+	scheduledNodes := nodeAnalyzer.GetScheduledNodes()
+	utils.Logger.Info("got related nodes", zap.Int("numScheduledNodes", len(scheduledNodes)))
+	verification.AddMetric(MetricNumScheduledNodes, float64(len(scheduledNodes)), utils.UnitNodes)
+
+	// analyze-1: print slow tasks (optional)
+	if eps.scenarioConf.ShowNumOfLastTasks > 0 {
+		logLastTasks(appAnalyzer.GetLastTasks(eps.scenarioConf.ShowNumOfLastTasks))
+	}
+	// analyze-2: print tasks distribution on nodes
+	tasksDistributionInfo := appAnalyzer.GetTasksDistributionInfo(scheduledNodes)
+	utils.Logger.Info("[Analyze] tasks distribution info on nodes",
+		zap.Int("LeastNum", tasksDistributionInfo.LeastNum),
+		zap.String("LeastNumNodeID", tasksDistributionInfo.LeastNumNodeID),
+		zap.Int("MostNum", tasksDistributionInfo.MostNum),
+		zap.String("MostNumNodeID", tasksDistributionInfo.MostNumNodeID),
+		zap.Float64("AvgNumPerNode", tasksDistributionInfo.AvgNum),
+		zap.Any("Fairness", tasksDistributionInfo.Fairness),
+		zap.Int("NumTasks", len(appInfo.TasksStatus)),
+		zap.Int("NumScheduledNodes", len(scheduledNodes)))
+	utils.Logger.Info("node with least number of tasks", zap.Any("summary",
+		tasksDistributionInfo.SortedNodeInfos[0].GetSummary()))
+	utils.Logger.Info("node with most number of tasks", zap.Any("summary",
+		tasksDistributionInfo.SortedNodeInfos[len(tasksDistributionInfo.SortedNodeInfos)-1].GetSummary()))
+	allNodesDistributionInfo := appAnalyzer.GetTasksDistributionInfo(nodeAnalyzer.GetAllocatableNodes())
+	verification.AddMetric(MetricTasksPerNodeSpread,
+		float64(allNodesDistributionInfo.MostNum-allNodesDistributionInfo.LeastNum), utils.UnitPods)
+
+	// profiling
+	if err = eps.outputProfilingTables(verification, appAnalyzer.GetTasksProfiling(), filePathPrefix); err != nil {
+		return err
+	}
 
-		// fulfill nodes info after testing
-		nodeAnalyzer.AnalyzeApp(appInfo)
-		scheduledNodes := nodeAnalyzer.GetScheduledNodes()
-		utils.Logger.Info("got related nodes", zap.Int("numScheduledNodes", len(scheduledNodes)))
+	// break down results by request groups
+	if err = eps.analyzeGroups(verification, appAnalyzer, filePathPrefix); err != nil {
+		return err
+	}
+
+	// visualize per-stage latency distributions
+	if err = eps.outputLatencyCharts(verification, filePathPrefix, appAnalyzer.GetStageLatencies()); err != nil {
+		return err
+	}
+
+	if eps.scenarioConf.CleanUpDelayMs > 0 {
+		utils.Logger.Info("wait for a while before cleaning up test apps",
+			zap.String("schedulerName", schedulerName),
+			zap.Any("cleanUpDelayMs", eps.scenarioConf.CleanUpDelayMs))
+		time.Sleep(time.Millisecond * time.Duration(eps.scenarioConf.CleanUpDelayMs))
+	}
+
+	// delete this app and wait for it to be cleaned up
+	utils.Logger.Info("[Cleanup] delete this app then wait for it to be cleaned up",
+		zap.String("appID", appInfo.AppID))
+	err = DeleteWaitAndAnalyze(eps.kubeClient, eps.commonConf, appManager, appInfo, verification,
+		filePathPrefix, eps.tableFormats)
+	if err != nil {
+		utils.Logger.Error("failed to delete/wait app", zap.Error(err))
+		verification.AddSubVerification("cleanup app", err.Error(), utils.FAILED)
+		return err
+	}
+	return nil
+}
 
-		// analyze-1: print slow tasks (optional)
+// analyzeGroups breaks down throughput, scheduling latencies, slow tasks and profiling by request groups
+// and outputs a comparison of all groups, nothing is done if there is only one group.
+func (eps *E2EPerfScenario) analyzeGroups(verification *utils.Verification, appAnalyzer *framework.AppAnalyzer,
+	filePathPrefix string) error {
+	groups := appAnalyzer.GetGroups()
+	if len(groups) < 2 {
+		return nil
+	}
+	comparison := utils.NewComparison(GroupComparedMetrics)
+	for _, group := range groups {
+		utils.Logger.Info("[Analyze] request group", zap.String("group", group))
+		groupAnalyzer := appAnalyzer.GetGroupAnalyzer(group)
+		groupVerification := verification.AddSubVerificationGroup("analyze group "+group, "")
+		AddThroughputMetrics(groupVerification, groupAnalyzer.GetTimeDistribution(framework.PodScheduled))
+		AddSchedulingLatencyMetrics(groupVerification, groupAnalyzer.GetSchedulingLatencies())
 		if eps.scenarioConf.ShowNumOfLastTasks > 0 {
-			slowTasksStatus := appAnalyzer.GetLastTasks(eps.scenarioConf.ShowNumOfLastTasks)
-			utils.Logger.Info(fmt.Sprintf("[Analyze] Show last %d tasks: ", len(slowTasksStatus)))
-			for _, task := range slowTasksStatus {
-				utils.Logger.Info("task status",
-					zap.String("taskID", task.TaskID),
-					zap.String("nodeID", task.NodeID),
-					zap.Duration("to-running-duration", task.RunningTime.Sub(task.CreateTime)),
-					zap.Time("createTime", task.CreateTime),
-					zap.Time("runningTime", task.RunningTime))
-			}
+			logLastTasks(groupAnalyzer.GetLastTasks(eps.scenarioConf.ShowNumOfLastTasks))
 		}
-		// analyze-2: print tasks distribution on nodes
-		tasksDistributionInfo := appAnalyzer.GetTasksDistributionInfo(scheduledNodes)
-		utils.Logger.Info("[Analyze] tasks distribution info on nodes",
-			zap.Int("LeastNum", tasksDistributionInfo.LeastNum),
-			zap.String("LeastNumNodeID", tasksDistributionInfo.LeastNumNodeID),
-			zap.Int("MostNum", tasksDistributionInfo.MostNum),
-			zap.String("MostNumNodeID", tasksDistributionInfo.MostNumNodeID),
-			zap.Float64("AvgNumPerNode", tasksDistributionInfo.AvgNum),
-			zap.Int("NumTasks", len(appInfo.TasksStatus)),
-			zap.Int("NumScheduledNodes", len(scheduledNodes)))
-		utils.Logger.Info("node with least number of tasks", zap.Any("summary",
-			tasksDistributionInfo.SortedNodeInfos[0].GetSummary()))
-		utils.Logger.Info("node with most number of tasks", zap.Any("summary",
-			tasksDistributionInfo.SortedNodeInfos[len(tasksDistributionInfo.SortedNodeInfos)-1].GetSummary()))
-
-		// profiling
-		prof := appAnalyzer.GetTasksProfiling()
-		if prof.GetCount() > 0 {
-			utils.Logger.Info("[Analyze] time statistics for pod conditions")
-			statsTableFilePath := fmt.Sprintf("%s/%s-case%d-timecost-stat.txt",
-				eps.commonConf.OutputPath, eps.GetName(), caseIndex)
-			statsOutputName := "time statistics"
-			stats := prof.GetTimeStatistics()
-			statsTable := ParseTableFromStatistic(stats)
-			if err = statsTable.Output(statsTableFilePath); err != nil {
-				caseVerification.AddSubVerification(statsOutputName,
-					fmt.Sprintf("failed to output %s: %s", statsOutputName, err.Error()),
-					utils.FAILED)
-				return
-			}
-			caseVerification.AddSubVerification(statsOutputName, statsTableFilePath, utils.SUCCEEDED)
-			statsTable.Print()
-			utils.Logger.Info("[Analyze] QPS statistics for pod conditions")
-			qpsStatsTableFilePath := fmt.Sprintf("%s/%s-case%d-qps-stat.txt",
-				eps.commonConf.OutputPath, eps.GetName(), caseIndex)
-			qpsStatsOutputName := "QPS statistics"
-			var qpsStat *profiling.QPSStatistics
-			qpsStat, err = prof.GetQPSStatistics()
-			if err != nil {
-				caseVerification.AddSubVerification(qpsStatsOutputName,
-					fmt.Sprintf("failed to output %s: %s", qpsStatsOutputName, err.Error()),
-					utils.FAILED)
-			}
-			qpsStatsTable := ParseTableFromQPSStatistics(qpsStat, framework.GetOrderedTaskConditionTypes())
-			if err = qpsStatsTable.Output(qpsStatsTableFilePath); err != nil {
-				caseVerification.AddSubVerification(qpsStatsOutputName,
-					fmt.Sprintf("failed to output %s: %s", qpsStatsOutputName, err.Error()),
-					utils.FAILED)
-				return
-			}
-			caseVerification.AddSubVerification(qpsStatsOutputName, qpsStatsTableFilePath, utils.SUCCEEDED)
-			qpsStatsTable.Print()
+		if err := eps.outputProfilingTables(groupVerification, groupAnalyzer.GetTasksProfiling(),
+			filePathPrefix+"-"+group); err != nil {
+			return err
 		}
+		comparison.AddVerification(group, groupVerification)
+	}
+	return OutputComparison(verification, comparison, filePathPrefix+"-groups-comparison", eps.tableFormats)
+}
 
-		if eps.scenarioConf.CleanUpDelayMs > 0 {
-			utils.Logger.Info("wait for a while before cleaning up test apps",
-				zap.String("schedulerName", schedulerName),
-				zap.Any("cleanUpDelayMs", eps.scenarioConf.CleanUpDelayMs))
-			time.Sleep(time.Millisecond * time.Duration(eps.scenarioConf.CleanUpDelayMs))
-		}
+// outputProfilingTables outputs time and QPS statistics of pod conditions
+func (eps *E2EPerfScenario) outputProfilingTables(verification *utils.Verification, prof profiling.Profiling,
+	filePathPrefix string) error {
+	if prof.GetCount() == 0 {
+		return nil
+	}
+	utils.Logger.Info("[Analyze] time statistics for pod conditions")
+	stats := prof.GetTimeStatistics()
+	statsTable := ParseTableFromStatistic(stats)
+	if err := OutputTable(verification, "time statistics", statsTable,
+		filePathPrefix+"-timecost-stat", eps.tableFormats); err != nil {
+		return err
+	}
+	statsTable.Print()
+	utils.Logger.Info("[Analyze] QPS statistics for pod conditions")
+	qpsStatsOutputName := "QPS statistics"
+	qpsStat, err := prof.GetQPSStatistics()
+	if err != nil {
+		verification.AddSubVerification(qpsStatsOutputName,
+			fmt.Sprintf("failed to output %s: %s", qpsStatsOutputName, err.Error()),
+			utils.FAILED)
+		return err
+	}
+	qpsStatsTable := ParseTableFromQPSStatistics(qpsStat, framework.GetOrderedTaskConditionTypes())
+	if err = OutputTable(verification, qpsStatsOutputName, qpsStatsTable,
+		filePathPrefix+"-qps-stat", eps.tableFormats); err != nil {
+		return err
+	}
+	qpsStatsTable.Print()
+	return nil
+}
 
-		// delete this app and wait for it to be cleaned up
-		utils.Logger.Info("[Cleanup] delete this app then wait for it to be cleaned up",
-			zap.String("appID", appInfo.AppID))
-		err = appManager.DeleteWait(appInfo, maxWaitTime)
-		if err != nil {
-			utils.Logger.Error("failed to delete/wait app", zap.Error(err))
-			caseVerification.AddSubVerification("cleanup app", err.Error(), utils.FAILED)
-			return
+func logLastTasks(tasks []*framework.TaskStatus) {
+	utils.Logger.Info(fmt.Sprintf("[Analyze] Show last %d tasks: ", len(tasks)))
+	for _, task := range tasks {
+		utils.Logger.Info("task status",
+			zap.String("taskID", task.TaskID),
+			zap.String("group", task.Group),
+			zap.String("nodeID", task.NodeID),
+			zap.Duration("to-running-duration", task.RunningTime.Sub(task.CreateTime)),
+			zap.Time("createTime", task.CreateTime),
+			zap.Time("runningTime", task.RunningTime))
+	}
+}
+
+func (eps *E2EPerfScenario) outputLatencyCharts(verification *utils.Verification, filePathPrefix string,
+	stageLatencies map[string][]float64) error {
+	charts := []*utils.Chart{
+		{
+			Kind:           utils.ChartKindHistogram,
+			Title:          "End-to-End Latency",
+			XLabel:         "Seconds",
+			YLabel:         "Number of Pods",
+			Values:         map[string][]float64{framework.EndToEndStageName: stageLatencies[framework.EndToEndStageName]},
+			FilePathPrefix: filePathPrefix + "-latency-histogram",
+		},
+		{
+			Kind:           utils.ChartKindCDF,
+			Title:          "Stage Latency CDF",
+			XLabel:         "Seconds",
+			YLabel:         "Fraction of Pods",
+			Values:         stageLatencies,
+			FilePathPrefix: filePathPrefix + "-latency-cdf",
+		},
+		{
+			Kind:           utils.ChartKindBoxPlot,
+			Title:          "Stage Latency",
+			XLabel:         "Stage",
+			YLabel:         "Seconds",
+			Values:         stageLatencies,
+			FilePathPrefix: filePathPrefix + "-latency-boxplot",
+		},
+	}
+	for _, chart := range charts {
+		chart.Width = constants.ChartWidth
+		chart.Height = constants.ChartHeight
+		chart.Formats = eps.chartFormats
+		if err := OutputChart(verification, "output "+strings.ToLower(chart.Title)+" chart", chart); err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
 func ParseTableFromStatistic(statistics *profiling.TimeStatistics) *utils.Table {
@@ -0,0 +1,418 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scenarios
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/yunikorn-release/perf-tools/constants"
+	"github.com/apache/yunikorn-release/perf-tools/framework"
+	"github.com/apache/yunikorn-release/perf-tools/utils"
+)
+
+const MultiAppsScenarioName = "multi_apps"
+
+// MultiAppsComparedMetrics are the metrics compared side by side for different schedulers in a multi-apps case
+var MultiAppsComparedMetrics = []*utils.ComparedMetric{
+	{Name: MetricTotalSeconds, Unit: utils.UnitSeconds, LowerIsBetter: true},
+	{Name: MetricAvgQPS, Unit: utils.UnitQPS},
+	{Name: MetricP99SchedulingLatency, Unit: utils.UnitSeconds, LowerIsBetter: true},
+	{Name: MetricAvgAppCompletionSeconds, Unit: utils.UnitSeconds, LowerIsBetter: true},
+	{Name: MetricMaxAppCompletionSeconds, Unit: utils.UnitSeconds, LowerIsBetter: true},
+	{Name: MetricSchedulingOrderInversionRatio, Unit: utils.UnitNone, LowerIsBetter: true},
+	{Name: MetricCompletionOrderInversionRatio, Unit: utils.UnitNone, LowerIsBetter: true},
+}
+
+type MultiAppsScenario struct {
+	kubeClient   *utils.KubeClient
+	commonConf   *framework.CommonConfig
+	scenarioConf *MultiAppsScenarioConfig
+	tableFormats []utils.TableFormat
+	chartFormats []utils.ChartFormat
+}
+
+type MultiAppsScenarioConfig struct {
+	SchedulerNames []string `validate:"required,scheduler"`
+	TableFormats   []string
+	Cases          []*MultiAppsCaseConfig `validate:"required"`
+}
+
+type MultiAppsCaseConfig struct {
+	Description     string
+	Apps            []*AppConfig `validate:"required"`
+	SchedulerConfig SchedulerConfigOverrides
+}
+
+type AppConfig struct {
+	// prefix of IDs of apps created from this config, defaults to the scenario name with the index of this config
+	Name string
+	// number of apps created from this config, defaults to 1
+	NumApps int `validate:"nonnegative"`
+	// queue of apps, defaults to the queue in common config
+	Queue string
+	// delay from the start of the case to the submission of the first app
+	SubmitOffsetMs int `validate:"nonnegative"`
+	// delay between submissions of adjacent apps created from this config
+	SubmitIntervalMs int              `validate:"nonnegative"`
+	RequestConfigs   []*RequestConfig `validate:"required"`
+}
+
+// appSubmission is an app to be submitted with the offset since the start of the case
+type appSubmission struct {
+	appInfo      *framework.AppInfo
+	submitOffset time.Duration
+	submitTime   time.Time
+}
+
+func init() {
+	framework.Register(&MultiAppsScenario{})
+}
+
+func (mas *MultiAppsScenario) GetName() string {
+	return MultiAppsScenarioName
+}
+
+func (mas *MultiAppsScenario) NewScenarioConfig() interface{} {
+	return &MultiAppsScenarioConfig{}
+}
+
+func (mas *MultiAppsScenario) Init(kubeClient *utils.KubeClient, conf *framework.Config) error {
+	mas.kubeClient = kubeClient
+	mas.commonConf = conf.Common
+	mas.scenarioConf = &MultiAppsScenarioConfig{}
+	if err := LoadScenarioConf(conf, mas.GetName(), mas.scenarioConf); err != nil {
+		return err
+	}
+	for caseIndex, testCase := range mas.scenarioConf.Cases {
+		if len(testCase.Apps) == 0 {
+			return fmt.Errorf("no apps configured for case %d of scenario %s", caseIndex, mas.GetName())
+		}
+		for appIndex, appConf := range testCase.Apps {
+			if appConf.Name == "" {
+				appConf.Name = fmt.Sprintf("%s-%d", mas.GetName(), appIndex)
+			}
+			if appConf.NumApps <= 0 {
+				appConf.NumApps = 1
+			}
+			if appConf.Queue == "" {
+				appConf.Queue = mas.commonConf.Queue
+			}
+		}
+	}
+	var err error
+	mas.tableFormats, err = GetTableFormats(mas.commonConf, mas.scenarioConf.TableFormats)
+	if err != nil {
+		return err
+	}
+	mas.chartFormats, err = utils.ParseChartFormats(mas.commonConf.ChartFormats)
+	return err
+}
+
+func (mas *MultiAppsScenario) Run(results *utils.Results) {
+	scenarioResults := results.CreateScenarioResults(mas.GetName())
+	maxWaitTime := time.Duration(mas.commonConf.MaxWaitSeconds) * time.Second
+	var appManager framework.AppManager
+	var submissions []*appSubmission
+	restoreSchedulerConfig := func() {}
+	// make sure apps are cleaned up when error occurred
+	defer func() {
+		for _, submission := range submissions {
+			CleanupApp(appManager, submission.appInfo, maxWaitTime)
+		}
+		restoreSchedulerConfig()
+	}()
+
+	for caseIndex, testCase := range mas.scenarioConf.Cases {
+		verGroupName := fmt.Sprintf("Case-%d", caseIndex)
+		verGroupDescription := fmt.Sprintf("%+v", testCase.Description)
+		caseVerification := scenarioResults.AddVerificationGroup(verGroupName, verGroupDescription)
+		utils.Logger.Info("[Prepare] add verification group", zap.String("name", verGroupName),
+			zap.String("description", verGroupDescription))
+		var err error
+		if restoreSchedulerConfig, err = ApplySchedulerConfig(mas.kubeClient, mas.commonConf,
+			testCase.SchedulerConfig, caseVerification); err != nil {
+			return
+		}
+		submissions = mas.getAppSubmissions(testCase)
+		appManager = framework.NewDeploymentsAppManager(mas.kubeClient)
+		comparison := utils.NewComparison(MultiAppsComparedMetrics)
+
+		// test for different schedulers
+		for _, schedulerName := range mas.scenarioConf.SchedulerNames {
+			utils.Logger.Info("start testing for scheduler " + schedulerName)
+			schedulerVerification := caseVerification.AddSubVerificationGroup(
+				fmt.Sprintf("test for %s", schedulerName), verGroupDescription).Start()
+			filePathPrefix := fmt.Sprintf("%s/%s-case%d-%s",
+				mas.commonConf.OutputPath, mas.GetName(), caseIndex, schedulerName)
+			if err = mas.runForScheduler(schedulerName, appManager, submissions, schedulerVerification,
+				filePathPrefix, maxWaitTime); err != nil {
+				return
+			}
+			schedulerVerification.Finish()
+			comparison.AddVerification(schedulerName, schedulerVerification)
+		}
+		if err = OutputComparison(caseVerification, comparison, fmt.Sprintf("%s/%s-case%d-comparison",
+			mas.commonConf.OutputPath, mas.GetName(), caseIndex), mas.tableFormats); err != nil {
+			return
+		}
+		restoreSchedulerConfig()
+	}
+}
+
+func (mas *MultiAppsScenario) Plan() ([]*framework.CasePlan, error) {
+	plans := make([]*framework.CasePlan, 0, len(mas.scenarioConf.Cases))
+	for _, testCase := range mas.scenarioConf.Cases {
+		submissions := mas.getAppSubmissions(testCase)
+		appInfos := make([]*framework.AppInfo, 0, len(submissions))
+		for _, submission := range submissions {
+			appInfos = append(appInfos, submission.appInfo)
+		}
+		plans = append(plans, &framework.CasePlan{
+			Description:    testCase.Description,
+			SchedulerNames: mas.scenarioConf.SchedulerNames,
+			AppInfos:       appInfos,
+		})
+	}
+	return plans, nil
+}
+
+// getAppSubmissions expands app configs of the case into apps ordered by their submit offsets
+func (mas *MultiAppsScenario) getAppSubmissions(testCase *MultiAppsCaseConfig) []*appSubmission {
+	var submissions []*appSubmission
+	for _, appConf := range testCase.Apps {
+		for i := 0; i < appConf.NumApps; i++ {
+			appID := appConf.Name
+			if appConf.NumApps > 1 {
+				appID = fmt.Sprintf("%s-%d", appConf.Name, i)
+			}
+			appInfo := framework.NewAppInfo(mas.commonConf.Namespace, appID, appConf.Queue,
+				ConvertToRequestInfos(appConf.RequestConfigs), mas.commonConf.PodTemplateSpec,
+				mas.commonConf.PodSpec)
+			submitOffsetMs := appConf.SubmitOffsetMs + i*appConf.SubmitIntervalMs
+			submissions = append(submissions, &appSubmission{
+				appInfo:      appInfo,
+				submitOffset: time.Duration(submitOffsetMs) * time.Millisecond,
+			})
+		}
+	}
+	return submissions
+}
+
+// runForScheduler submits all apps of a case for the specified scheduler and analyzes the result,
+// an error is returned if the scenario should stop.
+func (mas *MultiAppsScenario) runForScheduler(schedulerName string, appManager framework.AppManager,
+	submissions []*appSubmission, verification *utils.Verification, filePathPrefix string,
+	maxWaitTime time.Duration) error {
+	// submit apps concurrently and wait for all of them to be running
+	utils.Logger.Info("[Testing] submit apps and wait for them to be running, refresh tasks status at last",
+		zap.Int("numApps", len(submissions)))
+	caseStartTime, err := mas.submitApps(schedulerName, appManager, submissions, maxWaitTime)
+	if err != nil {
+		utils.Logger.Error("failed to create/wait/refresh apps", zap.Error(err))
+		verification.AddSubVerification("test apps", err.Error(), utils.FAILED)
+		return err
+	}
+	if err = mas.analyze(verification, submissions, caseStartTime, filePathPrefix); err != nil {
+		return err
+	}
+
+	// delete apps and wait for them to be cleaned up
+	for _, submission := range submissions {
+		appInfo := submission.appInfo
+		utils.Logger.Info("[Cleanup] delete this app then wait for it to be cleaned up",
+			zap.String("appID", appInfo.AppID))
+		err = DeleteWaitAndAnalyze(mas.kubeClient, mas.commonConf, appManager, appInfo, verification,
+			filePathPrefix+"-"+appInfo.AppID, mas.tableFormats)
+		if err != nil {
+			utils.Logger.Error("failed to delete/wait app", zap.Error(err))
+			verification.AddSubVerification("cleanup app "+appInfo.AppID, err.Error(), utils.FAILED)
+			return err
+		}
+	}
+	return nil
+}
+
+// submitApps creates every app at its submit offset since the start of the case, waits for all apps to be
+// running and refreshes their tasks status. The start time of the case is returned.
+func (mas *MultiAppsScenario) submitApps(schedulerName string, appManager framework.AppManager,
+	submissions []*appSubmission, maxWaitTime time.Duration) (time.Time, error) {
+	caseStartTime := time.Now()
+	errs := make([]error, len(submissions))
+	var wg sync.WaitGroup
+	for i, submission := range submissions {
+		wg.Add(1)
+		go func(i int, submission *appSubmission) {
+			defer wg.Done()
+			time.Sleep(time.Until(caseStartTime.Add(submission.submitOffset)))
+			submission.submitTime = time.Now()
+			utils.Logger.Info("submit app", zap.String("appID", submission.appInfo.AppID),
+				zap.String("queue", submission.appInfo.Queue), zap.Duration("submitOffset", submission.submitOffset))
+			errs[i] = appManager.CreateWaitAndRefreshTasksStatus(schedulerName, submission.appInfo, maxWaitTime)
+		}(i, submission)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return caseStartTime, fmt.Errorf("app %s: %s", submissions[i].appInfo.AppID, err.Error())
+		}
+	}
+	return caseStartTime, nil
+}
+
+// analyze records per-app metrics onto sub-verifications and aggregate metrics onto the verification, outputs
+// a table of per-app timings and a chart of scheduled pods of every app over time.
+func (mas *MultiAppsScenario) analyze(verification *utils.Verification, submissions []*appSubmission,
+	caseStartTime time.Time, filePathPrefix string) error {
+	appInfos := make([]*framework.AppInfo, len(submissions))
+	submitSeconds := make([]float64, len(submissions))
+	// apps are ordered by their configured offsets rather than actual submit times, which are subject to
+	// scheduling of goroutines, so that apps submitted at the same offset are ties
+	submitOffsetSeconds := make([]float64, len(submissions))
+	// offsets and first scheduled times of apps of which at least one task has been scheduled
+	var scheduledOffsetSeconds, firstScheduledSeconds []float64
+	finishSeconds := make([]float64, len(submissions))
+	completionSeconds := make([]float64, len(submissions))
+	scheduledPods := make(map[string][]int, len(submissions))
+	var tableData [][]string
+	for i, submission := range submissions {
+		appInfo := submission.appInfo
+		appInfos[i] = appInfo
+		appAnalyzer := framework.NewAppAnalyzer(appInfo)
+		latencies := appAnalyzer.GetSchedulingLatencies()
+		submitSeconds[i] = submission.submitTime.Sub(caseStartTime).Seconds()
+		submitOffsetSeconds[i] = submission.submitOffset.Seconds()
+		firstScheduled := "-"
+		if firstScheduledTime := getFirstScheduledTime(appInfo); !firstScheduledTime.IsZero() {
+			seconds := firstScheduledTime.Sub(caseStartTime).Seconds()
+			scheduledOffsetSeconds = append(scheduledOffsetSeconds, submitOffsetSeconds[i])
+			firstScheduledSeconds = append(firstScheduledSeconds, seconds)
+			firstScheduled = fmt.Sprintf("%.2f", seconds)
+		}
+		finishSeconds[i] = appInfo.AppStatus.RunningTime.Sub(caseStartTime).Seconds()
+		completionSeconds[i] = appInfo.AppStatus.RunningTime.Sub(submission.submitTime).Seconds()
+		scheduledPods[appInfo.AppID] = getCumulativeScheduledPods(appInfo, caseStartTime)
+
+		appVerification := verification.AddSubVerificationGroup("app "+appInfo.AppID, appInfo.Queue).
+			SetTimeRange(submission.submitTime, appInfo.AppStatus.RunningTime)
+		AddThroughputMetrics(appVerification, appAnalyzer.GetTimeDistribution(framework.PodScheduled))
+		AddSchedulingLatencyMetrics(appVerification, latencies)
+		appVerification.AddMetric(MetricAppCompletionSeconds, completionSeconds[i], utils.UnitSeconds)
+		tableData = append(tableData, []string{appInfo.AppID, appInfo.Queue,
+			fmt.Sprintf("%d", len(appInfo.TasksStatus)), fmt.Sprintf("%.2f", submitSeconds[i]),
+			firstScheduled, fmt.Sprintf("%.2f", finishSeconds[i]),
+			fmt.Sprintf("%.2f", completionSeconds[i]), fmt.Sprintf("%.2f", utils.Percentile(latencies, 50)),
+			fmt.Sprintf("%.2f", utils.Percentile(latencies, 99))})
+	}
+
+	// aggregate results of all apps
+	var sumCompletionSeconds float64
+	for _, seconds := range completionSeconds {
+		sumCompletionSeconds += seconds
+	}
+	aggregateAnalyzer := framework.NewAppAnalyzer(aggregateAppInfos(appInfos))
+	AddThroughputMetrics(verification, aggregateAnalyzer.GetTimeDistribution(framework.PodScheduled))
+	AddSchedulingLatencyMetrics(verification, aggregateAnalyzer.GetSchedulingLatencies())
+	verification.AddMetric(MetricAvgAppCompletionSeconds,
+		sumCompletionSeconds/float64(len(completionSeconds)), utils.UnitSeconds).
+		AddMetric(MetricMaxAppCompletionSeconds, utils.Percentile(completionSeconds, 100), utils.UnitSeconds).
+		AddMetric(MetricSchedulingOrderInversionRatio,
+			utils.CalculateInversionRatio(scheduledOffsetSeconds, firstScheduledSeconds), utils.UnitNone).
+		AddMetric(MetricCompletionOrderInversionRatio,
+			utils.CalculateInversionRatio(submitOffsetSeconds, finishSeconds), utils.UnitNone)
+	utils.Logger.Info("[Analyze] apps", zap.Int("numApps", len(submissions)),
+		zap.Any("metrics", verification.Metrics))
+
+	table := &utils.Table{
+		Headers: []string{"App", "Queue", "NumPods", "Submit(s)", "FirstScheduled(s)", "Finish(s)",
+			"Completion(s)", "P50SchedulingLatency(s)", "P99SchedulingLatency(s)"},
+		Data: tableData,
+	}
+	if err := OutputTable(verification, "apps", table, filePathPrefix+"-apps", mas.tableFormats); err != nil {
+		return err
+	}
+	table.Print()
+	chart := &utils.Chart{
+		Title:          "Scheduled Pods of Apps over Time",
+		XLabel:         "Seconds",
+		YLabel:         "Scheduled Pods",
+		Width:          constants.ChartWidth,
+		Height:         constants.ChartHeight,
+		LinePoints:     utils.GetLinePoints(scheduledPods),
+		FilePathPrefix: filePathPrefix + "-apps-scheduled",
+		Formats:        mas.chartFormats,
+	}
+	return OutputChart(verification, "output apps scheduled chart", chart)
+}
+
+// aggregateAppInfos returns an app info with tasks of all apps, spanning from the creation of the first app
+// to the time when the last app is running
+func aggregateAppInfos(appInfos []*framework.AppInfo) *framework.AppInfo {
+	aggregated := &framework.AppInfo{TasksStatus: make(map[string]*framework.TaskStatus)}
+	for _, appInfo := range appInfos {
+		for taskID, taskStatus := range appInfo.TasksStatus {
+			aggregated.TasksStatus[taskID] = taskStatus
+		}
+		if aggregated.AppStatus.CreateTime.IsZero() || appInfo.AppStatus.CreateTime.Before(
+			aggregated.AppStatus.CreateTime) {
+			aggregated.AppStatus.CreateTime = appInfo.AppStatus.CreateTime
+		}
+		if appInfo.AppStatus.RunningTime.After(aggregated.AppStatus.RunningTime) {
+			aggregated.AppStatus.RunningTime = appInfo.AppStatus.RunningTime
+		}
+	}
+	return aggregated
+}
+
+// getFirstScheduledTime returns the earliest time when a task of the app is scheduled
+func getFirstScheduledTime(appInfo *framework.AppInfo) time.Time {
+	var firstScheduledTime time.Time
+	for _, taskStatus := range appInfo.TasksStatus {
+		scheduledTime := taskStatus.GetTransitionTime(framework.PodScheduled)
+		if scheduledTime != nil && (firstScheduledTime.IsZero() || scheduledTime.Before(firstScheduledTime)) {
+			firstScheduledTime = *scheduledTime
+		}
+	}
+	return firstScheduledTime
+}
+
+// getCumulativeScheduledPods returns the number of scheduled pods of the app at the end of every second
+// since the start time
+func getCumulativeScheduledPods(appInfo *framework.AppInfo, startTime time.Time) []int {
+	var scheduledPods []int
+	for _, taskStatus := range appInfo.TasksStatus {
+		scheduledTime := taskStatus.GetTransitionTime(framework.PodScheduled)
+		if scheduledTime == nil {
+			continue
+		}
+		second := int(math.Max(0, math.Floor(scheduledTime.Sub(startTime).Seconds())))
+		for len(scheduledPods) <= second {
+			scheduledPods = append(scheduledPods, 0)
+		}
+		scheduledPods[second]++
+	}
+	for i := 1; i < len(scheduledPods); i++ {
+		scheduledPods[i] += scheduledPods[i-1]
+	}
+	return scheduledPods
+}
@@ -21,6 +21,7 @@ package scenarios
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -38,17 +39,48 @@ type NodeFairnessScenario struct {
 	kubeClient   *utils.KubeClient
 	commonConf   *framework.CommonConfig
 	scenarioConf *NodeFairnessScenarioConfig
+	tableFormats []utils.TableFormat
+	chartFormats []utils.ChartFormat
 }
 
 type NodeFairnessScenarioConfig struct {
-	SchedulerNames []string
-	Cases          []NodeFairnessCaseConfig
+	SchedulerNames []string `validate:"required,scheduler"`
+	TableFormats   []string
+	Cases          []NodeFairnessCaseConfig `validate:"required"`
 }
 
 type NodeFairnessCaseConfig struct {
-	NumPodsPerNode     int
-	AllocatePercentage int
-	ResourceName       string
+	NumPodsPerNode     int `validate:"required,positive"`
+	AllocatePercentage int `validate:"required,positive"`
+	// ResourceName is kept for compatibility, ResourceNames takes precedence if both are configured
+	ResourceName  string
+	ResourceNames []string
+	// utilization ratio at which a resource on a node is considered full, other free resources
+	// on that node are considered stranded, defaults to DefaultFullUtilizationThreshold
+	FullUtilizationThreshold float64 `validate:"nonnegative"`
+	// optional thresholds of fairness indices at the end of the test,
+	// utilization thresholds apply to the dominant share if there are multiple resources
+	UtilizationThresholds FairnessThresholds
+	TaskCountThresholds   FairnessThresholds
+	SchedulerConfig       SchedulerConfigOverrides
+}
+
+// FairnessThresholds defines expected fairness indices, zero values are not checked
+type FairnessThresholds struct {
+	MinJainIndex   float64
+	MaxGini        float64
+	MaxCV          float64
+	MaxMaxMinRatio float64
+}
+
+const DefaultFullUtilizationThreshold = 0.95
+
+// GetResourceNames returns names of all resources to be requested and analyzed for this case
+func (c *NodeFairnessCaseConfig) GetResourceNames() []string {
+	if len(c.ResourceNames) > 0 {
+		return c.ResourceNames
+	}
+	return []string{c.ResourceName}
 }
 
 func init() {
@@ -59,11 +91,24 @@ func (nfs *NodeFairnessScenario) GetName() string {
 	return NodeFairnessScenarioName
 }
 
+func (nfs *NodeFairnessScenario) NewScenarioConfig() interface{} {
+	return &NodeFairnessScenarioConfig{}
+}
+
 func (nfs *NodeFairnessScenario) Init(kubeClient *utils.KubeClient, conf *framework.Config) error {
 	nfs.kubeClient = kubeClient
 	nfs.commonConf = conf.Common
 	nfs.scenarioConf = &NodeFairnessScenarioConfig{}
-	return LoadScenarioConf(conf, nfs.GetName(), nfs.scenarioConf)
+	if err := LoadScenarioConf(conf, nfs.GetName(), nfs.scenarioConf); err != nil {
+		return err
+	}
+	var err error
+	nfs.tableFormats, err = GetTableFormats(nfs.commonConf, nfs.scenarioConf.TableFormats)
+	if err != nil {
+		return err
+	}
+	nfs.chartFormats, err = utils.ParseChartFormats(nfs.commonConf.ChartFormats)
+	return err
 }
 
 func (nfs *NodeFairnessScenario) Run(results *utils.Results) {
@@ -71,9 +116,11 @@ func (nfs *NodeFairnessScenario) Run(results *utils.Results) {
 	maxWaitTime := time.Duration(nfs.commonConf.MaxWaitSeconds) * time.Second
 	var appManager framework.AppManager
 	var appInfo *framework.AppInfo
+	restoreSchedulerConfig := func() {}
 	// make sure app is cleaned up when error occurred
 	defer func() {
 		CleanupApp(appManager, appInfo, maxWaitTime)
+		restoreSchedulerConfig()
 	}()
 
 	// init node analyzer and calculate allocated resource for nodes
@@ -93,125 +140,388 @@ func (nfs *NodeFairnessScenario) Run(results *utils.Results) {
 		caseVerification := scenarioResults.AddVerificationGroup(verGroupName, verGroupDescription)
 		utils.Logger.Info("add verification group", zap.String("name", verGroupName),
 			zap.String("description", verGroupDescription))
+		if restoreSchedulerConfig, err = ApplySchedulerConfig(nfs.kubeClient, nfs.commonConf,
+			testCase.SchedulerConfig, caseVerification); err != nil {
+			return
+		}
 
 		nodeAnalyzer.ClearApps()
-		totalAllocatableResource := nodeAnalyzer.GetTotalAllocatableResource()
-		var resourceUnit string
-		ykResourceName := testCase.ResourceName
-		if testCase.ResourceName == v1.ResourceMemory.String() {
-			resourceUnit = ""
-		} else if testCase.ResourceName == v1.ResourceCPU.String() {
-			resourceUnit = "m"
-			ykResourceName = siCommon.CPU
-		}
-		totalAllocatableResourceValue, ok := totalAllocatableResource.Resources[ykResourceName]
-		if !ok {
-			caseVerification.AddSubVerification("Unknown resource name",
-				fmt.Sprintf("resourceName=%s, totalAllocatableResource=%v",
-					ykResourceName, totalAllocatableResource), utils.FAILED)
+		// init app info with expected number of pods and resources of every pod & app manager
+		var ykResourceNames []string
+		appInfo, ykResourceNames, err = nfs.newAppInfo(&testCase, nodeAnalyzer)
+		if err != nil {
+			caseVerification.AddSubVerification("Unknown resource name", err.Error(), utils.FAILED)
 			return
 		}
-
-		// init expected number of pods
-		allocatableNodes := nodeAnalyzer.GetAllocatableNodes()
-		expectedNumPods := testCase.NumPodsPerNode * len(allocatableNodes)
-		expectedPodResource := int64(totalAllocatableResourceValue) * int64(testCase.AllocatePercentage) /
-			int64(expectedNumPods*100)
-		requestResources := make(map[string]string)
-		requestResources[testCase.ResourceName] = fmt.Sprintf("%d"+resourceUnit, expectedPodResource)
-		utils.Logger.Info("start testing",
-			zap.Int("numAllocatableNodes", len(allocatableNodes)),
-			zap.Int("expectedNumPods", expectedNumPods),
-			zap.Any("requestResources", requestResources))
-
-		// init app info & app manager
-		// #nosec G115 - This is a false positive, the input is controlled and safe
-		requestInfo := framework.NewRequestInfo(int32(expectedNumPods), "", requestResources, nil)
-		appInfo = framework.NewAppInfo(nfs.commonConf.Namespace, NodeFairnessScenarioName, nfs.commonConf.Queue,
-			[]*framework.RequestInfo{requestInfo}, nfs.commonConf.PodTemplateSpec, nfs.commonConf.PodSpec)
 		appManager = framework.NewDeploymentsAppManager(nfs.kubeClient)
-		appAnalyzer := framework.NewAppAnalyzer(appInfo)
+		comparison := utils.NewComparison(SchedulerComparedMetrics)
 
 		// test for different schedulers
 		for _, schedulerName := range nfs.scenarioConf.SchedulerNames {
 			utils.Logger.Info("start testing for scheduler " + schedulerName)
 			schedulerVerification := caseVerification.AddSubVerificationGroup(
-				fmt.Sprintf("test for %s", schedulerName), verGroupDescription)
-
-			// prepare nodes
-			nodeAnalyzer.ClearApps()
-			utils.Logger.Info("[Prepare] init nodes", zap.Int("numNodes", len(nodeAnalyzer.GetAllocatableNodes())))
-
-			// create app and wait for it to be running
-			utils.Logger.Info("create an app and wait for it to be running, refresh tasks status at last",
-				zap.String("appID", appInfo.AppID))
-			beginTime := time.Now().Truncate(time.Second)
-			err = appManager.CreateWaitAndRefreshTasksStatus(schedulerName, appInfo, maxWaitTime)
-			if err != nil {
-				utils.Logger.Error("failed to create/wait/refresh app", zap.Error(err))
-				schedulerVerification.AddSubVerification("test app", err.Error(), utils.FAILED)
-				return
-			}
-			utils.Logger.Info("all requirements of this app are satisfied", zap.String("appID", appInfo.AppID),
-				zap.Duration("elapseTime", time.Since(beginTime)))
-
-			// analyze
-			nodeDistribution := nodeAnalyzer.GetNodeResourceDistribution(
-				appAnalyzer.GetTasksDistribution(framework.PodScheduled), ykResourceName)
-
-			table := parseTableFromNodeDistribution(nodeDistribution)
-			tableFilePath := fmt.Sprintf("%s/%s-case%d-node-distribution.txt",
-				nfs.commonConf.OutputPath, nfs.GetName(), caseIndex)
-			tableOutputName := "output node distribution timeline table"
-			utils.Logger.Info(tableOutputName)
-			table.Print()
-			if err = table.Output(tableFilePath); err != nil {
-				schedulerVerification.AddSubVerification(tableOutputName,
-					fmt.Sprintf("failed to output node distribution timeline table: %s", err.Error()),
-					utils.FAILED)
+				fmt.Sprintf("test for %s", schedulerName), verGroupDescription).Start()
+			filePathPrefix := fmt.Sprintf("%s/%s-case%d-%s-%d-%d", nfs.commonConf.OutputPath, nfs.GetName(),
+				caseIndex, schedulerName, testCase.NumPodsPerNode, testCase.AllocatePercentage)
+			if err = nfs.runForScheduler(schedulerName, &testCase, ykResourceNames, appManager, appInfo,
+				nodeAnalyzer, schedulerVerification, filePathPrefix, maxWaitTime); err != nil {
 				return
 			}
-			schedulerVerification.AddSubVerification(tableOutputName, tableFilePath, utils.SUCCEEDED)
+			schedulerVerification.Finish()
+			comparison.AddVerification(schedulerName, schedulerVerification)
+		}
+		if err = OutputComparison(caseVerification, comparison, fmt.Sprintf("%s/%s-case%d-comparison",
+			nfs.commonConf.OutputPath, nfs.GetName(), caseIndex), nfs.tableFormats); err != nil {
+			return
+		}
+		restoreSchedulerConfig()
+	}
+}
 
-			// prepare line points
-			var linePoints []interface{}
-			for i, v := range nodeDistribution {
-				typeName := "bucket-" + strconv.Itoa(i)
-				linePoints = append(linePoints, typeName, utils.GetPointsFromSlice(v))
-			}
-			// draw chart
-			chartFileName := fmt.Sprintf("%s-case%d-%s-%d-%d", nfs.GetName(), caseIndex, schedulerName,
-				testCase.NumPodsPerNode, testCase.AllocatePercentage)
-			chart := &utils.Chart{
-				Title:      "Node Fairness",
-				XLabel:     "Seconds",
-				YLabel:     "Number of Nodes",
-				Width:      constants.ChartWidth,
-				Height:     constants.ChartHeight,
-				LinePoints: linePoints,
-				SvgFile:    nfs.commonConf.OutputPath + "/" + chartFileName + constants.ChartFileSuffix,
-			}
-			err = utils.DrawChart(chart)
-			outputName := "output node distribution timeline chart"
-			if err != nil {
-				schedulerVerification.AddSubVerification(outputName,
-					fmt.Sprintf("failed to draw chart: %s", err.Error()),
-					utils.FAILED)
-				return
-			}
-			schedulerVerification.AddSubVerification(outputName, chart.SvgFile, utils.SUCCEEDED)
-
-			// delete this app and wait for it to be cleaned up
-			utils.Logger.Info("delete this app then wait for it to be cleaned up",
-				zap.String("appID", appInfo.AppID))
-			err = appManager.DeleteWait(appInfo, maxWaitTime)
-			if err != nil {
-				utils.Logger.Error("failed to delete/wait app", zap.Error(err))
-				schedulerVerification.AddSubVerification("cleanup app", err.Error(), utils.FAILED)
-				return
-			}
+func (nfs *NodeFairnessScenario) Plan() ([]*framework.CasePlan, error) {
+	// pods are sized according to allocatable resources of the selected nodes, the same as Run
+	nodeAnalyzer := framework.NewNodeAnalyzer(nfs.kubeClient, nfs.commonConf.NodeSelector)
+	if err := nodeAnalyzer.InitNodeInfosBeforeTesting(); err != nil {
+		return nil, fmt.Errorf("failed to init nodes: %v", err)
+	}
+	nodeAnalyzer.CalculateAllocatedResource()
+	plans := make([]*framework.CasePlan, 0, len(nfs.scenarioConf.Cases))
+	for caseIndex := range nfs.scenarioConf.Cases {
+		testCase := &nfs.scenarioConf.Cases[caseIndex]
+		appInfo, _, err := nfs.newAppInfo(testCase, nodeAnalyzer)
+		if err != nil {
+			return nil, err
 		}
+		plans = append(plans, &framework.CasePlan{
+			Description:    fmt.Sprintf("%+v", *testCase),
+			SchedulerNames: nfs.scenarioConf.SchedulerNames,
+			AppInfos:       []*framework.AppInfo{appInfo},
+		})
 	}
+	return plans, nil
+}
+
+// newAppInfo returns the app of which pods allocate the configured percentage of total allocatable resources
+// of the nodes when they are evenly spread, together with YuniKorn names of the analyzed resources
+func (nfs *NodeFairnessScenario) newAppInfo(testCase *NodeFairnessCaseConfig,
+	nodeAnalyzer *framework.NodeAnalyzer) (*framework.AppInfo, []string, error) {
+	totalAllocatableResource := nodeAnalyzer.GetTotalAllocatableResource()
+	allocatableNodes := nodeAnalyzer.GetAllocatableNodes()
+	expectedNumPods := testCase.NumPodsPerNode * len(allocatableNodes)
+	requestResources := make(map[string]string)
+	resourceNames := testCase.GetResourceNames()
+	ykResourceNames := make([]string, len(resourceNames))
+	for i, resourceName := range resourceNames {
+		ykResourceName, resourceUnit := getYKResourceName(resourceName)
+		totalAllocatableResourceValue, ok := totalAllocatableResource.Resources[ykResourceName]
+		if !ok {
+			return nil, nil, fmt.Errorf("resourceName=%s, totalAllocatableResource=%v",
+				ykResourceName, totalAllocatableResource)
+		}
+		expectedPodResource := int64(totalAllocatableResourceValue) * int64(testCase.AllocatePercentage) /
+			int64(expectedNumPods*100)
+		requestResources[resourceName] = fmt.Sprintf("%d"+resourceUnit, expectedPodResource)
+		ykResourceNames[i] = ykResourceName
+	}
+	utils.Logger.Info("init app",
+		zap.Int("numAllocatableNodes", len(allocatableNodes)),
+		zap.Int("expectedNumPods", expectedNumPods),
+		zap.Any("requestResources", requestResources))
+	// #nosec G115 - This is a false positive, the input is controlled and safe
+	requestInfo := framework.NewRequestInfo(int32(expectedNumPods), "", requestResources, nil)
+	appInfo := framework.NewAppInfo(nfs.commonConf.Namespace, NodeFairnessScenarioName, nfs.commonConf.Queue,
+		[]*framework.RequestInfo{requestInfo}, nfs.commonConf.PodTemplateSpec, nfs.commonConf.PodSpec)
+	return appInfo, ykResourceNames, nil
+}
+
+// runForScheduler runs a case for the specified scheduler and analyzes the result,
+// an error is returned if the scenario should stop.
+func (nfs *NodeFairnessScenario) runForScheduler(schedulerName string, testCase *NodeFairnessCaseConfig,
+	ykResourceNames []string, appManager framework.AppManager, appInfo *framework.AppInfo,
+	nodeAnalyzer *framework.NodeAnalyzer, verification *utils.Verification, filePathPrefix string,
+	maxWaitTime time.Duration) error {
+	appAnalyzer := framework.NewAppAnalyzer(appInfo)
+	// prepare nodes
+	nodeAnalyzer.ClearApps()
+	utils.Logger.Info("[Prepare] init nodes", zap.Int("numNodes", len(nodeAnalyzer.GetAllocatableNodes())))
+
+	// create app and wait for it to be running
+	utils.Logger.Info("create an app and wait for it to be running, refresh tasks status at last",
+		zap.String("appID", appInfo.AppID))
+	beginTime := time.Now().Truncate(time.Second)
+	err := appManager.CreateWaitAndRefreshTasksStatus(schedulerName, appInfo, maxWaitTime)
+	if err != nil {
+		utils.Logger.Error("failed to create/wait/refresh app", zap.Error(err))
+		verification.AddSubVerification("test app", err.Error(), utils.FAILED)
+		return err
+	}
+	utils.Logger.Info("all requirements of this app are satisfied", zap.String("appID", appInfo.AppID),
+		zap.Duration("elapseTime", time.Since(beginTime)))
+	AddThroughputMetrics(verification, appAnalyzer.GetTimeDistribution(framework.PodScheduled))
+	AddSchedulingLatencyMetrics(verification, appAnalyzer.GetSchedulingLatencies())
+	nodeAnalyzer.AnalyzeApp(appInfo)
+	tasksDistributionInfo := appAnalyzer.GetTasksDistributionInfo(nodeAnalyzer.GetAllocatableNodes())
+	verification.AddMetric(MetricTasksPerNodeSpread,
+		float64(tasksDistributionInfo.MostNum-tasksDistributionInfo.LeastNum), utils.UnitPods)
+
+	// analyze distribution of every resource
+	tasksDistribution := appAnalyzer.GetTasksDistribution(framework.PodScheduled)
+	for _, ykResourceName := range ykResourceNames {
+		if err = nfs.analyzeResourceDistribution(verification, nodeAnalyzer, tasksDistribution, ykResourceName,
+			filePathPrefix+"-"+normalizeResourceName(ykResourceName)); err != nil {
+			return err
+		}
+	}
+
+	// analyze multiple resources together
+	if err = nfs.analyzeMultiResource(verification, testCase, nodeAnalyzer, ykResourceNames,
+		filePathPrefix+"-multi-resource"); err != nil {
+		return err
+	}
+
+	// analyze fairness indices over the timeline and at the end
+	if err = nfs.analyzeFairness(verification, testCase, nodeAnalyzer, tasksDistribution,
+		ykResourceNames, filePathPrefix+"-fairness"); err != nil {
+		return err
+	}
+
+	// delete this app and wait for it to be cleaned up
+	utils.Logger.Info("delete this app then wait for it to be cleaned up",
+		zap.String("appID", appInfo.AppID))
+	err = DeleteWaitAndAnalyze(nfs.kubeClient, nfs.commonConf, appManager, appInfo, verification,
+		filePathPrefix, nfs.tableFormats)
+	if err != nil {
+		utils.Logger.Error("failed to delete/wait app", zap.Error(err))
+		verification.AddSubVerification("cleanup app", err.Error(), utils.FAILED)
+		return err
+	}
+	return nil
+}
+
+func (nfs *NodeFairnessScenario) analyzeResourceDistribution(verification *utils.Verification,
+	nodeAnalyzer *framework.NodeAnalyzer, tasksDistribution [][]*framework.TaskStatus, ykResourceName string,
+	filePathPrefix string) error {
+	nodeDistribution := nodeAnalyzer.GetNodeResourceDistribution(tasksDistribution, ykResourceName)
+	table := parseTableFromNodeDistribution(nodeDistribution)
+	tableOutputName := "output node distribution timeline table of " + ykResourceName
+	utils.Logger.Info(tableOutputName)
+	table.Print()
+	if err := OutputTable(verification, tableOutputName, table,
+		filePathPrefix+"-node-distribution", nfs.tableFormats); err != nil {
+		return err
+	}
+
+	// prepare line points
+	var linePoints []interface{}
+	for i, v := range nodeDistribution {
+		typeName := "bucket-" + strconv.Itoa(i)
+		linePoints = append(linePoints, typeName, utils.GetPointsFromSlice(v))
+	}
+	// draw chart
+	chart := &utils.Chart{
+		Title:          "Node Fairness of " + ykResourceName,
+		XLabel:         "Seconds",
+		YLabel:         "Number of Nodes",
+		Width:          constants.ChartWidth,
+		Height:         constants.ChartHeight,
+		LinePoints:     linePoints,
+		FilePathPrefix: filePathPrefix,
+		Formats:        nfs.chartFormats,
+	}
+	if err := OutputChart(verification, "output node distribution timeline chart of "+ykResourceName,
+		chart); err != nil {
+		return err
+	}
+	// draw node x time utilization heatmap
+	nodeIDs, utilization := nodeAnalyzer.GetNodeUtilizationTimeline(tasksDistribution, ykResourceName)
+	heatmapChart := &utils.Chart{
+		Kind:   utils.ChartKindHeatmap,
+		Title:  "Node Utilization of " + ykResourceName,
+		XLabel: "Seconds",
+		YLabel: "Nodes",
+		Width:  constants.ChartWidth,
+		Height: constants.ChartHeight,
+		Heatmap: &utils.HeatmapData{
+			Values:    utilization,
+			RowLabels: nodeIDs,
+		},
+		FilePathPrefix: filePathPrefix + "-heatmap",
+		Formats:        nfs.chartFormats,
+	}
+	return OutputChart(verification, "output node utilization heatmap of "+ykResourceName, heatmapChart)
+}
+
+func (nfs *NodeFairnessScenario) analyzeMultiResource(verification *utils.Verification,
+	testCase *NodeFairnessCaseConfig, nodeAnalyzer *framework.NodeAnalyzer, ykResourceNames []string,
+	filePathPrefix string) error {
+	fullThreshold := testCase.FullUtilizationThreshold
+	if fullThreshold <= 0 {
+		fullThreshold = DefaultFullUtilizationThreshold
+	}
+	analysis := nodeAnalyzer.AnalyzeMultiResource(ykResourceNames, fullThreshold)
+	verification.AddMetric(MetricAvgDominantShare, analysis.GetAvgDominantShare(), utils.UnitNone).
+		AddMetric(MetricNumStrandedNodes, float64(analysis.NumStrandedNodes), utils.UnitNodes)
+	for _, ykResourceName := range ykResourceNames {
+		verification.AddMetric(ykResourceName+MetricSuffixFragmentation,
+			analysis.Fragmentation[ykResourceName], utils.UnitNone).
+			AddMetric(ykResourceName+MetricSuffixStranded,
+				float64(analysis.StrandedResources[ykResourceName]), utils.UnitNone)
+	}
+	utils.Logger.Info("[Analyze] multi-resource usage of nodes",
+		zap.Strings("resourceNames", ykResourceNames),
+		zap.Float64("avgDominantShare", analysis.GetAvgDominantShare()),
+		zap.Int("numStrandedNodes", analysis.NumStrandedNodes),
+		zap.Any("strandedResources", analysis.StrandedResources),
+		zap.Any("fragmentation", analysis.Fragmentation),
+		zap.Any("dominantShareFairness", analysis.DominantShareFairness))
+	return OutputTable(verification, "output multi-resource usage table", parseTableFromMultiResourceAnalysis(analysis),
+		filePathPrefix, nfs.tableFormats)
+}
+
+func (nfs *NodeFairnessScenario) analyzeFairness(verification *utils.Verification, testCase *NodeFairnessCaseConfig,
+	nodeAnalyzer *framework.NodeAnalyzer, tasksDistribution [][]*framework.TaskStatus, ykResourceNames []string,
+	filePathPrefix string) error {
+	_, utilization := nodeAnalyzer.GetNodeDominantShareTimeline(tasksDistribution, ykResourceNames)
+	_, numTasks := nodeAnalyzer.GetNodeTasksTimeline(tasksDistribution)
+	utilizationTimeline := utils.CalculateFairnessTimeline(utilization)
+	tasksTimeline := utils.CalculateFairnessTimeline(numTasks)
+	if len(utilizationTimeline) == 0 || len(tasksTimeline) == 0 {
+		verification.AddSubVerification("analyze fairness", "no scheduled tasks", utils.FAILED)
+		return fmt.Errorf("no scheduled tasks to analyze fairness")
+	}
+	utilizationFairness := utilizationTimeline[len(utilizationTimeline)-1]
+	tasksFairness := tasksTimeline[len(tasksTimeline)-1]
+	addFairnessMetrics(verification, "utilization", utilizationFairness)
+	addFairnessMetrics(verification, "tasks", tasksFairness)
+	utils.Logger.Info("[Analyze] fairness indices at the end",
+		zap.Any("utilization", utilizationFairness),
+		zap.Any("tasks", tasksFairness))
+
+	table := parseTableFromFairnessTimeline(utilizationTimeline, tasksTimeline)
+	if err := OutputTable(verification, "output fairness timeline table", table,
+		filePathPrefix, nfs.tableFormats); err != nil {
+		return err
+	}
+	jainIndices := make([]float64, len(utilizationTimeline))
+	ginis := make([]float64, len(utilizationTimeline))
+	cvs := make([]float64, len(utilizationTimeline))
+	for i, indices := range utilizationTimeline {
+		jainIndices[i] = indices.JainIndex
+		ginis[i] = indices.Gini
+		cvs[i] = indices.CV
+	}
+	chart := &utils.Chart{
+		Title:  "Fairness of Node Utilization",
+		XLabel: "Seconds",
+		YLabel: "Index",
+		Width:  constants.ChartWidth,
+		Height: constants.ChartHeight,
+		LinePoints: []interface{}{
+			"jain", utils.GetPointsFromFloatSlice(jainIndices),
+			"gini", utils.GetPointsFromFloatSlice(ginis),
+			"cv", utils.GetPointsFromFloatSlice(cvs),
+		},
+		FilePathPrefix: filePathPrefix,
+		Formats:        nfs.chartFormats,
+	}
+	if err := OutputChart(verification, "output fairness timeline chart", chart); err != nil {
+		return err
+	}
+
+	// check thresholds
+	if testCase.UtilizationThresholds != (FairnessThresholds{}) ||
+		testCase.TaskCountThresholds != (FairnessThresholds{}) {
+		thresholdsVerification := verification.AddSubVerificationGroup("check fairness thresholds", "")
+		checkFairnessThresholds(thresholdsVerification, "utilization", utilizationFairness,
+			testCase.UtilizationThresholds)
+		checkFairnessThresholds(thresholdsVerification, "tasks", tasksFairness, testCase.TaskCountThresholds)
+	}
+	return nil
+}
+
+func addFairnessMetrics(verification *utils.Verification, prefix string, indices utils.FairnessIndices) {
+	verification.AddMetric(prefix+MetricSuffixJainIndex, indices.JainIndex, utils.UnitNone).
+		AddMetric(prefix+MetricSuffixGini, indices.Gini, utils.UnitNone).
+		AddMetric(prefix+MetricSuffixCV, indices.CV, utils.UnitNone).
+		AddMetric(prefix+MetricSuffixMaxMinRatio, indices.MaxMinRatio, utils.UnitNone)
+}
+
+func checkFairnessThresholds(verification *utils.Verification, prefix string, indices utils.FairnessIndices,
+	thresholds FairnessThresholds) {
+	if thresholds.MinJainIndex > 0 {
+		verification.AddAssertSubVerification(indices.JainIndex >= thresholds.MinJainIndex,
+			fmt.Sprintf("%s%s >= %v", prefix, MetricSuffixJainIndex, thresholds.MinJainIndex),
+			fmt.Sprintf("actual: %v", indices.JainIndex))
+	}
+	if thresholds.MaxGini > 0 {
+		verification.AddAssertSubVerification(indices.Gini <= thresholds.MaxGini,
+			fmt.Sprintf("%s%s <= %v", prefix, MetricSuffixGini, thresholds.MaxGini),
+			fmt.Sprintf("actual: %v", indices.Gini))
+	}
+	if thresholds.MaxCV > 0 {
+		verification.AddAssertSubVerification(indices.CV <= thresholds.MaxCV,
+			fmt.Sprintf("%s%s <= %v", prefix, MetricSuffixCV, thresholds.MaxCV),
+			fmt.Sprintf("actual: %v", indices.CV))
+	}
+	if thresholds.MaxMaxMinRatio > 0 {
+		verification.AddAssertSubVerification(indices.MaxMinRatio <= thresholds.MaxMaxMinRatio,
+			fmt.Sprintf("%s%s <= %v", prefix, MetricSuffixMaxMinRatio, thresholds.MaxMaxMinRatio),
+			fmt.Sprintf("actual: %v", indices.MaxMinRatio))
+	}
+}
+
+func parseTableFromFairnessTimeline(utilizationTimeline, tasksTimeline []utils.FairnessIndices) *utils.Table {
+	data := make([][]string, len(utilizationTimeline))
+	for i := range utilizationTimeline {
+		row := []string{strconv.Itoa(i)}
+		for _, indices := range []utils.FairnessIndices{utilizationTimeline[i], tasksTimeline[i]} {
+			row = append(row, fmt.Sprintf("%.4f", indices.JainIndex), fmt.Sprintf("%.4f", indices.Gini),
+				fmt.Sprintf("%.4f", indices.CV), fmt.Sprintf("%.4f", indices.MaxMinRatio))
+		}
+		data[i] = row
+	}
+	return &utils.Table{
+		Headers: []string{"second",
+			"utilizationJain", "utilizationGini", "utilizationCV", "utilizationMaxMin",
+			"tasksJain", "tasksGini", "tasksCV", "tasksMaxMin"},
+		Data: data,
+	}
+}
+
+func parseTableFromMultiResourceAnalysis(analysis *framework.MultiResourceAnalysis) *utils.Table {
+	headers := []string{"node"}
+	for _, resourceName := range analysis.ResourceNames {
+		headers = append(headers, resourceName+"Utilization", resourceName+"Free")
+	}
+	headers = append(headers, "dominantResource", "dominantShare", "stranded")
+	data := make([][]string, len(analysis.Nodes))
+	for i, node := range analysis.Nodes {
+		row := []string{node.NodeID}
+		for _, resourceName := range analysis.ResourceNames {
+			row = append(row, fmt.Sprintf("%.4f", node.Utilization[resourceName]),
+				strconv.FormatInt(node.Free[resourceName], 10))
+		}
+		row = append(row, node.DominantResource, fmt.Sprintf("%.4f", node.DominantShare),
+			strconv.FormatBool(node.Stranded))
+		data[i] = row
+	}
+	return &utils.Table{
+		Headers: headers,
+		Data:    data,
+	}
+}
+
+// getYKResourceName returns the resource name used by YuniKorn and the unit of the quantity in requests
+func getYKResourceName(resourceName string) (string, string) {
+	if resourceName == v1.ResourceCPU.String() {
+		return siCommon.CPU, "m"
+	}
+	return resourceName, ""
+}
+
+// normalizeResourceName makes a resource name such as nvidia.com/gpu safe to be used in file names
+func normalizeResourceName(resourceName string) string {
+	return strings.NewReplacer("/", "-", ".", "-").Replace(resourceName)
 }
 
 func parseTableFromNodeDistribution(nodeDistribution [10][]int) *utils.Table {
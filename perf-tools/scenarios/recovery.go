@@ -0,0 +1,542 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scenarios
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/apache/yunikorn-release/perf-tools/constants"
+	"github.com/apache/yunikorn-release/perf-tools/framework"
+	"github.com/apache/yunikorn-release/perf-tools/utils"
+)
+
+const (
+	RecoveryScenarioName = "recovery"
+
+	RestartModeDelete  = "delete"
+	RestartModeRollout = "rollout"
+
+	DefaultRecoverySchedulerName        = "yunikorn"
+	DefaultSchedulerNamespace           = "yunikorn"
+	DefaultSchedulerDeploymentName      = "yunikorn-scheduler"
+	DefaultSchedulerReadyTimeoutSeconds = 300
+)
+
+var DefaultSchedulerPodLabels = map[string]string{"app": "yunikorn", "component": "yunikorn-scheduler"}
+
+type RecoveryScenario struct {
+	kubeClient   *utils.KubeClient
+	commonConf   *framework.CommonConfig
+	scenarioConf *RecoveryScenarioConfig
+	tableFormats []utils.TableFormat
+}
+
+type RecoveryScenarioConfig struct {
+	SchedulerName string `validate:"scheduler"`
+	// namespace, labels and deployment name used to locate the scheduler
+	SchedulerNamespace      string
+	SchedulerPodLabels      map[string]string
+	SchedulerDeploymentName string
+	// max seconds to wait for the scheduler to be ready after the restart
+	SchedulerReadyTimeoutSeconds int `validate:"nonnegative"`
+	TableFormats                 []string
+	Cases                        []*RecoveryCaseConfig `validate:"required"`
+}
+
+type RecoveryCaseConfig struct {
+	Description string
+	// "delete" (default) deletes the scheduler pods without the grace period, "rollout" triggers a rolling restart
+	// of the deployment. Pending pods are only submitted once the scheduler is down, which requires the Recreate
+	// strategy of the deployment in rollout mode.
+	RestartMode string
+	// pods to be running before the restart
+	LoadRequestConfigs []*RequestConfig `validate:"required"`
+	// optional pods to be submitted while the scheduler is down
+	PendingRequestConfigs []*RequestConfig
+	SchedulerConfig       SchedulerConfigOverrides
+}
+
+// podSnapshot keeps the states of a running pod which should not change during the restart
+type podSnapshot struct {
+	uid          string
+	nodeName     string
+	restartCount int32
+}
+
+func init() {
+	framework.Register(&RecoveryScenario{})
+}
+
+func (rs *RecoveryScenario) GetName() string {
+	return RecoveryScenarioName
+}
+
+// IsDisruptive returns true since the live scheduler is restarted, so that this scenario only runs when specified
+func (rs *RecoveryScenario) IsDisruptive() bool {
+	return true
+}
+
+func (rs *RecoveryScenario) NewScenarioConfig() interface{} {
+	return &RecoveryScenarioConfig{}
+}
+
+func (rs *RecoveryScenario) Init(kubeClient *utils.KubeClient, conf *framework.Config) error {
+	rs.kubeClient = kubeClient
+	rs.commonConf = conf.Common
+	rs.scenarioConf = &RecoveryScenarioConfig{}
+	if err := LoadScenarioConf(conf, rs.GetName(), rs.scenarioConf); err != nil {
+		return err
+	}
+	if rs.scenarioConf.SchedulerName == "" {
+		rs.scenarioConf.SchedulerName = DefaultRecoverySchedulerName
+	}
+	if rs.scenarioConf.SchedulerNamespace == "" {
+		rs.scenarioConf.SchedulerNamespace = DefaultSchedulerNamespace
+	}
+	if len(rs.scenarioConf.SchedulerPodLabels) == 0 {
+		rs.scenarioConf.SchedulerPodLabels = DefaultSchedulerPodLabels
+	}
+	if rs.scenarioConf.SchedulerDeploymentName == "" {
+		rs.scenarioConf.SchedulerDeploymentName = DefaultSchedulerDeploymentName
+	}
+	if rs.scenarioConf.SchedulerReadyTimeoutSeconds <= 0 {
+		rs.scenarioConf.SchedulerReadyTimeoutSeconds = DefaultSchedulerReadyTimeoutSeconds
+	}
+	for _, testCase := range rs.scenarioConf.Cases {
+		switch testCase.RestartMode {
+		case "":
+			testCase.RestartMode = RestartModeDelete
+		case RestartModeDelete, RestartModeRollout:
+		default:
+			return fmt.Errorf("unknown restart mode %s, expected %s or %s",
+				testCase.RestartMode, RestartModeDelete, RestartModeRollout)
+		}
+	}
+	var err error
+	rs.tableFormats, err = GetTableFormats(rs.commonConf, rs.scenarioConf.TableFormats)
+	return err
+}
+
+func (rs *RecoveryScenario) Run(results *utils.Results) {
+	scenarioResults := results.CreateScenarioResults(rs.GetName())
+	maxWaitTime := time.Duration(rs.commonConf.MaxWaitSeconds) * time.Second
+	var appManager framework.AppManager
+	var loadAppInfo, pendingAppInfo *framework.AppInfo
+	restoreSchedulerConfig := func() {}
+	// make sure apps are cleaned up when error occurred
+	defer func() {
+		CleanupApp(appManager, pendingAppInfo, maxWaitTime)
+		CleanupApp(appManager, loadAppInfo, maxWaitTime)
+		restoreSchedulerConfig()
+	}()
+
+	for caseIndex, testCase := range rs.scenarioConf.Cases {
+		verGroupName := fmt.Sprintf("Case-%d", caseIndex)
+		verGroupDescription := fmt.Sprintf("%+v", testCase.Description)
+		caseVerification := scenarioResults.AddVerificationGroup(verGroupName, verGroupDescription).Start()
+		utils.Logger.Info("[Prepare] add verification group", zap.String("name", verGroupName),
+			zap.String("description", verGroupDescription))
+		var err error
+		if restoreSchedulerConfig, err = ApplySchedulerConfig(rs.kubeClient, rs.commonConf,
+			testCase.SchedulerConfig, caseVerification); err != nil {
+			return
+		}
+
+		// init app infos & app manager
+		loadAppInfo, pendingAppInfo = rs.newAppInfos(testCase)
+		appManager = framework.NewDeploymentsAppManager(rs.kubeClient)
+		filePathPrefix := fmt.Sprintf("%s/%s-case%d", rs.commonConf.OutputPath, rs.GetName(), caseIndex)
+		if err = rs.runCase(testCase, appManager, loadAppInfo, pendingAppInfo, caseVerification,
+			filePathPrefix, maxWaitTime); err != nil {
+			return
+		}
+		caseVerification.Finish()
+		restoreSchedulerConfig()
+	}
+}
+
+func (rs *RecoveryScenario) Plan() ([]*framework.CasePlan, error) {
+	plans := make([]*framework.CasePlan, 0, len(rs.scenarioConf.Cases))
+	for _, testCase := range rs.scenarioConf.Cases {
+		loadAppInfo, pendingAppInfo := rs.newAppInfos(testCase)
+		appInfos := []*framework.AppInfo{loadAppInfo}
+		if pendingAppInfo != nil {
+			appInfos = append(appInfos, pendingAppInfo)
+		}
+		plans = append(plans, &framework.CasePlan{
+			Description:    testCase.Description,
+			SchedulerNames: []string{rs.scenarioConf.SchedulerName},
+			AppInfos:       appInfos,
+		})
+	}
+	return plans, nil
+}
+
+// newAppInfos returns the app running before the restart and the app submitted while the scheduler is down,
+// which is nil if there is no pending request
+func (rs *RecoveryScenario) newAppInfos(testCase *RecoveryCaseConfig) (*framework.AppInfo, *framework.AppInfo) {
+	loadAppInfo := framework.NewAppInfo(rs.commonConf.Namespace, RecoveryScenarioName+"-load",
+		rs.commonConf.Queue, ConvertToRequestInfos(testCase.LoadRequestConfigs),
+		rs.commonConf.PodTemplateSpec, rs.commonConf.PodSpec)
+	if len(testCase.PendingRequestConfigs) == 0 {
+		return loadAppInfo, nil
+	}
+	pendingAppInfo := framework.NewAppInfo(rs.commonConf.Namespace, RecoveryScenarioName+"-pending",
+		rs.commonConf.Queue, ConvertToRequestInfos(testCase.PendingRequestConfigs),
+		rs.commonConf.PodTemplateSpec, rs.commonConf.PodSpec)
+	return loadAppInfo, pendingAppInfo
+}
+
+// runCase loads the cluster, restarts the scheduler and analyzes the recovery,
+// an error is returned if the scenario should stop.
+func (rs *RecoveryScenario) runCase(testCase *RecoveryCaseConfig, appManager framework.AppManager,
+	loadAppInfo, pendingAppInfo *framework.AppInfo, verification *utils.Verification, filePathPrefix string,
+	maxWaitTime time.Duration) error {
+	// load the cluster and take a snapshot of running pods
+	utils.Logger.Info("[Prepare] create the load app and wait for it to be running",
+		zap.String("appID", loadAppInfo.AppID))
+	err := appManager.CreateWaitAndRefreshTasksStatus(rs.scenarioConf.SchedulerName, loadAppInfo, maxWaitTime)
+	if err != nil {
+		utils.Logger.Error("failed to create/wait/refresh load app", zap.Error(err))
+		verification.AddSubVerification("load cluster", err.Error(), utils.FAILED)
+		return err
+	}
+	snapshots, err := rs.getPodSnapshots(loadAppInfo)
+	if err != nil {
+		utils.Logger.Error("failed to get running pods", zap.Error(err))
+		verification.AddSubVerification("snapshot running pods", err.Error(), utils.FAILED)
+		return err
+	}
+
+	// restart the scheduler then submit pending pods while it is down
+	oldSchedulerPods, err := rs.getSchedulerPods()
+	if err != nil || len(oldSchedulerPods) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no scheduler pod found in namespace %s with labels %v",
+				rs.scenarioConf.SchedulerNamespace, rs.scenarioConf.SchedulerPodLabels)
+		}
+		utils.Logger.Error("failed to locate scheduler pods", zap.Error(err))
+		verification.AddSubVerification("locate scheduler", err.Error(), utils.FAILED)
+		return err
+	}
+	utils.Logger.Info("[Testing] restart the scheduler", zap.String("restartMode", testCase.RestartMode),
+		zap.Any("schedulerPods", oldSchedulerPods))
+	restartTime := time.Now()
+	if err = rs.restartScheduler(testCase.RestartMode, oldSchedulerPods); err != nil {
+		utils.Logger.Error("failed to restart scheduler", zap.Error(err))
+		verification.AddSubVerification("restart scheduler", err.Error(), utils.FAILED)
+		return err
+	}
+	if pendingAppInfo != nil {
+		if err = rs.submitPendingApp(appManager, pendingAppInfo, oldSchedulerPods, verification,
+			maxWaitTime); err != nil {
+			return err
+		}
+	}
+
+	// wait for the scheduler to be ready and pending pods to be scheduled
+	schedulerReadySeconds, err := rs.waitForSchedulerReady(oldSchedulerPods, restartTime)
+	if err != nil {
+		utils.Logger.Error("failed to wait for scheduler to be ready", zap.Error(err))
+		verification.AddSubVerification("wait for scheduler to be ready", err.Error(), utils.FAILED)
+		return err
+	}
+	verification.AddMetric(MetricSchedulerReadySeconds, schedulerReadySeconds, utils.UnitSeconds)
+	utils.Logger.Info("[Analyze] scheduler recovered", zap.Float64("schedulerReadySeconds", schedulerReadySeconds))
+	recoveryData := [][]string{{"scheduler ready", fmt.Sprintf("%.2f", schedulerReadySeconds)}}
+	if pendingAppInfo != nil {
+		var pendingScheduledSeconds float64
+		if pendingScheduledSeconds, err = rs.analyzePendingApp(appManager, pendingAppInfo, restartTime,
+			verification, maxWaitTime); err != nil {
+			return err
+		}
+		recoveryData = append(recoveryData,
+			[]string{"pending pods scheduled", fmt.Sprintf("%.2f", pendingScheduledSeconds)})
+	}
+
+	// verify that running pods were not disturbed
+	disturbedPods, err := rs.getDisturbedPods(loadAppInfo, snapshots)
+	if err != nil {
+		utils.Logger.Error("failed to check running pods", zap.Error(err))
+		verification.AddSubVerification("check running pods", err.Error(), utils.FAILED)
+		return err
+	}
+	verification.AddMetric(MetricNumDisturbedPods, float64(len(disturbedPods)), utils.UnitPods)
+	verification.AddAssertSubVerification(len(disturbedPods) == 0, "running pods are not disturbed",
+		fmt.Sprintf("numRunningPods=%d, disturbedPods=%v", len(snapshots), disturbedPods))
+
+	table := &utils.Table{
+		Headers: []string{"phase", "seconds"},
+		Data:    recoveryData,
+	}
+	if err = OutputTable(verification, "output recovery table", table, filePathPrefix+"-recovery",
+		rs.tableFormats); err != nil {
+		return err
+	}
+
+	// delete apps and wait for them to be cleaned up
+	utils.Logger.Info("[Cleanup] delete apps then wait for them to be cleaned up")
+	for _, appInfo := range []*framework.AppInfo{pendingAppInfo, loadAppInfo} {
+		if appInfo == nil {
+			continue
+		}
+		if err = DeleteWaitAndAnalyze(rs.kubeClient, rs.commonConf, appManager, appInfo, verification,
+			filePathPrefix+"-"+appInfo.AppID, rs.tableFormats); err != nil {
+			utils.Logger.Error("failed to delete/wait app", zap.Error(err))
+			verification.AddSubVerification("cleanup app", err.Error(), utils.FAILED)
+			return err
+		}
+	}
+	return nil
+}
+
+// analyzePendingApp waits for pods submitted while the scheduler was down to be running, and returns the seconds
+// since the restart until the last of them was scheduled
+func (rs *RecoveryScenario) analyzePendingApp(appManager framework.AppManager, pendingAppInfo *framework.AppInfo,
+	restartTime time.Time, verification *utils.Verification, maxWaitTime time.Duration) (float64, error) {
+	err := appManager.WaitForAppsToBeSatisfied(pendingAppInfo, maxWaitTime)
+	if err == nil {
+		err = appManager.RefreshTasksStatusAfterRunning(pendingAppInfo)
+	}
+	if err != nil {
+		utils.Logger.Error("failed to wait for pending pods to be running", zap.Error(err))
+		verification.AddSubVerification("wait for pending pods to be running", err.Error(), utils.FAILED)
+		return 0, err
+	}
+	var lastScheduledTime time.Time
+	for _, ts := range pendingAppInfo.TasksStatus {
+		if scheduledTime := ts.GetTransitionTime(framework.PodScheduled); scheduledTime != nil &&
+			scheduledTime.After(lastScheduledTime) {
+			lastScheduledTime = *scheduledTime
+		}
+	}
+	pendingScheduledSeconds := lastScheduledTime.Sub(restartTime).Seconds()
+	verification.AddMetric(MetricPendingScheduledSeconds, pendingScheduledSeconds, utils.UnitSeconds)
+	AddSchedulingLatencyMetrics(verification, framework.NewAppAnalyzer(pendingAppInfo).GetSchedulingLatencies())
+	utils.Logger.Info("[Analyze] pending pods scheduled",
+		zap.Float64("pendingScheduledSeconds", pendingScheduledSeconds))
+	return pendingScheduledSeconds, nil
+}
+
+func (rs *RecoveryScenario) getSchedulerPods() ([]string, error) {
+	podList, err := rs.kubeClient.GetPods(rs.scenarioConf.SchedulerNamespace,
+		utils.GetListOptions(rs.scenarioConf.SchedulerPodLabels))
+	if err != nil {
+		return nil, err
+	}
+	podNames := make([]string, len(podList.Items))
+	for i, pod := range podList.Items {
+		podNames[i] = pod.Name
+	}
+	return podNames, nil
+}
+
+// restartScheduler triggers a rolling restart of the deployment, or deletes the scheduler pods without
+// the grace period so that the old scheduler stops at once
+func (rs *RecoveryScenario) restartScheduler(restartMode string, schedulerPods []string) error {
+	if restartMode == RestartModeRollout {
+		return rs.kubeClient.RestartDeployment(rs.scenarioConf.SchedulerNamespace,
+			rs.scenarioConf.SchedulerDeploymentName)
+	}
+	for _, podName := range schedulerPods {
+		if err := rs.kubeClient.DeletePodImmediately(rs.scenarioConf.SchedulerNamespace, podName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// submitPendingApp waits for the scheduler to be down, submits the pending app and checks that none of its pods
+// has been scheduled once all of them are created, an error is returned if the scenario should stop.
+func (rs *RecoveryScenario) submitPendingApp(appManager framework.AppManager, pendingAppInfo *framework.AppInfo,
+	oldSchedulerPods []string, verification *utils.Verification, maxWaitTime time.Duration) error {
+	if err := rs.waitForSchedulerDown(oldSchedulerPods); err != nil {
+		utils.Logger.Error("failed to wait for scheduler to be down", zap.Error(err))
+		verification.AddSubVerification("wait for scheduler to be down", err.Error(), utils.FAILED)
+		return err
+	}
+	utils.Logger.Info("[Testing] submit pending pods while the scheduler is down",
+		zap.String("appID", pendingAppInfo.AppID))
+	if err := appManager.Create(rs.scenarioConf.SchedulerName, pendingAppInfo); err != nil {
+		utils.Logger.Error("failed to create pending app", zap.Error(err))
+		verification.AddSubVerification("submit pending pods", err.Error(), utils.FAILED)
+		return err
+	}
+	listOptions := utils.GetListOptions(map[string]string{constants.LabelAppID: pendingAppInfo.AppID})
+	var scheduledPods []string
+	var lastErr error
+	err := framework.WaitForCondition(func() bool {
+		podList, err := rs.kubeClient.GetPods(pendingAppInfo.Namespace, listOptions)
+		if err != nil {
+			lastErr = err
+			return false
+		}
+		scheduledPods = scheduledPods[:0]
+		for _, pod := range podList.Items {
+			for _, cond := range pod.Status.Conditions {
+				if cond.Type == apiv1.PodScheduled && cond.Status == apiv1.ConditionTrue {
+					scheduledPods = append(scheduledPods, pod.Name)
+				}
+			}
+		}
+		return len(podList.Items) >= int(pendingAppInfo.GetDesiredNumTasks())
+	}, 100*time.Millisecond, maxWaitTime)
+	if err == nil && len(scheduledPods) > 0 {
+		err = fmt.Errorf("%d pending pods were scheduled before the scheduler was down or all pods were "+
+			"submitted: %v", len(scheduledPods), scheduledPods)
+	} else if err != nil && lastErr != nil {
+		err = fmt.Errorf("failed to wait for pending pods to be created: %s", lastErr.Error())
+	}
+	verification.AddErrorSubVerification(err, "submit pending pods while the scheduler is down",
+		fmt.Sprintf("numPods=%d", pendingAppInfo.GetDesiredNumTasks()))
+	if err != nil {
+		utils.Logger.Error("failed to submit pending pods while the scheduler is down", zap.Error(err))
+	}
+	return err
+}
+
+// waitForSchedulerDown waits until none of the old scheduler pods exists and no scheduler pod is ready,
+// which never happens if a new scheduler pod is ready before old ones are removed (e.g. a rolling update
+// with surge)
+func (rs *RecoveryScenario) waitForSchedulerDown(oldSchedulerPods []string) error {
+	oldPods := make(map[string]bool, len(oldSchedulerPods))
+	for _, podName := range oldSchedulerPods {
+		oldPods[podName] = true
+	}
+	var upPods []string
+	err := framework.WaitForCondition(func() bool {
+		podList, err := rs.kubeClient.GetPods(rs.scenarioConf.SchedulerNamespace,
+			utils.GetListOptions(rs.scenarioConf.SchedulerPodLabels))
+		if err != nil {
+			utils.Logger.Info("failed to get scheduler pods", zap.Error(err))
+			return false
+		}
+		upPods = upPods[:0]
+		for _, pod := range podList.Items {
+			if oldPods[pod.Name] || isPodReady(&pod) {
+				upPods = append(upPods, pod.Name)
+			}
+		}
+		return len(upPods) == 0
+	}, 100*time.Millisecond, time.Duration(rs.scenarioConf.SchedulerReadyTimeoutSeconds)*time.Second)
+	if err != nil {
+		return fmt.Errorf("scheduler pods %v are still up, use restartMode %s or the Recreate strategy "+
+			"if new pods get ready before old ones are removed", upPods, RestartModeDelete)
+	}
+	return nil
+}
+
+// waitForSchedulerReady waits for the old scheduler pods to be gone and the new scheduler to report itself healthy
+// through its REST API, and returns the seconds since the restart. Pod readiness only means the REST API is up,
+// while the health checks of YuniKorn also cover the consistency of the recovered state.
+func (rs *RecoveryScenario) waitForSchedulerReady(oldSchedulerPods []string, restartTime time.Time) (float64,
+	error) {
+	oldPods := make(map[string]bool, len(oldSchedulerPods))
+	for _, podName := range oldSchedulerPods {
+		oldPods[podName] = true
+	}
+	configManager := framework.NewSchedulerConfigManager(rs.kubeClient, rs.commonConf.YuniKorn)
+	var readyTime time.Time
+	var lastErr error
+	err := framework.WaitForCondition(func() bool {
+		podList, err := rs.kubeClient.GetPods(rs.scenarioConf.SchedulerNamespace,
+			utils.GetListOptions(rs.scenarioConf.SchedulerPodLabels))
+		if err != nil {
+			lastErr = fmt.Errorf("failed to get scheduler pods: %s", err.Error())
+			return false
+		}
+		for _, pod := range podList.Items {
+			if oldPods[pod.Name] {
+				lastErr = fmt.Errorf("old scheduler pod %s still exists", pod.Name)
+				return false
+			}
+		}
+		healthy, failedChecks, err := configManager.CheckHealth()
+		if err != nil || !healthy {
+			if err == nil {
+				err = fmt.Errorf("scheduler is not healthy, failed health checks: %v", failedChecks)
+			}
+			lastErr = err
+			return false
+		}
+		readyTime = time.Now()
+		utils.Logger.Info("scheduler is healthy", zap.Time("readyTime", readyTime))
+		return true
+	}, 500*time.Millisecond, time.Duration(rs.scenarioConf.SchedulerReadyTimeoutSeconds)*time.Second)
+	if err != nil {
+		if lastErr != nil {
+			return 0, fmt.Errorf("%s: %s", err.Error(), lastErr.Error())
+		}
+		return 0, err
+	}
+	return readyTime.Sub(restartTime).Seconds(), nil
+}
+
+func (rs *RecoveryScenario) getPodSnapshots(appInfo *framework.AppInfo) (map[string]*podSnapshot, error) {
+	podList, err := rs.kubeClient.GetPods(appInfo.Namespace,
+		utils.GetListOptions(map[string]string{constants.LabelAppID: appInfo.AppID}))
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make(map[string]*podSnapshot, len(podList.Items))
+	for _, pod := range podList.Items {
+		snapshots[pod.Name] = getPodSnapshot(&pod)
+	}
+	return snapshots, nil
+}
+
+// getDisturbedPods returns names of pods which have been deleted, moved or restarted since the snapshot
+func (rs *RecoveryScenario) getDisturbedPods(appInfo *framework.AppInfo,
+	snapshots map[string]*podSnapshot) ([]string, error) {
+	currentSnapshots, err := rs.getPodSnapshots(appInfo)
+	if err != nil {
+		return nil, err
+	}
+	var disturbedPods []string
+	for podName, snapshot := range snapshots {
+		if current, ok := currentSnapshots[podName]; !ok || *current != *snapshot {
+			disturbedPods = append(disturbedPods, podName)
+		}
+	}
+	return disturbedPods, nil
+}
+
+func isPodReady(pod *apiv1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == apiv1.PodReady {
+			return cond.Status == apiv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func getPodSnapshot(pod *apiv1.Pod) *podSnapshot {
+	snapshot := &podSnapshot{
+		uid:      string(pod.UID),
+		nodeName: pod.Spec.NodeName,
+	}
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		snapshot.restartCount += containerStatus.RestartCount
+	}
+	return snapshot
+}
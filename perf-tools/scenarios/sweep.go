@@ -0,0 +1,185 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scenarios
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/apache/yunikorn-release/perf-tools/constants"
+	"github.com/apache/yunikorn-release/perf-tools/framework"
+	"github.com/apache/yunikorn-release/perf-tools/utils"
+)
+
+var fileNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// metricSweep holds values of a metric in expanded cases of a sweep, keyed by series names
+type metricSweep struct {
+	name    string
+	unit    string
+	xValues map[string][]string
+	yValues map[string][]float64
+}
+
+// OutputSweeps aggregates results of cases expanded from every sweep into a chart per metric, the X axis of
+// which is the first swept parameter. Series are sub-verifications of the cases (e.g. test for yunikorn)
+// combined with values of other parameters. Charts are added to a verification group of the scenario
+// named Sweep-<index>, sweeps of scenarios which haven't been run are skipped.
+func OutputSweeps(results *utils.Results, sweeps []*framework.Sweep, commonConf *framework.CommonConfig) error {
+	chartFormats, err := utils.ParseChartFormats(commonConf.ChartFormats)
+	if err != nil {
+		return err
+	}
+	sweepIndexes := make(map[string]int)
+	for _, sweep := range sweeps {
+		scenarioResult := results.GetScenarioResult(sweep.ScenarioName)
+		if scenarioResult == nil {
+			continue
+		}
+		sweepIndex := sweepIndexes[sweep.ScenarioName]
+		sweepIndexes[sweep.ScenarioName]++
+		description := "parameters: " + strings.Join(sweep.Parameters, ", ")
+		if sweep.Description != "" {
+			description = sweep.Description + ", " + description
+		}
+		sweepVerification := scenarioResult.AddVerificationGroup(fmt.Sprintf("Sweep-%d", sweepIndex), description)
+		for _, ms := range getMetricSweeps(scenarioResult, sweep) {
+			linePoints, xNames := ms.getLinePoints()
+			yLabel := ms.name
+			if ms.unit != "" {
+				yLabel += fmt.Sprintf(" (%s)", ms.unit)
+			}
+			chart := &utils.Chart{
+				Title:      fmt.Sprintf("%s by %s", ms.name, sweep.Parameters[0]),
+				XLabel:     sweep.Parameters[0],
+				YLabel:     yLabel,
+				Width:      constants.ChartWidth,
+				Height:     constants.ChartHeight,
+				LinePoints: linePoints,
+				XNames:     xNames,
+				FilePathPrefix: fmt.Sprintf("%s/%s-sweep%d-%s", commonConf.OutputPath, sweep.ScenarioName,
+					sweepIndex, fileNameRegexp.ReplaceAllString(ms.name, "-")),
+				Formats: chartFormats,
+			}
+			// failures are recorded in the verification, other charts are still drawn
+			_ = OutputChart(sweepVerification, "sweep chart of "+ms.name, chart)
+		}
+	}
+	return nil
+}
+
+// getMetricSweeps collects metrics of expanded cases of the sweep in order of metric names
+func getMetricSweeps(scenarioResult *utils.ScenarioResult, sweep *framework.Sweep) []*metricSweep {
+	metricSweeps := make(map[string]*metricSweep)
+	for _, sweepCase := range sweep.Cases {
+		caseVerification := scenarioResult.GetVerification(fmt.Sprintf("Case-%d", sweepCase.CaseIndex))
+		if caseVerification == nil {
+			continue
+		}
+		otherValues := ""
+		if len(sweep.Parameters) > 1 {
+			otherValues = framework.FormatSweepValues(sweep.Parameters[1:], sweepCase.Values[1:])
+		}
+		for path, metrics := range caseVerification.CollectMetrics() {
+			if path == "" {
+				path = "case"
+			}
+			seriesName := strings.TrimSpace(path + " " + otherValues)
+			for _, metric := range metrics {
+				ms := metricSweeps[metric.Name]
+				if ms == nil {
+					ms = &metricSweep{name: metric.Name, unit: metric.Unit, xValues: make(map[string][]string),
+						yValues: make(map[string][]float64)}
+					metricSweeps[metric.Name] = ms
+				}
+				ms.xValues[seriesName] = append(ms.xValues[seriesName], sweepCase.Values[0])
+				ms.yValues[seriesName] = append(ms.yValues[seriesName], metric.Value)
+			}
+		}
+	}
+	sorted := make([]*metricSweep, 0, len(metricSweeps))
+	for _, ms := range metricSweeps {
+		sorted = append(sorted, ms)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].name < sorted[j].name
+	})
+	return sorted
+}
+
+// getLinePoints returns series of points in order of series names, X values are numbers (including quantities
+// such as 100m) if all of them are numbers, otherwise they are indexes of the returned names of X values
+func (ms *metricSweep) getLinePoints() ([]interface{}, []string) {
+	seriesNames := make([]string, 0, len(ms.xValues))
+	for seriesName := range ms.xValues {
+		seriesNames = append(seriesNames, seriesName)
+	}
+	sort.Strings(seriesNames)
+	numbers := make(map[string]float64)
+	var xNames []string
+	for _, seriesName := range seriesNames {
+		for _, xValue := range ms.xValues[seriesName] {
+			if _, ok := numbers[xValue]; ok {
+				continue
+			}
+			numbers[xValue] = float64(len(xNames))
+			xNames = append(xNames, xValue)
+		}
+	}
+	numeric := true
+	for _, xName := range xNames {
+		if number, ok := parseNumber(xName); ok {
+			numbers[xName] = number
+		} else {
+			numeric = false
+		}
+	}
+	if !numeric {
+		for i, xName := range xNames {
+			numbers[xName] = float64(i)
+		}
+	}
+	linePoints := make([]interface{}, 0, 2*len(seriesNames))
+	for _, seriesName := range seriesNames {
+		xValues := make([]float64, len(ms.xValues[seriesName]))
+		for i, xValue := range ms.xValues[seriesName] {
+			xValues[i] = numbers[xValue]
+		}
+		linePoints = append(linePoints, seriesName, utils.GetXYPoints(xValues, ms.yValues[seriesName]))
+	}
+	if numeric {
+		return linePoints, nil
+	}
+	return linePoints, xNames
+}
+
+func parseNumber(value string) (float64, bool) {
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number, true
+	}
+	if quantity, err := resource.ParseQuantity(value); err == nil {
+		return quantity.AsApproximateFloat64(), true
+	}
+	return 0, false
+}
@@ -35,17 +35,21 @@ type ThroughputScenario struct {
 	kubeClient   *utils.KubeClient
 	commonConf   *framework.CommonConfig
 	scenarioConf *ThroughputScenarioConfig
+	tableFormats []utils.TableFormat
+	chartFormats []utils.ChartFormat
 }
 
 type ThroughputScenarioConfig struct {
-	CleanUpDelayMs int
-	SchedulerNames []string
-	Cases          []*ThroughputCaseConfig
+	CleanUpDelayMs int      `validate:"nonnegative"`
+	SchedulerNames []string `validate:"required,scheduler"`
+	TableFormats   []string
+	Cases          []*ThroughputCaseConfig `validate:"required"`
 }
 
 type ThroughputCaseConfig struct {
-	Description    string
-	RequestConfigs []*RequestConfig
+	Description     string
+	RequestConfigs  []*RequestConfig `validate:"required"`
+	SchedulerConfig SchedulerConfigOverrides
 }
 
 func init() {
@@ -56,11 +60,24 @@ func (ts *ThroughputScenario) GetName() string {
 	return ThroughputScenarioName
 }
 
+func (ts *ThroughputScenario) NewScenarioConfig() interface{} {
+	return &ThroughputScenarioConfig{}
+}
+
 func (ts *ThroughputScenario) Init(kubeClient *utils.KubeClient, conf *framework.Config) error {
 	ts.kubeClient = kubeClient
 	ts.commonConf = conf.Common
 	ts.scenarioConf = &ThroughputScenarioConfig{}
-	return LoadScenarioConf(conf, ts.GetName(), ts.scenarioConf)
+	if err := LoadScenarioConf(conf, ts.GetName(), ts.scenarioConf); err != nil {
+		return err
+	}
+	var err error
+	ts.tableFormats, err = GetTableFormats(ts.commonConf, ts.scenarioConf.TableFormats)
+	if err != nil {
+		return err
+	}
+	ts.chartFormats, err = utils.ParseChartFormats(ts.commonConf.ChartFormats)
+	return err
 }
 
 func (ts *ThroughputScenario) Run(results *utils.Results) {
@@ -70,9 +87,11 @@ func (ts *ThroughputScenario) Run(results *utils.Results) {
 	var appManager framework.AppManager
 	var appInfo *framework.AppInfo
 	var appAnanyzer *framework.AppAnalyzer
+	restoreSchedulerConfig := func() {}
 	// make sure app is cleaned up when error occurred
 	defer func() {
 		CleanupApp(appManager, appInfo, maxWaitTime)
+		restoreSchedulerConfig()
 	}()
 
 	for caseIndex, testCase := range ts.scenarioConf.Cases {
@@ -81,25 +100,30 @@ func (ts *ThroughputScenario) Run(results *utils.Results) {
 		caseVerification := scenarioResults.AddVerificationGroup(verGroupName, verGroupDescription)
 		utils.Logger.Info("[Prepare] add verification group", zap.String("name", verGroupName),
 			zap.String("description", verGroupDescription))
+		var err error
+		if restoreSchedulerConfig, err = ApplySchedulerConfig(ts.kubeClient, ts.commonConf,
+			testCase.SchedulerConfig, caseVerification); err != nil {
+			return
+		}
 		// init app info & app manager
-		requestInfos := ConvertToRequestInfos(testCase.RequestConfigs)
-		appInfo = framework.NewAppInfo(ts.commonConf.Namespace, ThroughputScenarioName, ts.commonConf.Queue,
-			requestInfos, ts.commonConf.PodTemplateSpec, ts.commonConf.PodSpec)
+		appInfo = ts.newAppInfo(testCase)
 		appManager = framework.NewDeploymentsAppManager(ts.kubeClient)
 		appAnanyzer = framework.NewAppAnalyzer(appInfo)
 
 		// test for different schedulers
 		cumulativeDistributions := make(map[string][]int, len(schedulerNames))
+		schedulingLatencies := make(map[string][]float64, len(schedulerNames))
+		comparison := utils.NewComparison(SchedulerComparedMetrics)
 		for _, schedulerName := range schedulerNames {
 			utils.Logger.Info("start testing for scheduler " + schedulerName)
 			schedulerVerification := caseVerification.AddSubVerificationGroup(
-				fmt.Sprintf("test for %s", schedulerName), verGroupDescription)
+				fmt.Sprintf("test for %s", schedulerName), verGroupDescription).Start()
 
 			// create app and wait for it to be running
 			utils.Logger.Info("[Testing] create an app and wait for it to be running, refresh app status at last",
 				zap.String("appID", appInfo.AppID))
 			beginTime := time.Now().Truncate(time.Second)
-			err := appManager.CreateWaitAndRefreshTasksStatus(schedulerName, appInfo, maxWaitTime)
+			err = appManager.CreateWaitAndRefreshTasksStatus(schedulerName, appInfo, maxWaitTime)
 			if err != nil {
 				utils.Logger.Error("failed to create/wait/refresh app", zap.Error(err))
 				schedulerVerification.AddSubVerification("test app", err.Error(), utils.FAILED)
@@ -112,6 +136,7 @@ func (ts *ThroughputScenario) Run(results *utils.Results) {
 			// calculate scheduled time distribution and its cumulative distribution
 			scheduledTimeDistribution := appAnanyzer.GetTimeDistribution(framework.PodScheduled)
 			cumulativeDistributions[schedulerName] = getCumulativeDistribution(scheduledTimeDistribution)
+			schedulingLatencies[schedulerName] = appAnanyzer.GetSchedulingLatencies()
 
 			if ts.scenarioConf.CleanUpDelayMs > 0 {
 				utils.Logger.Info("wait for a while before cleaning up test apps",
@@ -123,7 +148,9 @@ func (ts *ThroughputScenario) Run(results *utils.Results) {
 			// delete this app and wait for it to be cleaned up
 			utils.Logger.Info("[Cleanup] delete this app then wait for it to be cleaned up",
 				zap.String("appID", appInfo.AppID))
-			err = appManager.DeleteWait(appInfo, maxWaitTime)
+			err = DeleteWaitAndAnalyze(ts.kubeClient, ts.commonConf, appManager, appInfo, schedulerVerification,
+				fmt.Sprintf("%s/%s-case%d-%s", ts.commonConf.OutputPath, ts.GetName(), caseIndex, schedulerName),
+				ts.tableFormats)
 			if err != nil {
 				utils.Logger.Error("failed to delete/wait app", zap.Error(err))
 				schedulerVerification.AddSubVerification("cleanup app", err.Error(), utils.FAILED)
@@ -132,31 +159,74 @@ func (ts *ThroughputScenario) Run(results *utils.Results) {
 
 			description := fmt.Sprintf("seconds: %d, throughputDistribution: %+v",
 				len(scheduledTimeDistribution), scheduledTimeDistribution)
-			schedulerVerification.AddSubVerification("get scheduled time distribution", description, utils.SUCCEEDED)
+			distributionVerification := schedulerVerification.AddSubVerification(
+				"get scheduled time distribution", description, utils.SUCCEEDED)
+			distributionVerification.SetTimeRange(appInfo.AppStatus.CreateTime, appInfo.AppStatus.RunningTime)
+			AddThroughputMetrics(schedulerVerification, scheduledTimeDistribution)
+			AddSchedulingLatencyMetrics(schedulerVerification, schedulingLatencies[schedulerName])
+			schedulerVerification.Finish()
+			comparison.AddVerification(schedulerName, schedulerVerification)
 		}
-		// draw chart
-		linePoints := utils.GetLinePoints(cumulativeDistributions)
+		// output comparison and charts
 		chartFileName := fmt.Sprintf("%s-case%d-%d", ThroughputScenarioName,
 			caseIndex, appInfo.GetDesiredNumTasks())
-		chart := &utils.Chart{
-			Title:      "Scheduling Throughput",
-			XLabel:     "Seconds",
-			YLabel:     "Number of Scheduled Pods",
-			Width:      constants.ChartWidth,
-			Height:     constants.ChartHeight,
-			LinePoints: linePoints,
-			SvgFile:    ts.commonConf.OutputPath + "/" + chartFileName + constants.ChartFileSuffix,
+		if err = OutputComparison(caseVerification, comparison,
+			ts.commonConf.OutputPath+"/"+chartFileName+"-comparison", ts.tableFormats); err != nil {
+			return
 		}
-		err := utils.DrawChart(chart)
-		outputName := "output chart"
-		if err != nil {
-			caseVerification.AddSubVerification(outputName,
-				fmt.Sprintf("failed to draw chart: %s", err.Error()),
-				utils.FAILED)
+		if err = ts.outputCharts(caseVerification, chartFileName, cumulativeDistributions,
+			schedulingLatencies); err != nil {
 			return
 		}
-		caseVerification.AddSubVerification(outputName, chart.SvgFile, utils.SUCCEEDED)
+		restoreSchedulerConfig()
+	}
+}
+
+func (ts *ThroughputScenario) Plan() ([]*framework.CasePlan, error) {
+	plans := make([]*framework.CasePlan, 0, len(ts.scenarioConf.Cases))
+	for _, testCase := range ts.scenarioConf.Cases {
+		plans = append(plans, &framework.CasePlan{
+			Description:    testCase.Description,
+			SchedulerNames: ts.scenarioConf.SchedulerNames,
+			AppInfos:       []*framework.AppInfo{ts.newAppInfo(testCase)},
+		})
+	}
+	return plans, nil
+}
+
+func (ts *ThroughputScenario) newAppInfo(testCase *ThroughputCaseConfig) *framework.AppInfo {
+	return framework.NewAppInfo(ts.commonConf.Namespace, ThroughputScenarioName, ts.commonConf.Queue,
+		ConvertToRequestInfos(testCase.RequestConfigs), ts.commonConf.PodTemplateSpec, ts.commonConf.PodSpec)
+}
+
+// outputCharts outputs the scheduling throughput chart and the scheduling latency chart of a case
+func (ts *ThroughputScenario) outputCharts(caseVerification *utils.Verification, chartFileName string,
+	cumulativeDistributions map[string][]int, schedulingLatencies map[string][]float64) error {
+	chart := &utils.Chart{
+		Title:          "Scheduling Throughput",
+		XLabel:         "Seconds",
+		YLabel:         "Number of Scheduled Pods",
+		Width:          constants.ChartWidth,
+		Height:         constants.ChartHeight,
+		LinePoints:     utils.GetLinePoints(cumulativeDistributions),
+		FilePathPrefix: ts.commonConf.OutputPath + "/" + chartFileName,
+		Formats:        ts.chartFormats,
+	}
+	if err := OutputChart(caseVerification, "output chart", chart); err != nil {
+		return err
+	}
+	latencyChart := &utils.Chart{
+		Kind:           utils.ChartKindBoxPlot,
+		Title:          "Scheduling Latency",
+		XLabel:         "Scheduler",
+		YLabel:         "Seconds from Created to Scheduled",
+		Width:          constants.ChartWidth,
+		Height:         constants.ChartHeight,
+		Values:         schedulingLatencies,
+		FilePathPrefix: ts.commonConf.OutputPath + "/" + chartFileName + "-latency",
+		Formats:        ts.chartFormats,
 	}
+	return OutputChart(caseVerification, "output scheduling latency chart", latencyChart)
 }
 
 func getCumulativeDistribution(data []int) []int {
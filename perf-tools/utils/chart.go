@@ -19,40 +19,273 @@
 package utils
 
 import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+
 	"go.uber.org/zap"
 	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette"
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/plotutil"
 	"gonum.org/v1/plot/vg"
 )
 
+type ChartKind string
+
+const (
+	ChartKindLine      ChartKind = "line"
+	ChartKindHistogram ChartKind = "histogram"
+	ChartKindCDF       ChartKind = "cdf"
+	ChartKindBoxPlot   ChartKind = "boxplot"
+	ChartKindHeatmap   ChartKind = "heatmap"
+
+	DefaultHistogramBins = 20
+	// show names of heatmap rows only if there are not too many rows
+	maxHeatmapRowLabels = 50
+)
+
+type ChartFormat string
+
+const (
+	ChartFormatSVG ChartFormat = "svg"
+	ChartFormatPNG ChartFormat = "png"
+	ChartFormatPDF ChartFormat = "pdf"
+)
+
+var DefaultChartFormats = []ChartFormat{ChartFormatSVG}
+
 type Chart struct {
-	Title      string
-	XLabel     string
-	YLabel     string
-	Width      vg.Length
-	Height     vg.Length
+	// kind of this chart, defaults to line chart
+	Kind   ChartKind
+	Title  string
+	XLabel string
+	YLabel string
+	Width  vg.Length
+	Height vg.Length
+	// series for line charts: alternating series name and points
 	LinePoints []interface{}
-	SvgFile    string
+	// optional names of X values for line charts, the X value of a point is the index of its name
+	XNames []string
+	// sample values for histogram, CDF and box plot charts, keyed by series name
+	Values map[string][]float64
+	// number of bins for histogram charts
+	Bins int
+	// grid data for heatmap charts
+	Heatmap *HeatmapData
+	// output file path without suffix, a file will be written for each format
+	FilePathPrefix string
+	// output formats, defaults to SVG
+	Formats []ChartFormat
 }
 
-func DrawChart(chart *Chart) error {
+// HeatmapData holds values indexed by [row][column], for example [node][second]
+type HeatmapData struct {
+	Values    [][]float64
+	RowLabels []string
+}
+
+// ParseChartFormats converts configured format names to chart formats,
+// the default formats are returned if nothing is configured.
+func ParseChartFormats(formatNames []string) ([]ChartFormat, error) {
+	if len(formatNames) == 0 {
+		return DefaultChartFormats, nil
+	}
+	formats := make([]ChartFormat, 0, len(formatNames))
+	for _, formatName := range formatNames {
+		format := ChartFormat(strings.ToLower(strings.TrimSpace(formatName)))
+		switch format {
+		case ChartFormatSVG, ChartFormatPNG, ChartFormatPDF:
+			formats = append(formats, format)
+		default:
+			return nil, fmt.Errorf("unknown chart format %s, supported formats: %v", formatName,
+				[]ChartFormat{ChartFormatSVG, ChartFormatPNG, ChartFormatPDF})
+		}
+	}
+	return formats, nil
+}
+
+// DrawChart draws the chart and saves it in all expected formats, returns paths of the written files.
+func DrawChart(chart *Chart) ([]string, error) {
 	p := plot.New()
 	p.Title.Text = chart.Title
 	p.X.Label.Text = chart.XLabel
 	p.Y.Label.Text = chart.YLabel
-	err := plotutil.AddLinePoints(p, chart.LinePoints...)
+	var err error
+	switch chart.Kind {
+	case ChartKindLine, "":
+		err = plotutil.AddLinePoints(p, chart.LinePoints...)
+		if len(chart.XNames) > 0 {
+			p.NominalX(chart.XNames...)
+		}
+	case ChartKindHistogram:
+		err = addHistograms(p, chart)
+	case ChartKindCDF:
+		err = addCDFs(p, chart)
+	case ChartKindBoxPlot:
+		err = addBoxPlots(p, chart)
+	case ChartKindHeatmap:
+		err = addHeatmap(p, chart)
+	default:
+		err = fmt.Errorf("unknown chart kind %s", chart.Kind)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if err := p.Save(chart.Width, chart.Height, chart.SvgFile); err != nil {
-		return err
+	formats := chart.Formats
+	if len(formats) == 0 {
+		formats = DefaultChartFormats
+	}
+	outputFiles := make([]string, 0, len(formats))
+	for _, format := range formats {
+		outputFile := chart.FilePathPrefix + "." + string(format)
+		if err := p.Save(chart.Width, chart.Height, outputFile); err != nil {
+			return outputFiles, err
+		}
+		outputFiles = append(outputFiles, outputFile)
 	}
 	Logger.Info("Successfully draw chart", zap.String("title", chart.Title),
-		zap.String("outputFile", chart.SvgFile))
+		zap.Strings("outputFiles", outputFiles))
+	return outputFiles, nil
+}
+
+// getSortedSeriesNames returns names of non-empty series in order to keep colors and legends stable
+func getSortedSeriesNames(values map[string][]float64) []string {
+	names := make([]string, 0, len(values))
+	for name, v := range values {
+		if len(v) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func addHistograms(p *plot.Plot, chart *Chart) error {
+	bins := chart.Bins
+	if bins <= 0 {
+		bins = DefaultHistogramBins
+	}
+	for i, name := range getSortedSeriesNames(chart.Values) {
+		hist, err := plotter.NewHist(plotter.Values(chart.Values[name]), bins)
+		if err != nil {
+			return err
+		}
+		c := plotutil.Color(i)
+		r, g, b, _ := c.RGBA()
+		// semi-transparent fill to keep overlapping series visible
+		hist.FillColor = color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 128}
+		hist.LineStyle.Color = c
+		p.Add(hist)
+		p.Legend.Add(name, hist)
+	}
+	return nil
+}
+
+func addCDFs(p *plot.Plot, chart *Chart) error {
+	var lines []interface{}
+	for _, name := range getSortedSeriesNames(chart.Values) {
+		lines = append(lines, name, GetCDFPoints(chart.Values[name]))
+	}
+	return plotutil.AddLines(p, lines...)
+}
+
+// GetCDFPoints returns points of the cumulative distribution function of the values,
+// X is the value and Y is the fraction of values less than or equal to X.
+func GetCDFPoints(values []float64) plotter.XYs {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	pts := make(plotter.XYs, len(sorted))
+	for i, v := range sorted {
+		pts[i].X = v
+		pts[i].Y = float64(i+1) / float64(len(sorted))
+	}
+	return pts
+}
+
+func addBoxPlots(p *plot.Plot, chart *Chart) error {
+	names := getSortedSeriesNames(chart.Values)
+	for i, name := range names {
+		box, err := plotter.NewBoxPlot(vg.Points(20), float64(i), plotter.Values(chart.Values[name]))
+		if err != nil {
+			return err
+		}
+		box.FillColor = plotutil.Color(i)
+		p.Add(box)
+	}
+	p.NominalX(names...)
 	return nil
 }
 
+func addHeatmap(p *plot.Plot, chart *Chart) error {
+	if chart.Heatmap == nil || len(chart.Heatmap.Values) == 0 {
+		return fmt.Errorf("no data for heatmap chart %s", chart.Title)
+	}
+	grid := newHeatmapGrid(chart.Heatmap.Values)
+	if grid.cols == 0 {
+		return fmt.Errorf("no data for heatmap chart %s", chart.Title)
+	}
+	p.Add(plotter.NewHeatMap(grid, palette.Heat(16, 1)))
+	if len(chart.Heatmap.RowLabels) == len(chart.Heatmap.Values) &&
+		len(chart.Heatmap.RowLabels) <= maxHeatmapRowLabels {
+		p.NominalY(chart.Heatmap.RowLabels...)
+	}
+	return nil
+}
+
+// heatmapGrid implements plotter.GridXYZ for values indexed by [row][column]
+type heatmapGrid struct {
+	values   [][]float64
+	cols     int
+	min, max float64
+}
+
+func newHeatmapGrid(values [][]float64) *heatmapGrid {
+	grid := &heatmapGrid{values: values, min: math.Inf(1), max: math.Inf(-1)}
+	for _, row := range values {
+		grid.cols = max(grid.cols, len(row))
+		for _, v := range row {
+			grid.min = math.Min(grid.min, v)
+			grid.max = math.Max(grid.max, v)
+		}
+	}
+	// avoid an empty range which can't be mapped to the palette
+	if grid.max <= grid.min {
+		grid.max = grid.min + 1
+	}
+	return grid
+}
+
+func (g *heatmapGrid) Dims() (c, r int) {
+	return g.cols, len(g.values)
+}
+
+func (g *heatmapGrid) Z(c, r int) float64 {
+	if c >= len(g.values[r]) {
+		return g.min
+	}
+	return g.values[r][c]
+}
+
+func (g *heatmapGrid) X(c int) float64 {
+	return float64(c)
+}
+
+func (g *heatmapGrid) Y(r int) float64 {
+	return float64(r)
+}
+
+func (g *heatmapGrid) Min() float64 {
+	return g.min
+}
+
+func (g *heatmapGrid) Max() float64 {
+	return g.max
+}
+
 func GetPointsFromSlice(slice []int) plotter.XYs {
 	pts := make(plotter.XYs, len(slice))
 	for i := range slice {
@@ -62,6 +295,15 @@ func GetPointsFromSlice(slice []int) plotter.XYs {
 	return pts
 }
 
+func GetPointsFromFloatSlice(slice []float64) plotter.XYs {
+	pts := make(plotter.XYs, len(slice))
+	for i := range slice {
+		pts[i].X = float64(i)
+		pts[i].Y = slice[i]
+	}
+	return pts
+}
+
 func GetLinePoints(dataMap map[string][]int) []interface{} {
 	var linePoints []interface{}
 	for k, v := range dataMap {
@@ -69,3 +311,16 @@ func GetLinePoints(dataMap map[string][]int) []interface{} {
 	}
 	return linePoints
 }
+
+// GetXYPoints returns points of the X and Y values sorted by X values
+func GetXYPoints(xValues, yValues []float64) plotter.XYs {
+	pts := make(plotter.XYs, len(xValues))
+	for i := range xValues {
+		pts[i].X = xValues[i]
+		pts[i].Y = yValues[i]
+	}
+	sort.SliceStable(pts, func(i, j int) bool {
+		return pts[i].X < pts[j].X
+	})
+	return pts
+}
@@ -0,0 +1,99 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gonum.org/v1/plot/vg"
+	"gotest.tools/v3/assert"
+)
+
+func TestDrawChart(t *testing.T) {
+	outputPath := t.TempDir()
+	values := map[string][]float64{
+		"yunikorn":          {0.1, 0.2, 0.2, 0.5, 1.2},
+		"default-scheduler": {0.3, 0.4, 0.6, 0.9},
+		"empty":             {},
+	}
+	charts := []*Chart{
+		{Kind: ChartKindLine, LinePoints: GetLinePoints(map[string][]int{"s1": {1, 2, 3}})},
+		{Kind: ChartKindLine, LinePoints: []interface{}{"s1", GetXYPoints([]float64{1, 0}, []float64{3, 2})},
+			XNames: []string{"yunikorn", "default-scheduler"}},
+		{Kind: ChartKindHistogram, Values: values, Bins: 5},
+		{Kind: ChartKindCDF, Values: values},
+		{Kind: ChartKindBoxPlot, Values: values},
+		{Kind: ChartKindHeatmap, Heatmap: &HeatmapData{
+			Values:    [][]float64{{0, 0.5, 1}, {0.2, 0.2}},
+			RowLabels: []string{"node-1", "node-2"},
+		}},
+	}
+	for i, chart := range charts {
+		chart.Title = string(chart.Kind)
+		chart.Width = 2 * vg.Inch
+		chart.Height = 2 * vg.Inch
+		chart.FilePathPrefix = filepath.Join(outputPath, fmt.Sprintf("%s-%d", chart.Kind, i))
+		chart.Formats = []ChartFormat{ChartFormatSVG, ChartFormatPNG, ChartFormatPDF}
+		outputFiles, err := DrawChart(chart)
+		assert.NilError(t, err, "failed to draw %s chart", chart.Kind)
+		assert.Equal(t, len(outputFiles), 3)
+		for _, outputFile := range outputFiles {
+			info, err := os.Stat(outputFile)
+			assert.NilError(t, err)
+			assert.Assert(t, info.Size() > 0)
+		}
+	}
+
+	_, err := DrawChart(&Chart{Kind: ChartKindHeatmap, Width: vg.Inch, Height: vg.Inch})
+	assert.ErrorContains(t, err, "no data for heatmap chart")
+	_, err = DrawChart(&Chart{Kind: "unknown", Width: vg.Inch, Height: vg.Inch})
+	assert.ErrorContains(t, err, "unknown chart kind")
+}
+
+func TestGetCDFPoints(t *testing.T) {
+	pts := GetCDFPoints([]float64{3, 1, 2, 4})
+	assert.Equal(t, len(pts), 4)
+	assert.Equal(t, pts[0].X, float64(1))
+	assert.Equal(t, pts[0].Y, 0.25)
+	assert.Equal(t, pts[3].X, float64(4))
+	assert.Equal(t, pts[3].Y, float64(1))
+}
+
+func TestGetXYPoints(t *testing.T) {
+	pts := GetXYPoints([]float64{100, 10, 50}, []float64{1, 2, 3})
+	assert.Equal(t, len(pts), 3)
+	assert.Equal(t, pts[0].X, float64(10))
+	assert.Equal(t, pts[0].Y, float64(2))
+	assert.Equal(t, pts[2].X, float64(100))
+	assert.Equal(t, pts[2].Y, float64(1))
+}
+
+func TestParseChartFormats(t *testing.T) {
+	formats, err := ParseChartFormats(nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, formats, DefaultChartFormats)
+	formats, err = ParseChartFormats([]string{"PNG", "pdf"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, formats, []ChartFormat{ChartFormatPNG, ChartFormatPDF})
+	_, err = ParseChartFormats([]string{"gif"})
+	assert.ErrorContains(t, err, "unknown chart format gif")
+}
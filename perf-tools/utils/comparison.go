@@ -0,0 +1,231 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+const noValue = "-"
+
+type ComparedMetric struct {
+	Name          string
+	Unit          string
+	LowerIsBetter bool
+}
+
+// Comparison compares metrics side by side, one column per subject (e.g. scheduler)
+type Comparison struct {
+	Metrics []*ComparedMetric
+	Columns []string
+	// key: metric name, value: (key: column name, value: metric value)
+	values map[string]map[string]float64
+}
+
+func NewComparison(metrics []*ComparedMetric) *Comparison {
+	return &Comparison{
+		Metrics: metrics,
+		Columns: make([]string, 0),
+		values:  make(map[string]map[string]float64),
+	}
+}
+
+// AddVerification adds values of compared metrics recorded on the verification as a new column
+func (c *Comparison) AddVerification(column string, verification *Verification) {
+	c.addColumn(column)
+	for _, metric := range c.Metrics {
+		if m := verification.GetMetric(metric.Name); m != nil {
+			c.Set(column, metric.Name, m.Value)
+		}
+	}
+}
+
+func (c *Comparison) Set(column, metricName string, value float64) {
+	c.addColumn(column)
+	if c.values[metricName] == nil {
+		c.values[metricName] = make(map[string]float64)
+	}
+	c.values[metricName][column] = value
+}
+
+func (c *Comparison) Get(column, metricName string) (float64, bool) {
+	value, ok := c.values[metricName][column]
+	return value, ok
+}
+
+func (c *Comparison) addColumn(column string) {
+	for _, existing := range c.Columns {
+		if existing == column {
+			return
+		}
+	}
+	c.Columns = append(c.Columns, column)
+}
+
+// GetWinner returns the column with the best value of the metric and the relative difference (in percentage)
+// of the runner-up to the winner, an empty winner is returned if less than two columns have values.
+func (c *Comparison) GetWinner(metric *ComparedMetric) (string, float64) {
+	type columnValue struct {
+		column string
+		value  float64
+	}
+	columnValues := make([]columnValue, 0, len(c.Columns))
+	for _, column := range c.Columns {
+		if value, ok := c.Get(column, metric.Name); ok {
+			columnValues = append(columnValues, columnValue{column: column, value: value})
+		}
+	}
+	if len(columnValues) < 2 {
+		return "", 0
+	}
+	sort.SliceStable(columnValues, func(i, j int) bool {
+		if metric.LowerIsBetter {
+			return columnValues[i].value < columnValues[j].value
+		}
+		return columnValues[i].value > columnValues[j].value
+	})
+	winner, runnerUp := columnValues[0], columnValues[1]
+	if winner.value == runnerUp.value {
+		return "", 0
+	}
+	if winner.value == 0 {
+		return winner.column, math.Inf(1)
+	}
+	return winner.column, math.Abs(runnerUp.value-winner.value) / math.Abs(winner.value) * 100
+}
+
+// ToTable renders the comparison as a table with one row per metric and one column per subject,
+// plus the winner and the relative difference of the runner-up to the winner.
+func (c *Comparison) ToTable() *Table {
+	headers := append([]string{"Metric"}, c.Columns...)
+	headers = append(headers, "Winner", "Difference")
+	data := make([][]string, 0, len(c.Metrics))
+	for _, metric := range c.Metrics {
+		row := make([]string, 0, len(headers))
+		name := metric.Name
+		if metric.Unit != "" {
+			name += fmt.Sprintf(" (%s)", metric.Unit)
+		}
+		row = append(row, name)
+		for _, column := range c.Columns {
+			if value, ok := c.Get(column, metric.Name); ok {
+				row = append(row, formatComparedValue(value))
+			} else {
+				row = append(row, noValue)
+			}
+		}
+		winner, diff := c.GetWinner(metric)
+		if winner == "" {
+			row = append(row, noValue, noValue)
+		} else if math.IsInf(diff, 1) {
+			row = append(row, winner, noValue)
+		} else {
+			row = append(row, winner, fmt.Sprintf("%.2f%%", diff))
+		}
+		data = append(data, row)
+	}
+	return &Table{
+		Headers: headers,
+		Data:    data,
+	}
+}
+
+func formatComparedValue(value float64) string {
+	return strconv.FormatFloat(math.Round(value*1000)/1000, 'f', -1, 64)
+}
+
+type resultsMetric struct {
+	scenarioName     string
+	verificationPath string
+	metric           *Metric
+}
+
+// CompareResults compares metrics of two runs, one row per metric of verifications found in both runs by their
+// paths (the scenario and names of verifications). The change is relative to the baseline, and it is judged
+// as better or worse only for metrics with known directions.
+func CompareResults(baseline, target *Results, metrics []*ComparedMetric) *Table {
+	lowerIsBetter := make(map[string]bool, len(metrics))
+	for _, metric := range metrics {
+		if _, ok := lowerIsBetter[metric.Name]; !ok {
+			lowerIsBetter[metric.Name] = metric.LowerIsBetter
+		}
+	}
+	baselineMetrics := make(map[string]*Metric)
+	for _, rm := range baseline.collectMetrics() {
+		baselineMetrics[rm.scenarioName+"/"+rm.verificationPath+"/"+rm.metric.Name] = rm.metric
+	}
+	table := &Table{
+		Headers: []string{"Scenario", "Verification", "Metric", "Baseline", "Target", "Change", "Verdict"},
+	}
+	for _, rm := range target.collectMetrics() {
+		baselineMetric := baselineMetrics[rm.scenarioName+"/"+rm.verificationPath+"/"+rm.metric.Name]
+		if baselineMetric == nil {
+			continue
+		}
+		change, verdict := noValue, noValue
+		if baselineMetric.Value != 0 {
+			change = fmt.Sprintf("%+.2f%%", (rm.metric.Value-baselineMetric.Value)/math.Abs(baselineMetric.Value)*100)
+		}
+		if lower, ok := lowerIsBetter[rm.metric.Name]; ok {
+			switch {
+			case rm.metric.Value == baselineMetric.Value:
+				verdict = "same"
+			case (rm.metric.Value < baselineMetric.Value) == lower:
+				verdict = "better"
+			default:
+				verdict = "worse"
+			}
+		}
+		name := rm.metric.Name
+		if rm.metric.Unit != "" {
+			name += fmt.Sprintf(" (%s)", rm.metric.Unit)
+		}
+		table.Data = append(table.Data, []string{rm.scenarioName, rm.verificationPath, name,
+			formatComparedValue(baselineMetric.Value), formatComparedValue(rm.metric.Value), change, verdict})
+	}
+	return table
+}
+
+// collectMetrics returns all metrics of verifications in order with their paths
+func (r *Results) collectMetrics() []*resultsMetric {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	var collected []*resultsMetric
+	var collect func(scenarioName, parentPath string, verifications []*Verification)
+	collect = func(scenarioName, parentPath string, verifications []*Verification) {
+		for _, v := range verifications {
+			path := v.Name
+			if parentPath != "" {
+				path = parentPath + " / " + v.Name
+			}
+			for _, metric := range v.Metrics {
+				collected = append(collected, &resultsMetric{scenarioName: scenarioName, verificationPath: path,
+					metric: metric})
+			}
+			collect(scenarioName, path, v.SubVerifications)
+		}
+	}
+	for _, scenarioResult := range r.ScenarioResults {
+		collect(scenarioResult.Name, "", scenarioResult.Verifications)
+	}
+	return collected
+}
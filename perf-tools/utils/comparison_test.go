@@ -0,0 +1,96 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestComparison(t *testing.T) {
+	metrics := []*ComparedMetric{
+		{Name: "totalSeconds", Unit: UnitSeconds, LowerIsBetter: true},
+		{Name: "avgQPS", Unit: UnitQPS},
+		{Name: "spread", Unit: UnitPods, LowerIsBetter: true},
+	}
+	results := NewResults()
+	s1 := results.CreateScenarioResults("s1")
+	yk := s1.AddVerificationGroup("yunikorn", "").
+		AddMetric("totalSeconds", 10, UnitSeconds).
+		AddMetric("avgQPS", 100, UnitQPS)
+	ds := s1.AddVerificationGroup("default-scheduler", "").
+		AddMetric("totalSeconds", 20, UnitSeconds).
+		AddMetric("avgQPS", 50, UnitQPS).
+		AddMetric("spread", 3, UnitPods)
+
+	comparison := NewComparison(metrics)
+	comparison.AddVerification("yunikorn", yk)
+	comparison.AddVerification("default-scheduler", ds)
+	assert.DeepEqual(t, comparison.Columns, []string{"yunikorn", "default-scheduler"})
+
+	winner, diff := comparison.GetWinner(metrics[0])
+	assert.Equal(t, winner, "yunikorn")
+	assert.Equal(t, diff, float64(100))
+	winner, diff = comparison.GetWinner(metrics[1])
+	assert.Equal(t, winner, "yunikorn")
+	assert.Equal(t, diff, float64(50))
+	// only one column has value
+	winner, _ = comparison.GetWinner(metrics[2])
+	assert.Equal(t, winner, "")
+
+	table := comparison.ToTable()
+	assert.DeepEqual(t, table.Headers, []string{"Metric", "yunikorn", "default-scheduler", "Winner", "Difference"})
+	assert.DeepEqual(t, table.Data, [][]string{
+		{"totalSeconds (s)", "10", "20", "yunikorn", "100.00%"},
+		{"avgQPS (pods/s)", "100", "50", "yunikorn", "50.00%"},
+		{"spread (pods)", "-", "3", "-", "-"},
+	})
+}
+
+func TestCompareResults(t *testing.T) {
+	metrics := []*ComparedMetric{
+		{Name: "totalSeconds", Unit: UnitSeconds, LowerIsBetter: true},
+		{Name: "avgQPS", Unit: UnitQPS},
+	}
+	baseline := NewResults()
+	baseline.CreateScenarioResults("s1").AddVerificationGroup("Case-0", "").
+		AddSubVerificationGroup("test for yunikorn", "").
+		AddMetric("totalSeconds", 10, UnitSeconds).
+		AddMetric("avgQPS", 100, UnitQPS).
+		AddMetric("numPods", 0, UnitPods).
+		AddMetric("removed", 1, UnitNone)
+	target := NewResults()
+	target.CreateScenarioResults("s1").AddVerificationGroup("Case-0", "").
+		AddSubVerificationGroup("test for yunikorn", "").
+		AddMetric("totalSeconds", 12, UnitSeconds).
+		AddMetric("avgQPS", 100, UnitQPS).
+		AddMetric("numPods", 5, UnitPods).
+		AddMetric("added", 1, UnitNone)
+
+	table := CompareResults(baseline, target, metrics)
+	assert.DeepEqual(t, table.Headers,
+		[]string{"Scenario", "Verification", "Metric", "Baseline", "Target", "Change", "Verdict"})
+	// only metrics found in both runs are compared
+	assert.DeepEqual(t, table.Data, [][]string{
+		{"s1", "Case-0 / test for yunikorn", "totalSeconds (s)", "10", "12", "+20.00%", "worse"},
+		{"s1", "Case-0 / test for yunikorn", "avgQPS (pods/s)", "100", "100", "+0.00%", "same"},
+		{"s1", "Case-0 / test for yunikorn", "numPods (pods)", "0", "5", "-", "-"},
+	})
+}
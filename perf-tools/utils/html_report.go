@@ -0,0 +1,226 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const HTMLReportFileName = "index.html"
+
+type RunMetadata struct {
+	StartTime     time.Time
+	EndTime       time.Time
+	ConfigFile    string
+	OutputPath    string
+	ScenarioNames []string
+	// additional key-value information shown in the report, e.g. cluster or scheduler versions
+	Extra map[string]string
+}
+
+// HTMLReporter generates a single self-contained HTML file for the results of a run,
+// all artifacts referenced by verifications are embedded into the file.
+type HTMLReporter struct {
+	results  *Results
+	metadata *RunMetadata
+}
+
+type htmlVerification struct {
+	*Verification
+	Artifacts []*htmlArtifact
+	Children  []*htmlVerification
+}
+
+type htmlArtifact struct {
+	*Artifact
+	Content template.HTML
+}
+
+type htmlScenario struct {
+	Name          string
+	Status        string
+	Verifications []*htmlVerification
+}
+
+func NewHTMLReporter(results *Results, metadata *RunMetadata) *HTMLReporter {
+	return &HTMLReporter{
+		results:  results,
+		metadata: metadata,
+	}
+}
+
+// GenerateReport writes index.html into the output path of this run
+func (hr *HTMLReporter) GenerateReport() error {
+	reportFile := filepath.Join(hr.metadata.OutputPath, HTMLReportFileName)
+	f, err := os.Create(reportFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"status": getStatusString,
+		"formatTime": func(t time.Time) string {
+			if t.IsZero() {
+				return "-"
+			}
+			return t.Format(time.RFC3339)
+		},
+	}).Parse(htmlReportTemplate)
+	if err != nil {
+		return err
+	}
+	scenarios := make([]*htmlScenario, len(hr.results.ScenarioResults))
+	for i, scenarioResult := range hr.results.ScenarioResults {
+		scenarios[i] = &htmlScenario{
+			Name:          scenarioResult.Name,
+			Status:        getStatusString(scenarioResult.Status),
+			Verifications: convertHTMLVerifications(scenarioResult.Verifications),
+		}
+	}
+	err = tmpl.Execute(f, map[string]interface{}{
+		"Metadata":  hr.metadata,
+		"Scenarios": scenarios,
+	})
+	if err != nil {
+		return err
+	}
+	Logger.Info("Successfully generated HTML report", zap.String("outputFile", reportFile))
+	return nil
+}
+
+func convertHTMLVerifications(verifications []*Verification) []*htmlVerification {
+	htmlVerifications := make([]*htmlVerification, len(verifications))
+	for i, v := range verifications {
+		artifacts := make([]*htmlArtifact, len(v.Artifacts))
+		for j, artifact := range v.Artifacts {
+			artifacts[j] = &htmlArtifact{
+				Artifact: artifact,
+				Content:  loadArtifactContent(artifact),
+			}
+		}
+		htmlVerifications[i] = &htmlVerification{
+			Verification: v,
+			Artifacts:    artifacts,
+			Children:     convertHTMLVerifications(v.SubVerifications),
+		}
+	}
+	return htmlVerifications
+}
+
+// loadArtifactContent returns the HTML to embed an artifact: inline SVG, base64 encoded images,
+// preformatted text for tables, or a notice if the file can't be read.
+func loadArtifactContent(artifact *Artifact) template.HTML {
+	// #nosec G304 -- artifact paths are generated by the tool itself
+	content, err := os.ReadFile(artifact.Path)
+	if err != nil {
+		Logger.Warn("failed to load artifact", zap.String("path", artifact.Path), zap.Error(err))
+		return template.HTML(fmt.Sprintf("<p class=\"missing\">failed to load %s</p>",
+			template.HTMLEscapeString(artifact.Path)))
+	}
+	switch strings.ToLower(filepath.Ext(artifact.Path)) {
+	case ".svg":
+		svg := string(content)
+		// strip the XML prolog which is not allowed inside HTML
+		if idx := strings.Index(svg, "<svg"); idx > 0 {
+			svg = svg[idx:]
+		}
+		// #nosec G203 -- svg files are generated by the tool itself
+		return template.HTML(svg)
+	case ".png":
+		return template.HTML(fmt.Sprintf("<img src=\"data:image/png;base64,%s\"/>",
+			base64.StdEncoding.EncodeToString(content)))
+	case ".pdf":
+		return template.HTML(fmt.Sprintf("<a download=\"%s\" href=\"data:application/pdf;base64,%s\">%s</a>",
+			template.HTMLEscapeString(filepath.Base(artifact.Path)), base64.StdEncoding.EncodeToString(content),
+			template.HTMLEscapeString(filepath.Base(artifact.Path))))
+	default:
+		return template.HTML(fmt.Sprintf("<pre>%s</pre>", template.HTMLEscapeString(string(content))))
+	}
+}
+
+func getStatusString(status VerificationStatus) string {
+	switch status {
+	case FAILED:
+		return "FAILED"
+	case SUCCEEDED:
+		return "SUCCEEDED"
+	}
+	return ""
+}
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>YuniKorn Performance Report</title>
+<style>
+body { font-family: sans-serif; margin: 20px; }
+table.meta td { padding: 2px 10px; }
+.SUCCEEDED { color: #2e7d32; font-weight: bold; }
+.FAILED { color: #c62828; font-weight: bold; }
+.verification { margin-left: 20px; border-left: 1px solid #ddd; padding-left: 10px; }
+.description { color: #555; }
+.metrics td, .metrics th { border: 1px solid #ddd; padding: 2px 8px; }
+.metrics { border-collapse: collapse; margin: 4px 0; }
+pre { background: #f5f5f5; padding: 8px; overflow-x: auto; }
+.missing { color: #c62828; }
+</style>
+</head>
+<body>
+<h1>YuniKorn Performance Report</h1>
+<h2>Run Metadata</h2>
+<table class="meta">
+<tr><td>Start time</td><td>{{formatTime .Metadata.StartTime}}</td></tr>
+<tr><td>End time</td><td>{{formatTime .Metadata.EndTime}}</td></tr>
+<tr><td>Config file</td><td>{{.Metadata.ConfigFile}}</td></tr>
+<tr><td>Output path</td><td>{{.Metadata.OutputPath}}</td></tr>
+<tr><td>Scenarios</td><td>{{range $i, $name := .Metadata.ScenarioNames}}{{if $i}}, {{end}}{{$name}}{{end}}</td></tr>
+{{range $key, $value := .Metadata.Extra}}<tr><td>{{$key}}</td><td>{{$value}}</td></tr>
+{{end}}</table>
+<h2>Summary</h2>
+<ul>
+{{range .Scenarios}}<li><a href="#scenario-{{.Name}}">{{.Name}}</a> <span class="{{.Status}}">[{{.Status}}]</span></li>
+{{end}}</ul>
+{{range .Scenarios}}
+<h2 id="scenario-{{.Name}}">Scenario: {{.Name}} <span class="{{.Status}}">[{{.Status}}]</span></h2>
+{{range .Verifications}}{{template "verification" .}}{{end}}
+{{end}}
+</body>
+</html>
+{{define "verification"}}<div class="verification">
+<p>{{.Name}} <span class="{{status .Status}}">[{{status .Status}}]</span>
+{{if .Description}}<span class="description">({{.Description}})</span>{{end}}
+{{if gt .Duration 0}}<span class="description">duration: {{.Duration}}</span>{{end}}</p>
+{{if .Metrics}}<table class="metrics"><tr><th>Metric</th><th>Value</th><th>Unit</th></tr>
+{{range .Metrics}}<tr><td>{{.Name}}</td><td>{{.Value}}</td><td>{{.Unit}}</td></tr>
+{{end}}</table>{{end}}
+{{range .Artifacts}}<h4>{{.Name}}</h4>
+{{.Content}}
+{{end}}
+{{range .Children}}{{template "verification" .}}{{end}}
+</div>{{end}}
+`
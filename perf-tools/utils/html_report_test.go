@@ -0,0 +1,63 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestHTMLReporter(t *testing.T) {
+	outputPath := t.TempDir()
+	svgFile := filepath.Join(outputPath, "chart.svg")
+	assert.NilError(t, os.WriteFile(svgFile, []byte("<?xml version=\"1.0\"?>\n<svg id=\"test-chart\"></svg>"), 0600))
+	tableFile := filepath.Join(outputPath, "table.txt")
+	assert.NilError(t, os.WriteFile(tableFile, []byte("| A | <B> |"), 0600))
+
+	results := NewResults()
+	s1 := results.CreateScenarioResults("s1")
+	vg1 := s1.AddVerificationGroup("s1-vg1", "case description")
+	vg1.AddMetric("avgQPS", 10.5, UnitQPS)
+	vg1.AddSubVerification("chart", svgFile, SUCCEEDED).AddArtifact("chart", ArtifactChart, svgFile)
+	vg1.AddSubVerification("table", tableFile, SUCCEEDED).AddArtifact("table", ArtifactTable, tableFile)
+	vg1.AddSubVerification("missing", "", FAILED).AddArtifact("missing", ArtifactTable, "/not/exist.txt")
+	results.RefreshStatus()
+
+	metadata := &RunMetadata{
+		StartTime:     time.Now(),
+		EndTime:       time.Now(),
+		OutputPath:    outputPath,
+		ScenarioNames: []string{"s1"},
+	}
+	assert.NilError(t, NewHTMLReporter(results, metadata).GenerateReport())
+	content, err := os.ReadFile(filepath.Join(outputPath, HTMLReportFileName))
+	assert.NilError(t, err)
+	html := string(content)
+	assert.Assert(t, strings.Contains(html, "<svg id=\"test-chart\"></svg>"))
+	assert.Assert(t, !strings.Contains(html, "<?xml"))
+	assert.Assert(t, strings.Contains(html, "| A | &lt;B&gt; |"))
+	assert.Assert(t, strings.Contains(html, "failed to load /not/exist.txt"))
+	assert.Assert(t, strings.Contains(html, "avgQPS"))
+	assert.Assert(t, strings.Contains(html, "Scenario: s1 <span class=\"FAILED\">"))
+}
@@ -32,6 +32,8 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -73,6 +75,10 @@ func (kc *KubeClient) GetPods(namespace string, listOptions *metav1.ListOptions)
 	return kc.clientSet.CoreV1().Pods(namespace).List(context.TODO(), *listOptions)
 }
 
+func (kc *KubeClient) WatchPods(namespace string, listOptions *metav1.ListOptions) (watch.Interface, error) {
+	return kc.clientSet.CoreV1().Pods(namespace).Watch(context.TODO(), *listOptions)
+}
+
 func (kc *KubeClient) GetNodes(listOptions *metav1.ListOptions) (*apiv1.NodeList, error) {
 	return kc.clientSet.CoreV1().Nodes().List(context.TODO(), *listOptions)
 }
@@ -81,6 +87,16 @@ func (kc *KubeClient) GetConfigMap(namespace string, name string, getOptions *me
 	return kc.clientSet.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, *getOptions)
 }
 
+func (kc *KubeClient) UpdateConfigMap(namespace string, configMap *apiv1.ConfigMap) (*apiv1.ConfigMap, error) {
+	return kc.clientSet.CoreV1().ConfigMaps(namespace).Update(context.TODO(), configMap, metav1.UpdateOptions{})
+}
+
+// GetServiceProxy sends a GET request to the path of the service through the proxy of the API server
+func (kc *KubeClient) GetServiceProxy(namespace, serviceName, port, path string) ([]byte, error) {
+	return kc.clientSet.CoreV1().Services(namespace).ProxyGet("http", serviceName, port, path, nil).
+		DoRaw(context.TODO())
+}
+
 func (kc *KubeClient) CreateDeployment(namespace string, deployment *appsv1.Deployment) error {
 	deploymentsClient := kc.clientSet.AppsV1().Deployments(namespace)
 	Logger.Debug("creating deployment...")
@@ -102,6 +118,50 @@ func (kc *KubeClient) DeleteDeployment(namespace, name string) error {
 	})
 }
 
+func (kc *KubeClient) GetDeployments(namespace string, listOptions *metav1.ListOptions) (*appsv1.DeploymentList,
+	error) {
+	return kc.clientSet.AppsV1().Deployments(namespace).List(context.TODO(), *listOptions)
+}
+
+// RestartDeployment triggers a rolling restart of the deployment in the same way as "kubectl rollout restart"
+func (kc *KubeClient) RestartDeployment(namespace, name string) error {
+	deploymentsClient := kc.clientSet.AppsV1().Deployments(namespace)
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":"%s"}}}}}`,
+		time.Now().Format(time.RFC3339))
+	_, err := deploymentsClient.Patch(context.TODO(), name, types.StrategicMergePatchType, []byte(patch),
+		metav1.PatchOptions{})
+	return err
+}
+
+// CreatePod creates a pod and returns the error of the call, the call is canceled after the timeout if it is positive
+func (kc *KubeClient) CreatePod(namespace string, pod *apiv1.Pod, timeout time.Duration) error {
+	ctx := context.TODO()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	_, err := kc.clientSet.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+	return err
+}
+
+// DeletePods deletes all pods matching the list options in the namespace
+func (kc *KubeClient) DeletePods(namespace string, listOptions *metav1.ListOptions) error {
+	return kc.clientSet.CoreV1().Pods(namespace).DeleteCollection(context.TODO(), metav1.DeleteOptions{},
+		*listOptions)
+}
+
+func (kc *KubeClient) DeletePod(namespace, name string) error {
+	return kc.clientSet.CoreV1().Pods(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
+
+// DeletePodImmediately deletes a pod without the grace period, so that its containers are killed at once
+func (kc *KubeClient) DeletePodImmediately(namespace, name string) error {
+	gracePeriodSeconds := int64(0)
+	return kc.clientSet.CoreV1().Pods(namespace).Delete(context.TODO(), name,
+		metav1.DeleteOptions{GracePeriodSeconds: &gracePeriodSeconds})
+}
+
 // GetDeploymentInfo return basic information of deployment: (createTime, [desired, created, ready] replicas, error)
 func (kc *KubeClient) GetDeploymentInfo(namespace, appID string) (time.Time, []int, error) {
 	deployment, err := kc.GetDeployment(namespace, appID)
@@ -0,0 +1,286 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type EventType string
+
+const (
+	EventScenarioStarted      EventType = "scenarioStarted"
+	EventVerificationAdded    EventType = "verificationAdded"
+	EventVerificationStarted  EventType = "verificationStarted"
+	EventVerificationFinished EventType = "verificationFinished"
+	EventMetricAdded          EventType = "metricAdded"
+	EventArtifactAdded        EventType = "artifactAdded"
+
+	// max number of events buffered for the webhook, later events are dropped if the webhook is too slow
+	webhookBufferSize = 10000
+	webhookTimeout    = 5 * time.Second
+	// max time to wait for buffered events to be posted when the webhook observer is closed,
+	// remaining events are dropped after that
+	webhookCloseTimeout = 30 * time.Second
+)
+
+// Event describes an update of results as it happens
+type Event struct {
+	Time     time.Time
+	Type     EventType
+	Scenario string
+	// names of verifications from the top level to the one this event is about, empty for scenario events
+	Path []string `json:",omitempty"`
+	// status of the verification, only set for leaf verifications when they are added and all verifications
+	// when they are finished
+	Status   string        `json:",omitempty"`
+	Duration time.Duration `json:",omitempty"`
+	Metric   *Metric       `json:",omitempty"`
+	Artifact *Artifact     `json:",omitempty"`
+}
+
+// Observer is notified of events of results. Events are notified in order while results are locked,
+// so observers should return quickly and must not access results.
+type Observer interface {
+	OnEvent(event *Event)
+	Close() error
+}
+
+// AddObserver registers an observer which is notified of all later events
+func (r *Results) AddObserver(observer Observer) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.observers = append(r.observers, observer)
+}
+
+// Close closes all observers, pending events are flushed. Results are not locked while observers are closing,
+// later events are not notified.
+func (r *Results) Close() {
+	r.lock.Lock()
+	observers := r.observers
+	r.observers = nil
+	r.lock.Unlock()
+	for _, observer := range observers {
+		if err := observer.Close(); err != nil {
+			Logger.Warn("failed to close observer", zap.Error(err))
+		}
+	}
+}
+
+func (r *Results) notify(event *Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	for _, observer := range r.observers {
+		observer.OnEvent(event)
+	}
+}
+
+func (v *Verification) notify(eventType EventType, status string, updates ...func(event *Event)) {
+	if v.results == nil || len(v.results.observers) == 0 {
+		return
+	}
+	event := &Event{
+		Type:     eventType,
+		Scenario: v.scenarioName,
+		Path:     v.getPath(),
+		Status:   status,
+		Duration: v.Duration,
+	}
+	for _, update := range updates {
+		update(event)
+	}
+	v.results.notify(event)
+}
+
+func (v *Verification) getPath() []string {
+	var path []string
+	for current := v; current != nil; current = current.Parent {
+		path = append([]string{current.Name}, path...)
+	}
+	return path
+}
+
+// ConsoleObserver prints progress of verifications, one line per event
+type ConsoleObserver struct {
+	writer io.Writer
+}
+
+func NewConsoleObserver(writer io.Writer) *ConsoleObserver {
+	return &ConsoleObserver{writer: writer}
+}
+
+func (co *ConsoleObserver) OnEvent(event *Event) {
+	var line string
+	switch event.Type {
+	case EventScenarioStarted:
+		line = fmt.Sprintf("[%s] started", event.Scenario)
+	case EventVerificationStarted:
+		line = fmt.Sprintf("[%s] %s started", event.Scenario, strings.Join(event.Path, " > "))
+	case EventVerificationAdded, EventVerificationFinished:
+		// groups are only printed when they are finished
+		if event.Status == "" {
+			return
+		}
+		line = fmt.Sprintf("[%s] %s [%s]", event.Scenario, strings.Join(event.Path, " > "), event.Status)
+		if event.Duration > 0 {
+			line += fmt.Sprintf(" [duration: %s]", event.Duration)
+		}
+	case EventMetricAdded:
+		line = fmt.Sprintf("[%s] %s: %s", event.Scenario, strings.Join(event.Path, " > "), event.Metric)
+	default:
+		return
+	}
+	if _, err := fmt.Fprintln(co.writer, time.Now().Format(time.TimeOnly)+" "+line); err != nil {
+		Logger.Debug("failed to print progress", zap.Error(err))
+	}
+}
+
+func (co *ConsoleObserver) Close() error {
+	return nil
+}
+
+// JSONLinesObserver appends every event as a line of JSON to a file, which is written immediately so that
+// partial results are kept if the run dies
+type JSONLinesObserver struct {
+	file    *os.File
+	encoder *json.Encoder
+}
+
+func NewJSONLinesObserver(filePath string) (*JSONLinesObserver, error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLinesObserver{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+func (jo *JSONLinesObserver) OnEvent(event *Event) {
+	if err := jo.encoder.Encode(event); err != nil {
+		Logger.Warn("failed to write event", zap.String("filePath", jo.file.Name()), zap.Error(err))
+	}
+}
+
+func (jo *JSONLinesObserver) Close() error {
+	return jo.file.Close()
+}
+
+// WebhookObserver posts every event as JSON to an endpoint in the background,
+// events are dropped if the buffer is full so that a slow endpoint never blocks the tests
+type WebhookObserver struct {
+	url          string
+	client       *http.Client
+	events       chan *Event
+	done         chan struct{}
+	numDropped   int
+	closeOnce    sync.Once
+	closeTimeout time.Duration
+	// cancels the posting event and drops remaining events when closing times out
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewWebhookObserver(url string) *WebhookObserver {
+	ctx, cancel := context.WithCancel(context.Background())
+	wo := &WebhookObserver{
+		url:          url,
+		client:       &http.Client{Timeout: webhookTimeout},
+		events:       make(chan *Event, webhookBufferSize),
+		done:         make(chan struct{}),
+		closeTimeout: webhookCloseTimeout,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+	go wo.run()
+	return wo
+}
+
+func (wo *WebhookObserver) OnEvent(event *Event) {
+	select {
+	case wo.events <- event:
+	default:
+		wo.numDropped++
+	}
+}
+
+func (wo *WebhookObserver) run() {
+	defer close(wo.done)
+	for event := range wo.events {
+		if wo.ctx.Err() != nil {
+			wo.numDropped++
+			continue
+		}
+		if err := wo.post(event); err != nil {
+			Logger.Warn("failed to post event", zap.String("url", wo.url), zap.Error(err))
+		}
+	}
+}
+
+func (wo *WebhookObserver) post(event *Event) error {
+	content, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(wo.ctx, http.MethodPost, wo.url, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := wo.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close waits for buffered events to be posted, events which haven't been posted within the close timeout
+// are dropped
+func (wo *WebhookObserver) Close() error {
+	wo.closeOnce.Do(func() {
+		close(wo.events)
+		timer := time.NewTimer(wo.closeTimeout)
+		defer timer.Stop()
+		select {
+		case <-wo.done:
+		case <-timer.C:
+			wo.cancel()
+			<-wo.done
+		}
+		wo.cancel()
+	})
+	if wo.numDropped > 0 {
+		return fmt.Errorf("dropped %d events since the webhook %s is too slow", wo.numDropped, wo.url)
+	}
+	return nil
+}
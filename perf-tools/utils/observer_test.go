@@ -0,0 +1,182 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+type recordingObserver struct {
+	events []*Event
+	closed bool
+}
+
+func (ro *recordingObserver) OnEvent(event *Event) {
+	ro.events = append(ro.events, event)
+}
+
+func (ro *recordingObserver) Close() error {
+	ro.closed = true
+	return nil
+}
+
+func TestObserver(t *testing.T) {
+	results := NewResults()
+	observer := &recordingObserver{}
+	results.AddObserver(observer)
+	scenarioResult := results.CreateScenarioResults("s1")
+	vg := scenarioResult.AddVerificationGroup("case-0", "").Start()
+	subVg := vg.AddSubVerificationGroup("test for yunikorn", "")
+	subVg.AddMetric("avgQPS", 10, UnitQPS)
+	subVg.AddSubVerification("check", "", FAILED)
+	subVg.AddArtifact("table", ArtifactTable, "/tmp/table.txt")
+	vg.Finish()
+	results.Close()
+	assert.Assert(t, observer.closed)
+
+	types := make([]EventType, len(observer.events))
+	for i, event := range observer.events {
+		types[i] = event.Type
+		assert.Assert(t, !event.Time.IsZero())
+	}
+	assert.DeepEqual(t, types, []EventType{EventScenarioStarted, EventVerificationAdded, EventVerificationStarted,
+		EventVerificationAdded, EventMetricAdded, EventVerificationAdded, EventArtifactAdded,
+		EventVerificationFinished})
+	metricEvent := observer.events[4]
+	assert.Equal(t, metricEvent.Scenario, "s1")
+	assert.DeepEqual(t, metricEvent.Path, []string{"case-0", "test for yunikorn"})
+	assert.Equal(t, metricEvent.Metric.Value, 10.0)
+	assert.DeepEqual(t, observer.events[5].Path, []string{"case-0", "test for yunikorn", "check"})
+	assert.Equal(t, observer.events[5].Status, "FAILED")
+	// the group is failed by its sub-verification
+	assert.Equal(t, observer.events[7].Status, "FAILED")
+
+	// no more events after observers are closed
+	numEvents := len(observer.events)
+	vg.AddMetric("m", 1, UnitNone)
+	assert.Equal(t, len(observer.events), numEvents)
+}
+
+func TestJSONLinesObserver(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "events.jsonl")
+	observer, err := NewJSONLinesObserver(filePath)
+	assert.NilError(t, err)
+	results := NewResults()
+	results.AddObserver(observer)
+	v := results.CreateScenarioResults("s1").AddVerificationGroup("case-0", "")
+	v.AddMetric("maxMinRatio", math.Inf(1), UnitNone)
+	results.Close()
+
+	file, err := os.Open(filePath)
+	assert.NilError(t, err)
+	defer file.Close()
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	assert.Equal(t, len(lines), 3)
+	var event Event
+	assert.NilError(t, json.Unmarshal([]byte(lines[0]), &event))
+	assert.Equal(t, event.Type, EventScenarioStarted)
+	// non-finite values are encoded as strings
+	assert.Assert(t, strings.Contains(lines[2], `"Value":"+Inf"`), lines[2])
+
+	// the whole results tree can be encoded as well
+	_, err = json.Marshal(results)
+	assert.NilError(t, err)
+}
+
+func TestWebhookObserver(t *testing.T) {
+	var lock sync.Mutex
+	var eventTypes []EventType
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		lock.Lock()
+		eventTypes = append(eventTypes, event.Type)
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	observer := NewWebhookObserver(server.URL)
+	results := NewResults()
+	results.AddObserver(observer)
+	results.CreateScenarioResults("s1").AddVerification("v1", "", SUCCEEDED)
+	results.Close()
+	// buffered events are posted before the observer is closed
+	lock.Lock()
+	defer lock.Unlock()
+	assert.DeepEqual(t, eventTypes, []EventType{EventScenarioStarted, EventVerificationAdded})
+}
+
+func TestWebhookObserverCloseTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	observer := NewWebhookObserver(server.URL)
+	observer.closeTimeout = 500 * time.Millisecond
+	results := NewResults()
+	results.AddObserver(observer)
+	scenarioResult := results.CreateScenarioResults("s1")
+	for i := 0; i < 10; i++ {
+		scenarioResult.AddVerification(fmt.Sprintf("v%d", i), "", SUCCEEDED)
+	}
+	beginTime := time.Now()
+	closed := make(chan struct{})
+	go func() {
+		results.Close()
+		close(closed)
+	}()
+	// results are not locked while observers are closing
+	time.Sleep(100 * time.Millisecond)
+	results.CreateScenarioResults("s2")
+	select {
+	case <-closed:
+		t.Fatal("observer should wait for buffered events until the close timeout")
+	default:
+	}
+	// remaining events are dropped rather than waiting for the hung webhook
+	<-closed
+	assert.Assert(t, time.Since(beginTime) < webhookTimeout)
+	assert.ErrorContains(t, observer.Close(), "dropped")
+}
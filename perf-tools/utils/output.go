@@ -19,8 +19,13 @@ package utils
 */
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 type VerificationStatus int
@@ -30,18 +35,42 @@ const (
 	FAILED
 )
 
+const (
+	UnitSeconds      = "s"
+	UnitMilliseconds = "ms"
+	UnitPods         = "pods"
+	UnitNodes        = "nodes"
+	UnitQPS          = "pods/s"
+	UnitPercent      = "%"
+	UnitNone         = ""
+)
+
+type ArtifactType string
+
+const (
+	ArtifactTable ArtifactType = "table"
+	ArtifactChart ArtifactType = "chart"
+	ArtifactOther ArtifactType = "other"
+)
+
 type Reporter interface {
-	GenerateReport()
+	GenerateReport() error
 }
 
+// Results can be updated concurrently by scenarios running in parallel, all updates via methods
+// of results, scenario results and verifications are guarded by the same lock and notified to observers.
 type Results struct {
 	ScenarioResults []*ScenarioResult
+	lock            *sync.RWMutex
+	observers       []Observer
 }
 
 type ScenarioResult struct {
 	Name          string
 	Status        VerificationStatus
 	Verifications []*Verification
+	lock          *sync.RWMutex
+	results       *Results
 }
 
 type Verification struct {
@@ -50,57 +79,128 @@ type Verification struct {
 	Status           VerificationStatus
 	Description      string
 	SubVerifications []*Verification
-	Parent           *Verification
+	Parent           *Verification `json:"-"`
+	StartTime        time.Time
+	EndTime          time.Time
+	Duration         time.Duration
+	Metrics          []*Metric
+	Artifacts        []*Artifact
+	lock             *sync.RWMutex
+	results          *Results
+	scenarioName     string
+}
+
+// Metric is a typed measurement attached to a verification
+type Metric struct {
+	Name  string
+	Value float64
+	Unit  string
+}
+
+// Artifact references an output file (table, chart, ...) produced by a verification
+type Artifact struct {
+	Name string
+	Type ArtifactType
+	Path string
 }
 
 func NewResults() *Results {
 	return &Results{
 		ScenarioResults: make([]*ScenarioResult, 0),
+		lock:            &sync.RWMutex{},
 	}
 }
 
 func (r *Results) CreateScenarioResults(scenarioName string) *ScenarioResult {
+	r.lock.Lock()
+	defer r.lock.Unlock()
 	scenarioResult := &ScenarioResult{
 		Name:          scenarioName,
 		Status:        SUCCEEDED,
 		Verifications: make([]*Verification, 0),
+		lock:          r.lock,
+		results:       r,
 	}
 	r.ScenarioResults = append(r.ScenarioResults, scenarioResult)
+	r.notify(&Event{Type: EventScenarioStarted, Scenario: scenarioName})
 	return scenarioResult
 }
 
-func (sr *ScenarioResult) AddVerification(name, description string, status VerificationStatus) {
+// GetScenarioResult returns results of the scenario, or nil if the scenario hasn't been run
+func (r *Results) GetScenarioResult(scenarioName string) *ScenarioResult {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for _, scenarioResult := range r.ScenarioResults {
+		if scenarioResult.Name == scenarioName {
+			return scenarioResult
+		}
+	}
+	return nil
+}
+
+func (sr *ScenarioResult) AddVerification(name, description string, status VerificationStatus) *Verification {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
 	verification := &Verification{
-		Deep:        1,
-		Name:        name,
-		Description: description,
-		Status:      status,
+		Deep:         1,
+		Name:         name,
+		Description:  description,
+		Status:       status,
+		lock:         sr.lock,
+		results:      sr.results,
+		scenarioName: sr.Name,
 	}
 	sr.Verifications = append(sr.Verifications, verification)
 	if status == FAILED {
 		sr.Status = FAILED
 	}
+	verification.notify(EventVerificationAdded, getStatusString(status))
+	return verification
 }
 
 func (sr *ScenarioResult) AddVerificationGroup(name, description string) *Verification {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
 	verification := &Verification{
 		Deep:             1,
 		Name:             name,
 		Description:      description,
 		SubVerifications: make([]*Verification, 0),
+		lock:             sr.lock,
+		results:          sr.results,
+		scenarioName:     sr.Name,
 	}
 	sr.Verifications = append(sr.Verifications, verification)
+	verification.notify(EventVerificationAdded, "")
 	return verification
 }
 
+// GetVerification returns the top-level verification with the name, or nil if not found
+func (sr *ScenarioResult) GetVerification(name string) *Verification {
+	sr.lock.RLock()
+	defer sr.lock.RUnlock()
+	for _, verification := range sr.Verifications {
+		if verification.Name == name {
+			return verification
+		}
+	}
+	return nil
+}
+
 func (vg *Verification) AddSubVerificationGroup(name, description string) *Verification {
+	vg.lock.Lock()
+	defer vg.lock.Unlock()
 	subVerification := &Verification{
-		Deep:        vg.Deep + 1,
-		Name:        name,
-		Description: description,
-		Parent:      vg,
+		Deep:         vg.Deep + 1,
+		Name:         name,
+		Description:  description,
+		Parent:       vg,
+		lock:         vg.lock,
+		results:      vg.results,
+		scenarioName: vg.scenarioName,
 	}
 	vg.SubVerifications = append(vg.SubVerifications, subVerification)
+	subVerification.notify(EventVerificationAdded, "")
 	return subVerification
 }
 
@@ -129,14 +229,20 @@ func (vg *Verification) AddErrorSubVerification(err error, name, description str
 }
 
 func (vg *Verification) AddSubVerification(name, description string, status VerificationStatus) *Verification {
+	vg.lock.Lock()
+	defer vg.lock.Unlock()
 	subVerification := &Verification{
-		Deep:        vg.Deep + 1,
-		Name:        name,
-		Status:      status,
-		Description: description,
-		Parent:      vg,
+		Deep:         vg.Deep + 1,
+		Name:         name,
+		Status:       status,
+		Description:  description,
+		Parent:       vg,
+		lock:         vg.lock,
+		results:      vg.results,
+		scenarioName: vg.scenarioName,
 	}
 	vg.SubVerifications = append(vg.SubVerifications, subVerification)
+	subVerification.notify(EventVerificationAdded, getStatusString(status))
 	if status == FAILED {
 		parentVer := subVerification.Parent
 		for parentVer != nil {
@@ -148,11 +254,124 @@ func (vg *Verification) AddSubVerification(name, description string, status Veri
 	return subVerification
 }
 
+// Start records the start time of this verification
+func (v *Verification) Start() *Verification {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.StartTime = time.Now()
+	v.notify(EventVerificationStarted, "")
+	return v
+}
+
+// Finish records the end time of this verification and calculates the duration since start time
+func (v *Verification) Finish() *Verification {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.setTimeRange(v.StartTime, time.Now())
+	v.notify(EventVerificationFinished, getStatusString(v.Status))
+	return v
+}
+
+// SetTimeRange records the start/end time of this verification, duration is set only if both are known
+func (v *Verification) SetTimeRange(startTime, endTime time.Time) *Verification {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return v.setTimeRange(startTime, endTime)
+}
+
+func (v *Verification) setTimeRange(startTime, endTime time.Time) *Verification {
+	v.StartTime = startTime
+	v.EndTime = endTime
+	if !startTime.IsZero() && !endTime.IsZero() {
+		v.Duration = endTime.Sub(startTime)
+	}
+	return v
+}
+
+// AddMetric adds a metric or updates the value and unit of an existing metric with the same name
+func (v *Verification) AddMetric(name string, value float64, unit string) *Verification {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	metric := v.getMetric(name)
+	if metric != nil {
+		metric.Value = value
+		metric.Unit = unit
+	} else {
+		metric = &Metric{
+			Name:  name,
+			Value: value,
+			Unit:  unit,
+		}
+		v.Metrics = append(v.Metrics, metric)
+	}
+	v.notify(EventMetricAdded, "", func(event *Event) {
+		event.Metric = &Metric{Name: name, Value: value, Unit: unit}
+	})
+	return v
+}
+
+func (v *Verification) GetMetric(name string) *Metric {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+	return v.getMetric(name)
+}
+
+func (v *Verification) getMetric(name string) *Metric {
+	for _, metric := range v.Metrics {
+		if metric.Name == name {
+			return metric
+		}
+	}
+	return nil
+}
+
+// CollectMetrics returns metrics of this verification and all its sub-verifications keyed by paths of
+// sub-verifications relative to this one (names joined by " / "), metrics of this one are keyed by ""
+func (v *Verification) CollectMetrics() map[string][]*Metric {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+	collected := make(map[string][]*Metric)
+	v.collectMetrics("", collected)
+	return collected
+}
+
+func (v *Verification) collectMetrics(path string, collected map[string][]*Metric) {
+	if len(v.Metrics) > 0 {
+		collected[path] = append(collected[path], v.Metrics...)
+	}
+	for _, subV := range v.SubVerifications {
+		subPath := subV.Name
+		if path != "" {
+			subPath = path + " / " + subV.Name
+		}
+		subV.collectMetrics(subPath, collected)
+	}
+}
+
+func (v *Verification) AddArtifact(name string, artifactType ArtifactType, path string) *Verification {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	artifact := &Artifact{
+		Name: name,
+		Type: artifactType,
+		Path: path,
+	}
+	v.Artifacts = append(v.Artifacts, artifact)
+	v.notify(EventArtifactAdded, "", func(event *Event) {
+		event.Artifact = artifact
+	})
+	return v
+}
+
 func (vg *Verification) IsFailed() bool {
+	vg.lock.RLock()
+	defer vg.lock.RUnlock()
 	return vg.Status == FAILED
 }
 
 func (r *Results) RefreshStatus() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
 	for _, scenarioResult := range r.ScenarioResults {
 		status := SUCCEEDED
 		for _, v := range scenarioResult.Verifications {
@@ -179,6 +398,8 @@ func (v *Verification) RefreshStatus() {
 }
 
 func (r *Results) String() string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
 	outputInfo := ""
 	for _, scenarioResult := range r.ScenarioResults {
 		outputInfo += fmt.Sprintf("%s\n", getStatusInfo("Scenario: "+scenarioResult.Name, scenarioResult.Status))
@@ -200,14 +421,7 @@ func (v *Verification) String() string {
 }
 
 func getStatusInfo(name string, status VerificationStatus) string {
-	statusStr := ""
-	switch status {
-	case FAILED:
-		statusStr = "FAILED"
-	case SUCCEEDED:
-		statusStr = "SUCCEEDED"
-	}
-	return fmt.Sprintf("%s [%s]", name, statusStr)
+	return fmt.Sprintf("%s [%s]", name, getStatusString(status))
 }
 
 func getVerificationStatusInfo(v *Verification) string {
@@ -215,9 +429,61 @@ func getVerificationStatusInfo(v *Verification) string {
 	if v.Description != "" {
 		statusInfo += fmt.Sprintf(" (%s)", v.Description)
 	}
+	if v.Duration > 0 {
+		statusInfo += fmt.Sprintf(" [duration: %s]", v.Duration)
+	}
+	if len(v.Metrics) > 0 {
+		metrics := make([]string, len(v.Metrics))
+		for i, metric := range v.Metrics {
+			metrics[i] = metric.String()
+		}
+		statusInfo += fmt.Sprintf(" [metrics: %s]", strings.Join(metrics, ", "))
+	}
 	return statusInfo
 }
 
+// MarshalJSON encodes non-finite values, such as +Inf of max/min ratios, as strings since JSON doesn't support them
+func (m *Metric) MarshalJSON() ([]byte, error) {
+	var value interface{} = m.Value
+	if math.IsInf(m.Value, 0) || math.IsNaN(m.Value) {
+		value = strconv.FormatFloat(m.Value, 'f', -1, 64)
+	}
+	return json.Marshal(&struct {
+		Name  string
+		Value interface{}
+		Unit  string
+	}{m.Name, value, m.Unit})
+}
+
+// UnmarshalJSON decodes metrics encoded by MarshalJSON, including non-finite values encoded as strings
+func (m *Metric) UnmarshalJSON(data []byte) error {
+	var encoded struct {
+		Name  string
+		Value interface{}
+		Unit  string
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	switch value := encoded.Value.(type) {
+	case float64:
+		m.Value = value
+	case string:
+		parsedValue, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value of metric %s: %s", encoded.Name, err.Error())
+		}
+		m.Value = parsedValue
+	}
+	m.Name = encoded.Name
+	m.Unit = encoded.Unit
+	return nil
+}
+
+func (m *Metric) String() string {
+	return fmt.Sprintf("%s=%s%s", m.Name, strconv.FormatFloat(m.Value, 'f', -1, 64), m.Unit)
+}
+
 func getDeepPrefix(deep int) string {
 	return strings.Repeat("    ", deep)
 }
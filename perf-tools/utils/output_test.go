@@ -19,7 +19,10 @@
 package utils
 
 import (
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"gotest.tools/v3/assert"
 )
@@ -52,3 +55,70 @@ func TestResults(t *testing.T) {
 
 	t.Log("\n" + results.String())
 }
+
+func TestVerificationMetrics(t *testing.T) {
+	results := NewResults()
+	s1 := results.CreateScenarioResults("s1")
+	v1 := s1.AddVerificationGroup("s1-vg1", "")
+	beginTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	v1.SetTimeRange(beginTime, beginTime.Add(10*time.Second))
+	assert.Equal(t, v1.Duration, 10*time.Second)
+	v1.SetTimeRange(beginTime, time.Time{})
+	assert.Equal(t, v1.EndTime.IsZero(), true)
+
+	v1.AddMetric("avgQPS", 12.5, UnitQPS).AddMetric("numPods", 100, UnitPods)
+	assert.Equal(t, len(v1.Metrics), 2)
+	assert.Equal(t, v1.GetMetric("avgQPS").Value, 12.5)
+	// update existing metric
+	v1.AddMetric("avgQPS", 20, UnitQPS)
+	assert.Equal(t, len(v1.Metrics), 2)
+	assert.Equal(t, v1.GetMetric("avgQPS").Value, float64(20))
+	assert.Assert(t, v1.GetMetric("unknown") == nil)
+	assert.Equal(t, v1.GetMetric("numPods").String(), "numPods=100pods")
+
+	v1.AddSubVerification("s1-vg1-1", "", SUCCEEDED).
+		AddArtifact("chart", ArtifactChart, "/tmp/chart.svg")
+	assert.Equal(t, len(v1.SubVerifications[0].Artifacts), 1)
+	assert.Equal(t, v1.SubVerifications[0].Artifacts[0].Type, ArtifactChart)
+
+	// metrics keyed by paths of sub-verifications
+	v1.AddSubVerificationGroup("test for yunikorn", "").AddSubVerification("analyze", "", SUCCEEDED).
+		AddMetric("p99", 1.5, UnitSeconds)
+	collected := v1.CollectMetrics()
+	assert.Equal(t, len(collected), 2)
+	assert.Equal(t, len(collected[""]), 2)
+	assert.Equal(t, collected["test for yunikorn / analyze"][0].String(), "p99=1.5s")
+	assert.Assert(t, s1.GetVerification("s1-vg1") == v1)
+	assert.Assert(t, s1.GetVerification("unknown") == nil)
+	assert.Assert(t, results.GetScenarioResult("s1") == s1)
+	assert.Assert(t, results.GetScenarioResult("unknown") == nil)
+
+	t.Log("\n" + results.String())
+}
+
+func TestResultsConcurrentUpdates(t *testing.T) {
+	results := NewResults()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			scenarioResult := results.CreateScenarioResults(fmt.Sprintf("s%d", i))
+			vg := scenarioResult.AddVerificationGroup("vg", "").Start()
+			for j := 0; j < 100; j++ {
+				vg.AddSubVerification(fmt.Sprintf("v%d", j), "", SUCCEEDED)
+				vg.AddMetric(fmt.Sprintf("m%d", j%10), float64(j), UnitNone)
+			}
+			vg.Finish()
+		}(i)
+	}
+	wg.Wait()
+	results.RefreshStatus()
+	assert.Equal(t, len(results.ScenarioResults), 10)
+	for _, scenarioResult := range results.ScenarioResults {
+		vg := scenarioResult.Verifications[0]
+		assert.Equal(t, len(vg.SubVerifications), 100)
+		assert.Equal(t, len(vg.Metrics), 10)
+		assert.Equal(t, vg.GetMetric("m9").Value, 99.0)
+	}
+}
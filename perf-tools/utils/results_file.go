@@ -0,0 +1,102 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const ResultsFileName = "results.json"
+
+// resultsFile is the saved data of a run, from which reports can be regenerated and runs can be compared
+type resultsFile struct {
+	Metadata        *RunMetadata
+	ScenarioResults []*ScenarioResult
+}
+
+// SaveResults saves results and metadata of a run as JSON into the output path of the run
+func SaveResults(results *Results, metadata *RunMetadata) error {
+	results.lock.RLock()
+	content, err := json.MarshalIndent(&resultsFile{
+		Metadata:        metadata,
+		ScenarioResults: results.ScenarioResults,
+	}, "", "  ")
+	results.lock.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode results: %s", err.Error())
+	}
+	filePath := filepath.Join(metadata.OutputPath, ResultsFileName)
+	// #nosec G306 - results are not sensitive
+	if err = os.WriteFile(filePath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write results: %s", err.Error())
+	}
+	Logger.Info("Successfully saved results", zap.String("outputFile", filePath))
+	return nil
+}
+
+// LoadResults loads results and metadata saved into the output path of a run. The output path of metadata and
+// paths of artifacts are updated if the output directory has been moved since the run.
+func LoadResults(outputPath string) (*Results, *RunMetadata, error) {
+	content, err := os.ReadFile(filepath.Join(outputPath, ResultsFileName))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read results: %s", err.Error())
+	}
+	var saved resultsFile
+	if err = json.Unmarshal(content, &saved); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode results: %s", err.Error())
+	}
+	if saved.Metadata == nil {
+		saved.Metadata = &RunMetadata{}
+	}
+	savedOutputPath := saved.Metadata.OutputPath
+	saved.Metadata.OutputPath = outputPath
+	results := NewResults()
+	for _, scenarioResult := range saved.ScenarioResults {
+		scenarioResult.lock = results.lock
+		scenarioResult.results = results
+		for _, verification := range scenarioResult.Verifications {
+			restoreVerification(verification, nil, scenarioResult, savedOutputPath, outputPath)
+		}
+		results.ScenarioResults = append(results.ScenarioResults, scenarioResult)
+	}
+	return results, saved.Metadata, nil
+}
+
+// restoreVerification restores fields of a loaded verification and its sub-verifications which are not saved
+func restoreVerification(v, parent *Verification, scenarioResult *ScenarioResult, savedOutputPath,
+	outputPath string) {
+	v.Parent = parent
+	v.lock = scenarioResult.lock
+	v.results = scenarioResult.results
+	v.scenarioName = scenarioResult.Name
+	for _, artifact := range v.Artifacts {
+		if savedOutputPath != "" && strings.HasPrefix(artifact.Path, savedOutputPath) {
+			artifact.Path = outputPath + strings.TrimPrefix(artifact.Path, savedOutputPath)
+		}
+	}
+	for _, subV := range v.SubVerifications {
+		restoreVerification(subV, v, scenarioResult, savedOutputPath, outputPath)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSaveAndLoadResults(t *testing.T) {
+	outputPath := t.TempDir()
+	results := NewResults()
+	s1 := results.CreateScenarioResults("s1")
+	group := s1.AddVerificationGroup("Case-0", "case 0")
+	group.AddSubVerificationGroup("test for yunikorn", "").
+		AddMetric("totalSeconds", 10, UnitSeconds).
+		AddMetric("maxMinRatio", math.Inf(1), UnitNone).
+		AddArtifact("chart", ArtifactChart, outputPath+"/s1-case0.png")
+	group.AddSubVerification("cleanup app", "timeout", FAILED)
+	results.RefreshStatus()
+	metadata := &RunMetadata{OutputPath: outputPath, ScenarioNames: []string{"s1"}}
+	assert.NilError(t, SaveResults(results, metadata))
+
+	// the output directory is moved after the run
+	movedOutputPath := filepath.Join(t.TempDir(), "moved")
+	assert.NilError(t, os.Rename(outputPath, movedOutputPath))
+	loadedResults, loadedMetadata, err := LoadResults(movedOutputPath)
+	assert.NilError(t, err)
+	assert.Equal(t, loadedMetadata.OutputPath, movedOutputPath)
+	assert.DeepEqual(t, loadedMetadata.ScenarioNames, []string{"s1"})
+	assert.Equal(t, loadedResults.String(), results.String())
+	loadedGroup := loadedResults.ScenarioResults[0].Verifications[0]
+	assert.Equal(t, loadedResults.ScenarioResults[0].Status, FAILED)
+	loadedSubV := loadedGroup.SubVerifications[0]
+	assert.Equal(t, loadedSubV.Parent, loadedGroup)
+	assert.Assert(t, math.IsInf(loadedSubV.GetMetric("maxMinRatio").Value, 1))
+	assert.Equal(t, loadedSubV.Artifacts[0].Path, movedOutputPath+"/s1-case0.png")
+
+	// loaded results can be updated as usual
+	loadedGroup.AddSubVerification("extra", "", SUCCEEDED)
+	assert.Equal(t, len(loadedGroup.SubVerifications), 3)
+
+	_, _, err = LoadResults(outputPath)
+	assert.ErrorContains(t, err, "failed to read results")
+}
@@ -0,0 +1,142 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"math"
+	"sort"
+)
+
+// Percentile returns the p-th percentile (0 < p <= 100) of the values using the nearest-rank method,
+// returns 0 if there are no values.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	} else if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// FairnessIndices describes how evenly a quantity (e.g. utilization or number of tasks) is spread across nodes
+type FairnessIndices struct {
+	// Jain's fairness index in (0, 1], 1 means perfectly fair
+	JainIndex float64
+	// Gini coefficient in [0, 1), 0 means perfectly fair
+	Gini float64
+	// coefficient of variation (standard deviation / mean), 0 means perfectly fair
+	CV float64
+	// ratio of the max value to the min value, +Inf if the min value is 0 while the max value is not
+	MaxMinRatio float64
+}
+
+// CalculateFairnessIndices calculates fairness indices of non-negative values,
+// values which are all zeros are considered perfectly fair.
+func CalculateFairnessIndices(values []float64) FairnessIndices {
+	indices := FairnessIndices{JainIndex: 1, MaxMinRatio: 1}
+	if len(values) == 0 {
+		return indices
+	}
+	n := float64(len(values))
+	var sum, sumOfSquares float64
+	minValue, maxValue := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		sum += v
+		sumOfSquares += v * v
+		minValue = math.Min(minValue, v)
+		maxValue = math.Max(maxValue, v)
+	}
+	if sum == 0 {
+		return indices
+	}
+	indices.JainIndex = sum * sum / (n * sumOfSquares)
+	mean := sum / n
+	// sum of squared deviations rather than sumOfSquares/n-mean*mean, which can be slightly negative due to
+	// rounding when all values are equal
+	var sumOfSquaredDeviations float64
+	for _, v := range values {
+		sumOfSquaredDeviations += (v - mean) * (v - mean)
+	}
+	indices.CV = math.Sqrt(sumOfSquaredDeviations/n) / mean
+	if minValue == 0 {
+		indices.MaxMinRatio = math.Inf(1)
+	} else {
+		indices.MaxMinRatio = maxValue / minValue
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	var weightedSum float64
+	for i, v := range sorted {
+		weightedSum += float64(i+1) * v
+	}
+	indices.Gini = 2*weightedSum/(n*sum) - (n+1)/n
+	return indices
+}
+
+// CalculateFairnessTimeline calculates fairness indices for every column of values
+// indexed by [row][column], for example [node][second].
+func CalculateFairnessTimeline(values [][]float64) []FairnessIndices {
+	numColumns := 0
+	for _, row := range values {
+		numColumns = max(numColumns, len(row))
+	}
+	timeline := make([]FairnessIndices, numColumns)
+	columnValues := make([]float64, len(values))
+	for c := 0; c < numColumns; c++ {
+		for r, row := range values {
+			if c < len(row) {
+				columnValues[r] = row[c]
+			} else {
+				columnValues[r] = 0
+			}
+		}
+		timeline[c] = CalculateFairnessIndices(columnValues)
+	}
+	return timeline
+}
+
+// CalculateInversionRatio returns the fraction of pairs ordered by keys which are ordered the other way round
+// by values, pairs with equal keys are not comparable and skipped. 0 means values are in the same order as keys,
+// 1 means they are in the reverse order, and 0 is returned if there is no comparable pair.
+func CalculateInversionRatio(keys, values []float64) float64 {
+	var inversions, pairs int
+	for i := 0; i < len(keys) && i < len(values); i++ {
+		for j := i + 1; j < len(keys) && j < len(values); j++ {
+			if keys[i] == keys[j] {
+				continue
+			}
+			pairs++
+			if (keys[i] < keys[j]) != (values[i] < values[j]) && values[i] != values[j] {
+				inversions++
+			}
+		}
+	}
+	if pairs == 0 {
+		return 0
+	}
+	return float64(inversions) / float64(pairs)
+}
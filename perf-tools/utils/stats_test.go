@@ -0,0 +1,84 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	assert.Equal(t, Percentile(nil, 99), float64(0))
+	values := []float64{5, 1, 4, 2, 3, 6, 7, 8, 9, 10}
+	assert.Equal(t, Percentile(values, 50), float64(5))
+	assert.Equal(t, Percentile(values, 99), float64(10))
+	assert.Equal(t, Percentile(values, 0), float64(1))
+	assert.Equal(t, Percentile([]float64{1.5}, 99), 1.5)
+}
+
+func TestCalculateFairnessIndices(t *testing.T) {
+	// perfectly fair
+	indices := CalculateFairnessIndices([]float64{2, 2, 2, 2})
+	assert.Equal(t, indices.JainIndex, float64(1))
+	assert.Equal(t, indices.Gini, float64(0))
+	assert.Equal(t, indices.CV, float64(0))
+	assert.Equal(t, indices.MaxMinRatio, float64(1))
+	// equal non-integer shares are perfectly fair regardless of rounding
+	for _, values := range [][]float64{{0.1, 0.1, 0.1}, {0.8, 0.8, 0.8}, {0.7, 0.7, 0.7, 0.7, 0.7}} {
+		indices = CalculateFairnessIndices(values)
+		assert.Assert(t, !math.IsNaN(indices.CV), "CV of %v", values)
+		assert.Assert(t, indices.CV < 1e-9, "CV of %v: %v", values, indices.CV)
+		assert.Assert(t, math.Abs(indices.JainIndex-1) < 1e-9, "Jain index of %v", values)
+		assert.Assert(t, math.Abs(indices.Gini) < 1e-9, "Gini of %v", values)
+	}
+	// all zeros and empty values are considered fair
+	assert.Equal(t, CalculateFairnessIndices([]float64{0, 0}), FairnessIndices{JainIndex: 1, MaxMinRatio: 1})
+	assert.Equal(t, CalculateFairnessIndices(nil), FairnessIndices{JainIndex: 1, MaxMinRatio: 1})
+	// only one node is used
+	indices = CalculateFairnessIndices([]float64{0, 0, 0, 4})
+	assert.Equal(t, indices.JainIndex, 0.25)
+	assert.Equal(t, indices.Gini, 0.75)
+	assert.Equal(t, indices.CV, math.Sqrt(3))
+	assert.Assert(t, math.IsInf(indices.MaxMinRatio, 1))
+	indices = CalculateFairnessIndices([]float64{1, 3})
+	assert.Equal(t, indices.JainIndex, 0.8)
+	assert.Equal(t, indices.Gini, 0.25)
+	assert.Equal(t, indices.CV, 0.5)
+	assert.Equal(t, indices.MaxMinRatio, float64(3))
+}
+
+func TestCalculateFairnessTimeline(t *testing.T) {
+	timeline := CalculateFairnessTimeline([][]float64{{1, 1, 1}, {0, 1}})
+	assert.Equal(t, len(timeline), 3)
+	assert.Equal(t, timeline[0].JainIndex, 0.5)
+	assert.Equal(t, timeline[1].JainIndex, float64(1))
+	assert.Equal(t, timeline[2].JainIndex, 0.5)
+}
+
+func TestCalculateInversionRatio(t *testing.T) {
+	assert.Equal(t, CalculateInversionRatio(nil, nil), 0.0)
+	assert.Equal(t, CalculateInversionRatio([]float64{0, 1, 2}, []float64{1, 2, 3}), 0.0)
+	assert.Equal(t, CalculateInversionRatio([]float64{0, 1, 2}, []float64{3, 2, 1}), 1.0)
+	// one of three pairs is inverted
+	assert.Equal(t, CalculateInversionRatio([]float64{0, 1, 2}, []float64{2, 1, 3}), 1.0/3)
+	// pairs with equal keys are skipped, ties of values are not inversions
+	assert.Equal(t, CalculateInversionRatio([]float64{0, 0, 1}, []float64{5, 1, 1}), 0.5)
+}
@@ -19,16 +19,51 @@
 package utils
 
 import (
+	"encoding/csv"
+	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
 )
 
+type TableFormat string
+
+const (
+	TableFormatText     TableFormat = "txt"
+	TableFormatCSV      TableFormat = "csv"
+	TableFormatTSV      TableFormat = "tsv"
+	TableFormatMarkdown TableFormat = "md"
+)
+
+var DefaultTableFormats = []TableFormat{TableFormatText}
+
 type Table struct {
 	Headers []string
 	Data    [][]string
 }
 
+// ParseTableFormats converts configured format names to table formats,
+// the default formats are returned if nothing is configured.
+func ParseTableFormats(formatNames []string) ([]TableFormat, error) {
+	if len(formatNames) == 0 {
+		return DefaultTableFormats, nil
+	}
+	formats := make([]TableFormat, 0, len(formatNames))
+	for _, formatName := range formatNames {
+		format := TableFormat(strings.ToLower(strings.TrimSpace(formatName)))
+		switch format {
+		case TableFormatText, TableFormatCSV, TableFormatTSV, TableFormatMarkdown:
+			formats = append(formats, format)
+		default:
+			return nil, fmt.Errorf("unknown table format %s, supported formats: %v", formatName,
+				[]TableFormat{TableFormatText, TableFormatCSV, TableFormatTSV, TableFormatMarkdown})
+		}
+	}
+	return formats, nil
+}
+
 // https://pkg.go.dev/github.com/olekukonko/tablewriter
 func (t *Table) Print() {
 	writer := tablewriter.NewWriter(os.Stdout)
@@ -40,11 +75,85 @@ func (t *Table) Output(file string) error {
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 	writer := tablewriter.NewWriter(f)
 	t.render(writer)
 	return nil
 }
 
+// OutputFormats writes this table into files named <filePathPrefix>.<format> for all specified formats,
+// returns paths of the written files.
+func (t *Table) OutputFormats(filePathPrefix string, formats []TableFormat) ([]string, error) {
+	filePaths := make([]string, 0, len(formats))
+	for _, format := range formats {
+		filePath := filePathPrefix + "." + string(format)
+		if err := t.OutputFormat(filePath, format); err != nil {
+			return filePaths, err
+		}
+		filePaths = append(filePaths, filePath)
+	}
+	return filePaths, nil
+}
+
+func (t *Table) OutputFormat(file string, format TableFormat) error {
+	if format == TableFormatText {
+		return t.Output(file)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return t.Render(f, format)
+}
+
+// Render writes this table into the writer in the specified format
+func (t *Table) Render(w io.Writer, format TableFormat) error {
+	switch format {
+	case TableFormatText:
+		t.render(tablewriter.NewWriter(w))
+		return nil
+	case TableFormatCSV:
+		return t.renderSeparated(w, ',')
+	case TableFormatTSV:
+		return t.renderSeparated(w, '\t')
+	case TableFormatMarkdown:
+		return t.renderMarkdown(w)
+	}
+	return fmt.Errorf("unknown table format %s", format)
+}
+
+func (t *Table) renderSeparated(w io.Writer, separator rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = separator
+	if err := writer.Write(t.Headers); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(t.Data); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// renderMarkdown writes this table in GitHub flavored markdown
+func (t *Table) renderMarkdown(w io.Writer) error {
+	separators := make([]string, len(t.Headers))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	rows := append([][]string{t.Headers, separators}, t.Data...)
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = strings.ReplaceAll(strings.ReplaceAll(cell, "|", "\\|"), "\n", " ")
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (t *Table) render(writer *tablewriter.Table) {
 	writer.SetHeader(t.Headers)
 	for _, v := range t.Data {
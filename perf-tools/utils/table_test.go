@@ -0,0 +1,78 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestTableRender(t *testing.T) {
+	table := &Table{
+		Headers: []string{"Name", "Value"},
+		Data: [][]string{
+			{"a", "1"},
+			{"b,c", "x|y"},
+		},
+	}
+	var buf bytes.Buffer
+	assert.NilError(t, table.Render(&buf, TableFormatCSV))
+	assert.Equal(t, buf.String(), "Name,Value\na,1\n\"b,c\",x|y\n")
+
+	buf.Reset()
+	assert.NilError(t, table.Render(&buf, TableFormatTSV))
+	assert.Equal(t, buf.String(), "Name\tValue\na\t1\nb,c\tx|y\n")
+
+	buf.Reset()
+	assert.NilError(t, table.Render(&buf, TableFormatMarkdown))
+	assert.Equal(t, buf.String(), "| Name | Value |\n| --- | --- |\n| a | 1 |\n| b,c | x\\|y |\n")
+
+	buf.Reset()
+	assert.ErrorContains(t, table.Render(&buf, TableFormat("xls")), "unknown table format")
+}
+
+func TestTableOutputFormats(t *testing.T) {
+	table := &Table{
+		Headers: []string{"Name"},
+		Data:    [][]string{{"a"}},
+	}
+	prefix := filepath.Join(t.TempDir(), "table")
+	filePaths, err := table.OutputFormats(prefix, []TableFormat{TableFormatText, TableFormatCSV})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, filePaths, []string{prefix + ".txt", prefix + ".csv"})
+	for _, filePath := range filePaths {
+		_, err = os.Stat(filePath)
+		assert.NilError(t, err)
+	}
+}
+
+func TestParseTableFormats(t *testing.T) {
+	formats, err := ParseTableFormats(nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, formats, DefaultTableFormats)
+	formats, err = ParseTableFormats([]string{"CSV", " md"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, formats, []TableFormat{TableFormatCSV, TableFormatMarkdown})
+	_, err = ParseTableFormats([]string{"xls"})
+	assert.ErrorContains(t, err, "unknown table format xls")
+}